@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brunseba/cdevents-tools/pkg/ci"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// applyCIDefaults fills the subject and source flags left empty from the CI environment, when --from-ci or the
+// from-ci configuration key is set. It runs before required flags are checked, so CI values satisfy them.
+func applyCIDefaults(cmd *cobra.Command, args []string) error {
+	fromCI := viper.GetBool("from-ci")
+	flag := cmd.Flag("from-ci")
+	explicit := flag != nil && flag.Changed
+	if explicit {
+		fromCI = flag.Value.String() == "true"
+	}
+	if !fromCI {
+		return nil
+	}
+	env := ci.Detect(os.Getenv)
+	if env == nil {
+		if explicit {
+			return fmt.Errorf("--from-ci: no supported CI environment detected (supported: %s)", strings.Join(ci.Providers, ", "))
+		}
+		return nil
+	}
+
+	// Pipeline events describe the CI run, task and build events its current job
+	kind := cmd.Name()
+	if cmd == wrapCmd {
+		kind = cmd.Flag("kind").Value.String()
+	}
+	defaults := map[string]string{}
+	if viper.GetString("source") == "" {
+		defaults["source"] = env.Source
+	}
+	switch kind {
+	case "pipeline":
+		defaults["id"], defaults["name"], defaults["url"] = env.RunID, env.RunName, env.RunURL
+	case "task", "build":
+		defaults["id"], defaults["name"], defaults["url"] = env.JobID, env.JobName, env.JobURL
+		defaults["pipeline"] = env.RunID
+	}
+	for name, value := range defaults {
+		if f := cmd.Flags().Lookup(name); f == nil || f.Value.String() != "" || value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("failed to set --%s from %s: %w", name, env.Provider, err)
+		}
+	}
+	return nil
+}
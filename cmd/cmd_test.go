@@ -1,17 +1,41 @@
 package cmd_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/brunseba/cdevents-tools/cmd"
 	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
 	"github.com/cdevents/sdk-go/pkg/api"
 	"github.com/spf13/viper"
 )
 
+// resetFlags restores the flags of all commands to their defaults before and after the test
+func resetFlags(t *testing.T) {
+	t.Helper()
+	cmd.ResetFlags()
+	t.Cleanup(cmd.ResetFlags)
+}
+
 func TestRootCommand(t *testing.T) {
 	// Save original args
 	originalArgs := os.Args
@@ -95,7 +119,7 @@ func TestGenerateCommands(t *testing.T) {
 		},
 		{
 			name: "generate service deployed",
-			args: []string{"cdevents-cli", "generate", "service", "deployed", "--id", "101", "--name", "test-service"},
+			args: []string{"cdevents-cli", "generate", "service", "deployed", "--id", "101", "--name", "test-service", "--environment", "prod", "--artifact-id", "pkg:oci/test-service@v1"},
 			expectError: false,
 		},
 		{
@@ -113,6 +137,21 @@ func TestGenerateCommands(t *testing.T) {
 			args: []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "123", "--name", "test-pipeline", "--output", "cloudevent"},
 			expectError: false,
 		},
+		{
+			name: "generate with curl output",
+			args: []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "123", "--name", "test-pipeline", "--output", "curl", "--http-target", "http://example.com/events", "--http-header", "Authorization=Bearer token"},
+			expectError: false,
+		},
+		{
+			name: "generate with http output",
+			args: []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "123", "--name", "test-pipeline", "--output", "http"},
+			expectError: false,
+		},
+		{
+			name: "generate with github output",
+			args: []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "123", "--name", "test-pipeline", "--output", "github"},
+			expectError: false,
+		},
 		{
 			name: "generate pipeline missing id",
 			args: []string{"cdevents-cli", "generate", "pipeline", "started", "--name", "test-pipeline"},
@@ -153,6 +192,7 @@ func TestSendCommands(t *testing.T) {
 	// Save original args
 	originalArgs := os.Args
 	defer func() { os.Args = originalArgs }()
+	outputFile := filepath.Join(t.TempDir(), "test.ndjson")
 
 	tests := []struct {
 		name string
@@ -166,7 +206,7 @@ func TestSendCommands(t *testing.T) {
 		},
 		{
 			name: "send to file",
-			args: []string{"cdevents-cli", "send", "--target", "file://test.json", "pipeline", "started", "--id", "123", "--name", "test-pipeline"},
+			args: []string{"cdevents-cli", "send", "--target", "file://" + outputFile, "pipeline", "started", "--id", "123", "--name", "test-pipeline"},
 			expectError: false,
 		},
 		{
@@ -390,3 +430,1445 @@ func TestEdgeCases(t *testing.T) {
 		t.Errorf("unexpected error with error details: %v", err)
 	}
 }
+
+func TestGenerateOutputWriter(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	defer cmd.SetOut(nil)
+
+	os.Args = []string{"cdevents-cli", "generate", "build", "started", "--id", "build-1", "--name", "test-build", "--output", "json"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var event map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &event); err != nil {
+		t.Fatalf("output should be valid JSON: %v\n%s", err, buf.String())
+	}
+	subject, _ := event["subject"].(map[string]interface{})
+	if subject["id"] != "build-1" {
+		t.Errorf("expected subject id build-1, got %v", subject["id"])
+	}
+}
+
+func TestGenerateOutputFile(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the output file flags for the following tests
+		os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "1", "--name", "reset", "--output-file", "-", "--append=false"}
+		cmd.SetOut(io.Discard)
+		cmd.Execute()
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	filename := filepath.Join(t.TempDir(), "out", "events.ndjson")
+	for _, id := range []string{"task-1", "task-2"} {
+		os.Args = []string{"cdevents-cli", "generate", "task", "started", "--id", id, "--name", "test-task", "--output", "json", "--output-file", filename, "--append"}
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if count := strings.Count(string(data), `"id": "task-`); count != 2 {
+		t.Errorf("expected 2 appended events, got %d:\n%s", count, data)
+	}
+}
+
+func TestGenerateValidation(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the validation flag for the following tests
+		os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "1", "--name", "reset", "--no-validate=false"}
+		cmd.SetOut(io.Discard)
+		cmd.Execute()
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectError string
+	}{
+		{
+			name:        "test event without environment",
+			args:        []string{"cdevents-cli", "generate", "test", "testcase-started", "--id", "test-1", "--name", "test-case"},
+			expectError: "/subject/content/environment",
+		},
+		{
+			name: "test event with environment",
+			args: []string{"cdevents-cli", "generate", "test", "testcase-finished", "--id", "test-1", "--name", "test-case", "--environment", "staging", "--outcome", "success"},
+		},
+		{
+			name:        "test output without type",
+			args:        []string{"cdevents-cli", "generate", "test", "testoutput-published", "--id", "output-1", "--name", "report"},
+			expectError: "/subject/content/outputType",
+		},
+		{
+			name: "test output with type",
+			args: []string{"cdevents-cli", "generate", "test", "testoutput-published", "--id", "output-1", "--name", "report", "--output-type", "report", "--output-format", "application/xml"},
+		},
+		{
+			name:        "service event without artifact",
+			args:        []string{"cdevents-cli", "generate", "service", "upgraded", "--id", "service-1", "--name", "test-service", "--environment", "prod", "--artifact-id", ""},
+			expectError: "/subject/content/artifactId",
+		},
+		{
+			name: "invalid event with validation disabled",
+			args: []string{"cdevents-cli", "generate", "service", "upgraded", "--id", "service-1", "--name", "test-service", "--no-validate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+			err := cmd.Execute()
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestValidateCommand(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	factory := events.NewEventFactory("https://example.com/ci")
+	event, err := factory.CreatePipelineRunEvent("started", "pipeline-1", "test-pipeline", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create test event: %v", err)
+	}
+	valid, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal test event: %v", err)
+	}
+	invalid := strings.Replace(string(valid), `"pipelineName":"test-pipeline"`, `"pipelineName":1`, 1)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "valid.json"), valid, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	// Files and globs
+	os.Args = []string{"cdevents-cli", "validate", filepath.Join(dir, "*.json")}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "PASS "+filepath.Join(dir, "valid.json")) {
+		t.Errorf("report should contain the valid file, got:\n%s", out.String())
+	}
+
+	// Stdin with newline delimited events
+	out.Reset()
+	cmd.SetIn(strings.NewReader(string(valid) + "\n" + invalid + "\n"))
+	defer cmd.SetIn(nil)
+	os.Args = []string{"cdevents-cli", "validate", "--report", "json", "-"}
+	err = cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 events failed validation") {
+		t.Errorf("expected a validation failure, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"path": "/subject/content/pipelineName"`) {
+		t.Errorf("report should point to the invalid field, got:\n%s", out.String())
+	}
+
+	// Missing files are reported as failures
+	out.Reset()
+	os.Args = []string{"cdevents-cli", "validate", "--report", "text", filepath.Join(dir, "missing.json")}
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestSemanticRules(t *testing.T) {
+	resetFlags(t)
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "finished", "--id", "123", "--name", "test-pipeline", "--outcome", "done"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "finished-outcome") {
+		t.Errorf("expected a finished-outcome violation, got %v", err)
+	}
+
+	os.Args = []string{"cdevents-cli", "generate", "task", "finished", "--id", "456", "--name", "test-task", "--outcome", "success", "--errors", "step failed"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "outcome-errors") {
+		t.Errorf("expected an outcome-errors violation, got %v", err)
+	}
+
+	// The validate command reports rule violations and can disable rules
+	event := `{"context": {"version": "0.4.1", "id": "1", "source": "ci", "type": "dev.cdevents.taskrun.finished.0.2.0", "timestamp": "2024-01-01T00:00:00Z"},
+		"subject": {"id": "456", "type": "taskRun", "content": {"outcome": "success", "errors": "step failed"}}}`
+	cmd.SetIn(strings.NewReader(event))
+	defer cmd.SetIn(nil)
+	os.Args = []string{"cdevents-cli", "validate", "-"}
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("expected a validation failure")
+	}
+	if !strings.Contains(out.String(), "/subject/content/errors: event reports errors but its outcome is \"success\" (outcome-errors)") {
+		t.Errorf("report should contain the rule violation, got:\n%s", out.String())
+	}
+
+	cmd.SetIn(strings.NewReader(event))
+	os.Args = []string{"cdevents-cli", "validate", "--disable-rule", "outcome-errors", "-"}
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error with the rule disabled: %v", err)
+	}
+
+	// Disabled rules no longer reject generated events
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "finished", "--id", "123", "--name", "test-pipeline", "--outcome", "done", "--disable-rule", "finished-outcome"}
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error with the finished-outcome rule disabled: %v", err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	policy := `rules:
+  - name: source-url
+    field: /context/source
+    prefix: https://ci.example.com/
+  - name: subject-id
+    severity: warning
+    field: /subject/id
+    pattern: ^[a-z0-9-]+$
+`
+	if err := os.WriteFile(policyFile, []byte(policy), 0o644); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+
+	originalArgs := os.Args
+	defer func() {
+		// Reset the policy flags for the following tests
+		cmd.SetOut(io.Discard)
+		os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "1", "--name", "reset", "--policy", ""}
+		cmd.Execute()
+		cmd.SetIn(strings.NewReader(`{}`))
+		os.Args = []string{"cdevents-cli", "validate", "--policy", "", "-"}
+		cmd.Execute()
+		cmd.SetIn(nil)
+		cmd.SetOut(nil)
+		viper.Set("source", "")
+		os.Args = originalArgs
+	}()
+
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	defer cmd.SetOut(nil)
+	defer cmd.SetErr(nil)
+
+	// Policy errors fail generation
+	viper.Set("source", "jenkins")
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "pipeline-1", "--name", "p", "--policy", policyFile}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "(source-url)") {
+		t.Errorf("expected a source-url policy error, got %v", err)
+	}
+
+	// Policy warnings are reported on stderr
+	out.Reset()
+	viper.Set("source", "https://ci.example.com/jenkins")
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "Pipeline_1", "--name", "p", "--policy", policyFile}
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("policy warnings should not fail generation: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "Warning: /subject/id:") || !strings.Contains(out.String(), "Pipeline_1") {
+		t.Errorf("expected a warning and the event, got stderr %q and stdout %q", errOut.String(), out.String())
+	}
+
+	// The validate command reports policy errors and warnings
+	out.Reset()
+	event := `{"context": {"version": "0.4.1", "id": "1", "source": "jenkins", "type": "dev.cdevents.pipelinerun.started.0.2.0", "timestamp": "2024-01-01T00:00:00Z"},
+		"subject": {"id": "Pipeline_1", "type": "pipelineRun", "content": {"pipelineName": "p"}}}`
+	cmd.SetIn(strings.NewReader(event))
+	os.Args = []string{"cdevents-cli", "validate", "--policy", policyFile, "-"}
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("expected a validation failure")
+	}
+	for _, want := range []string{"  /context/source: \"jenkins\" must start with", "  warning: /subject/id:"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report should contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSpecVersion(t *testing.T) {
+	resetFlags(t)
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "queued", "--id", "pipeline-1", "--name", "p", "--spec-version", "0.3"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to generate a v0.3 event: %v", err)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("output should be valid JSON: %v", err)
+	}
+	context := event["context"].(map[string]interface{})
+	if context["version"] != "0.3.0" || context["type"] != "dev.cdevents.pipelinerun.queued.0.1.1" {
+		t.Errorf("unexpected v0.3 context: %v", context)
+	}
+
+	out.Reset()
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "queued", "--id", "pipeline-1", "--name", "p", "--url", "https://ci.example.com/1", "--spec-version", "0.5"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to generate a v0.5 event: %v", err)
+	}
+	event = nil
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("output should be valid JSON: %v", err)
+	}
+	context = event["context"].(map[string]interface{})
+	if context["specversion"] != "0.5.0" || context["type"] != "dev.cdevents.pipelinerun.queued.0.3.0" {
+		t.Errorf("unexpected v0.5 context: %v", context)
+	}
+
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "queued", "--id", "pipeline-1", "--name", "p", "--spec-version", "0.6"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "unsupported spec version") {
+		t.Errorf("expected an unsupported spec version error, got %v", err)
+	}
+}
+
+func TestConvertCommand(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	defer cmd.SetOut(nil)
+	defer cmd.SetErr(nil)
+	defer cmd.SetIn(nil)
+
+	event := `{"context": {"version": "0.4.1", "id": "1", "source": "ci", "type": "dev.cdevents.pipelinerun.started.0.2.0",
+		"timestamp": "2024-01-01T00:00:00Z", "chainId": "chain-1"},
+		"subject": {"id": "pipeline-1", "type": "pipelineRun", "content": {"pipelineName": "p"}}}`
+
+	cmd.SetIn(strings.NewReader(event))
+	os.Args = []string{"cdevents-cli", "convert", "--to", "0.3", "-"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to convert event: %v", err)
+	}
+	if !strings.Contains(out.String(), `"dev.cdevents.pipelinerun.started.0.1.1"`) || strings.Contains(out.String(), "chain-1") {
+		t.Errorf("unexpected converted event:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "/context/chainId: dropped") {
+		t.Errorf("expected the dropped chain ID on stderr, got %q", errOut.String())
+	}
+
+	cmd.SetIn(strings.NewReader(event))
+	os.Args = []string{"cdevents-cli", "convert", "--to", "0.3", "--strict", "-"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "lossy") {
+		t.Errorf("expected a lossy conversion error with --strict, got %v", err)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the link flags for the following tests
+		cmd.SetOut(io.Discard)
+		os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "1", "--name", "reset", "--chain-id", "", "--link-from", "", "--link-relation", ""}
+		cmd.Execute()
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	generate := func(args ...string) map[string]interface{} {
+		t.Helper()
+		out.Reset()
+		os.Args = append([]string{"cdevents-cli", "generate", "pipeline", "started", "--id", "pipeline-1", "--name", "p"}, args...)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("failed to generate event: %v", err)
+		}
+		var event map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+			t.Fatalf("output should be valid JSON: %v", err)
+		}
+		return event["context"].(map[string]interface{})
+	}
+
+	// The chain ID is propagated from the environment of a CI run
+	t.Setenv("CDEVENTS_CHAIN_ID", "ci-run-42")
+	if context := generate(); context["chainId"] != "ci-run-42" {
+		t.Errorf("expected the chain ID from the environment, got %v", context["chainId"])
+	}
+
+	context := generate("--chain-id", "chain-1", "--link-from", "event-1")
+	if context["chainId"] != "chain-1" {
+		t.Errorf("--chain-id should override the environment, got %v", context["chainId"])
+	}
+	links, _ := context["links"].([]interface{})
+	if len(links) != 1 || links[0].(map[string]interface{})["linkType"] != "PATH" {
+		t.Errorf("expected a PATH link, got %v", context["links"])
+	}
+
+	context = generate("--link-from", "event-1", "--link-relation", "caused-by")
+	links, _ = context["links"].([]interface{})
+	if len(links) != 1 || links[0].(map[string]interface{})["linkKind"] != "caused-by" {
+		t.Errorf("expected a RELATION link, got %v", context["links"])
+	}
+
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "pipeline-1", "--name", "p", "--link-from", "", "--link-relation", "caused-by"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--link-relation requires --link-from") {
+		t.Errorf("expected a missing --link-from error, got %v", err)
+	}
+}
+
+func TestSendCustomData(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the custom data flag for the following tests
+		os.Args = []string{"cdevents-cli", "send", "--target", "console", "pipeline", "started", "--id", "1", "--name", "reset", "--custom-json", ""}
+		cmd.Execute()
+		os.Args = originalArgs
+	}()
+
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	// Set the target and timeout through viper as flag bindings do not survive viper.Reset in other tests
+	viper.Set("target", server.URL)
+	viper.Set("timeout", 10*time.Second)
+	defer func() {
+		viper.Set("target", "console")
+		viper.Set("timeout", 30*time.Second)
+	}()
+
+	os.Args = []string{"cdevents-cli", "send", "--target", server.URL, "pipeline", "started", "--id", "pipeline-1", "--name", "p", "--custom-json", `{"team":"platform"}`}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to send event: %v", err)
+	}
+
+	var event map[string]interface{}
+	var body []byte
+	select {
+	case body = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event received")
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("failed to decode received event: %v", err)
+	}
+	customData, _ := event["customData"].(map[string]interface{})
+	if customData["team"] != "platform" || event["customDataContentType"] != "application/json" {
+		t.Errorf("expected custom data at the receiver, got %v", event)
+	}
+}
+
+func TestCustomDataInputs(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the custom data flags for the following tests, --custom values can't be reset
+		// so it is only used with the task command
+		cmd.SetOut(io.Discard)
+		for _, command := range []string{"task", "build"} {
+			os.Args = []string{"cdevents-cli", "generate", command, "started", "--id", "1", "--name", "reset", "--custom-json", "", "--custom-data", "", "--custom-yaml", "", "--custom-data-content-type", ""}
+			cmd.Execute()
+		}
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	generate := func(command string, args ...string) map[string]interface{} {
+		t.Helper()
+		out.Reset()
+		// Custom data flags keep their values between executions, clear those of earlier tests
+		os.Args = append([]string{"cdevents-cli", "generate", command, "started", "--id", "1", "--name", "n", "--custom-json", "", "--custom-data", "", "--custom-yaml", ""}, args...)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("failed to generate event: %v", err)
+		}
+		var event map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+			t.Fatalf("output should be valid JSON: %v", err)
+		}
+		return event
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "custom.yaml"), []byte("team: platform\nbuild:\n  tool: make\n"), 0o644); err != nil {
+		t.Fatalf("failed to write custom data: %v", err)
+	}
+	event := generate("task", "--custom-yaml", "@"+filepath.Join(dir, "custom.yaml"), "--custom", "build.number=42")
+	customData, _ := event["customData"].(map[string]interface{})
+	build, _ := customData["build"].(map[string]interface{})
+	if customData["team"] != "platform" || build["tool"] != "make" || build["number"] != float64(42) {
+		t.Errorf("unexpected custom data: %v", event["customData"])
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "custom.json"), []byte(`{"team":"payments"}`), 0o644); err != nil {
+		t.Fatalf("failed to write custom data: %v", err)
+	}
+	event = generate("build", "--custom-data", "@"+filepath.Join(dir, "custom.json"))
+	if customData, _ := event["customData"].(map[string]interface{}); customData["team"] != "payments" {
+		t.Errorf("unexpected custom data: %v", event["customData"])
+	}
+
+	// Non-JSON payloads are carried base64 encoded
+	event = generate("build", "--custom-data", "<build/>", "--custom-data-content-type", "application/xml")
+	if event["customData"] != "PGJ1aWxkLz4=" || event["customDataContentType"] != "application/xml" {
+		t.Errorf("unexpected custom data: %v (%v)", event["customData"], event["customDataContentType"])
+	}
+
+	os.Args = []string{"cdevents-cli", "generate", "build", "started", "--id", "1", "--name", "n", "--custom-data", "", "--custom-yaml", "team: platform", "--custom-json", `{"team":"payments"}`}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "only one of") {
+		t.Errorf("expected an error for several custom data inputs, got %v", err)
+	}
+}
+
+func TestContextOverrides(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the context flags for the following tests
+		cmd.SetOut(io.Discard)
+		os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "1", "--name", "reset", "--source", "", "--event-id", "", "--timestamp", "", "--subject-source", ""}
+		cmd.Execute()
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "pipeline-1", "--name", "p",
+		"--source", "https://ci.example.com/jenkins", "--event-id", "backfill-1", "--timestamp", "1714564800", "--subject-source", "https://git.example.com/repo"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to generate event: %v", err)
+	}
+	var event struct {
+		Context map[string]interface{} `json:"context"`
+		Subject map[string]interface{} `json:"subject"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("output should be valid JSON: %v", err)
+	}
+	if event.Context["source"] != "https://ci.example.com/jenkins" || event.Context["id"] != "backfill-1" || event.Context["timestamp"] != "2024-05-01T12:00:00Z" {
+		t.Errorf("context overrides not applied: %v", event.Context)
+	}
+	if event.Subject["source"] != "https://git.example.com/repo" {
+		t.Errorf("expected the subject source, got %v", event.Subject["source"])
+	}
+
+	os.Args = []string{"cdevents-cli", "generate", "pipeline", "started", "--id", "pipeline-1", "--name", "p", "--timestamp", "yesterday"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid timestamp") {
+		t.Errorf("expected an invalid timestamp error, got %v", err)
+	}
+}
+
+func TestGenerateCustomEvent(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the custom event flags for the following tests
+		cmd.SetOut(io.Discard)
+		os.Args = []string{"cdevents-cli", "generate", "custom", "--type", "dev.cdeventsx.reset-reset.reset.0.1.0", "--id", "1", "--content", "", "--schema", ""}
+		cmd.Execute()
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "flag-flipped.json")
+	if err := os.WriteFile(schema, []byte(`{"type": "object", "properties": {"enabled": {"type": "boolean"}}, "required": ["enabled"]}`), 0o644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	content := filepath.Join(dir, "subject.json")
+	if err := os.WriteFile(content, []byte(`{"enabled": true, "environment": "prod"}`), 0o644); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+
+	os.Args = []string{"cdevents-cli", "generate", "custom", "--type", "dev.cdeventsx.flags-flag.flipped.0.1.0", "--id", "checkout-v2",
+		"--schema", schema, "--content", "@" + content}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to generate custom event: %v", err)
+	}
+	var event struct {
+		Context map[string]interface{} `json:"context"`
+		Subject map[string]interface{} `json:"subject"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("output should be valid JSON: %v", err)
+	}
+	if event.Context["type"] != "dev.cdeventsx.flags-flag.flipped.0.1.0" || !strings.HasSuffix(event.Context["schemaUri"].(string), "/flag-flipped.json") {
+		t.Errorf("unexpected context: %v", event.Context)
+	}
+	if subjectContent, _ := event.Subject["content"].(map[string]interface{}); subjectContent["enabled"] != true {
+		t.Errorf("unexpected subject: %v", event.Subject)
+	}
+
+	os.Args = []string{"cdevents-cli", "generate", "custom", "--type", "dev.cdeventsx.flags-flag.flipped.0.1.0", "--id", "checkout-v2",
+		"--schema", schema, "--content", `{"enabled": "yes"}`}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "does not match schema") {
+		t.Errorf("expected a schema error, got %v", err)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the template flags for the following tests
+		cmd.SetOut(io.Discard)
+		os.Args = []string{"cdevents-cli", "generate", "--from-template", "", "--source", ""}
+		cmd.Execute()
+		os.Args = []string{"cdevents-cli", "send", "--from-template", ""}
+		cmd.Execute()
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	dir := t.TempDir()
+	deploy := filepath.Join(dir, "deploy.yaml")
+	if err := os.WriteFile(deploy, []byte(`type: service.deployed
+subject:
+  id: placeholder
+  name: ${SERVICE_NAME:-checkout}
+  environment: ${ENVIRONMENT}
+  artifactId: pkg:oci/checkout@sha256:abc
+customData:
+  team: platform
+chainId: chain-1
+source: https://template.example.com
+`), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	os.Args = []string{"cdevents-cli", "generate", "--from-template", deploy, "--set", "subject.id=checkout-svc", "--var", "ENVIRONMENT=prod", "--source", "https://deploy.example.com"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to generate event from template: %v", err)
+	}
+	cmd.SetOut(nil)
+	var event struct {
+		Context    map[string]interface{} `json:"context"`
+		Subject    map[string]interface{} `json:"subject"`
+		CustomData map[string]interface{} `json:"customData"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("output should be valid JSON: %v", err)
+	}
+	content, _ := event.Subject["content"].(map[string]interface{})
+	environment, _ := content["environment"].(map[string]interface{})
+	if event.Subject["id"] != "checkout-svc" || environment["id"] != "prod" || event.CustomData["team"] != "platform" {
+		t.Errorf("unexpected event: %s", out.String())
+	}
+	if event.Context["chainId"] != "chain-1" || event.Context["source"] != "https://deploy.example.com" || !strings.HasPrefix(event.Context["type"].(string), "dev.cdevents.service.deployed.") {
+		t.Errorf("unexpected context: %v", event.Context)
+	}
+
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	// Set the target and timeout through viper as flag bindings do not survive viper.Reset in other tests
+	viper.Set("target", server.URL)
+	viper.Set("timeout", 10*time.Second)
+	defer func() {
+		viper.Set("target", "console")
+		viper.Set("timeout", 30*time.Second)
+	}()
+
+	pipeline := filepath.Join(dir, "pipeline.yaml")
+	if err := os.WriteFile(pipeline, []byte("type: pipeline.started\nsubject:\n  id: ${PIPELINE_ID}\n  name: release\n"), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	t.Setenv("PIPELINE_ID", "pipeline-42")
+	os.Args = []string{"cdevents-cli", "send", "--target", server.URL, "--from-template", pipeline, "--set", "subject.url=https://ci.example.com/42"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to send event from template: %v", err)
+	}
+	var body []byte
+	select {
+	case body = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event received")
+	}
+	var sent struct {
+		Subject map[string]interface{} `json:"subject"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("failed to decode received event: %v", err)
+	}
+	if sent.Subject["id"] != "pipeline-42" {
+		t.Errorf("expected the subject ID from the environment, got %s", body)
+	}
+
+	os.Unsetenv("PIPELINE_ID")
+	os.Args = []string{"cdevents-cli", "generate", "--from-template", pipeline}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "PIPELINE_ID") {
+		t.Errorf("expected an undefined variable error, got %v", err)
+	}
+}
+
+func TestSendFromFile(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the batch flags for the following tests
+		cmd.SetOut(io.Discard)
+		os.Args = []string{"cdevents-cli", "send", "--from-file", "", "--concurrency", "4", "--progress-every", "1000"}
+		cmd.Execute()
+		cmd.SetOut(nil)
+		cmd.SetErr(nil)
+		cmd.SetIn(nil)
+		os.Args = originalArgs
+	}()
+
+	var mu sync.Mutex
+	received := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			Context struct {
+				ID string `json:"id"`
+			} `json:"context"`
+			Subject struct {
+				ID string `json:"id"`
+			} `json:"subject"`
+		}
+		json.NewDecoder(r.Body).Decode(&event)
+		if event.Subject.ID == "pipeline-rejected" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// Delay some events so that unordered delivery would show
+		if strings.HasSuffix(event.Context.ID, "-0") {
+			time.Sleep(20 * time.Millisecond)
+		}
+		mu.Lock()
+		received[event.Subject.ID] = append(received[event.Subject.ID], event.Context.ID)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	// Set the target and timeout through viper as flag bindings do not survive viper.Reset in other tests
+	viper.Set("target", server.URL)
+	viper.Set("timeout", 10*time.Second)
+	defer func() {
+		viper.Set("target", "console")
+		viper.Set("timeout", 30*time.Second)
+	}()
+
+	var lines []string
+	for i := 0; i < 5; i++ {
+		for _, subject := range []string{"pipeline-a", "pipeline-b", "pipeline-c"} {
+			lines = append(lines, fmt.Sprintf(`{"context": {"version": "0.4.1", "id": "%s-%d", "source": "ci", "type": "dev.cdevents.pipelinerun.started.0.2.0", "timestamp": "2024-01-01T00:00:00Z"}, "subject": {"id": "%s", "content": {"pipelineName": "p", "url": "https://ci.example.com"}}}`, subject, i, subject))
+		}
+	}
+	// The last event has an unknown type
+	lines = append(lines, `{"context": {"version": "0.4.1", "id": "bad", "source": "ci", "type": "dev.cdevents.pipelinerun.exploded.0.2.0", "timestamp": "2024-01-01T00:00:00Z"}, "subject": {"id": "pipeline-d", "content": {}}}`)
+	// The target rejects the events of pipeline-rejected
+	lines = append(lines, `{"context": {"version": "0.4.1", "id": "rejected", "source": "ci", "type": "dev.cdevents.pipelinerun.started.0.2.0", "timestamp": "2024-01-01T00:00:00Z"}, "subject": {"id": "pipeline-rejected", "content": {"pipelineName": "p", "url": "https://ci.example.com"}}}`)
+
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	cmd.SetIn(strings.NewReader(strings.Join(lines, "\n")))
+	os.Args = []string{"cdevents-cli", "send", "--target", server.URL, "--from-file", "-", "--concurrency", "3", "--progress-every", "5"}
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "2 of 17 events failed to send") {
+		t.Errorf("expected the invalid and rejected events to fail, got %v", err)
+	}
+
+	for _, subject := range []string{"pipeline-a", "pipeline-b", "pipeline-c"} {
+		want := make([]string, 0, 5)
+		for i := 0; i < 5; i++ {
+			want = append(want, fmt.Sprintf("%s-%d", subject, i))
+		}
+		if got := strings.Join(received[subject], ","); got != strings.Join(want, ",") {
+			t.Errorf("expected the events of %s in order, got %s", subject, got)
+		}
+	}
+	if len(received["pipeline-d"]) != 0 {
+		t.Errorf("the invalid event should not be sent")
+	}
+	for _, want := range []string{"Failed to send stdin#16", "Failed to send stdin#17", "Progress: 5 events", "Sent 15 of 17 events"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("expected %q on stderr, got:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestReceiveCommand(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	// Reserve a free port for the server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	outputFile := filepath.Join(t.TempDir(), "received.ndjson")
+	os.Args = []string{"cdevents-cli", "receive", "--address", address, "--path", "/events", "--count", "2", "--output-file", outputFile}
+	cmd.SetErr(io.Discard)
+	defer cmd.SetErr(nil)
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Execute()
+	}()
+
+	factory := events.NewEventFactory("test-source")
+	post := func(subjectID string) (int, error) {
+		event, err := factory.CreatePipelineRunEvent("started", subjectID, "p", "", "", "https://ci.example.com", nil)
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		ce, err := api.AsCloudEvent(event)
+		if err != nil {
+			t.Fatalf("failed to create CloudEvent: %v", err)
+		}
+		body, _ := json.Marshal(ce)
+		resp, err := http.Post("http://"+address+"/events", "application/cloudevents+json", bytes.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// Wait for the server to start
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := post("pipeline-1")
+		if err == nil {
+			if status != http.StatusAccepted {
+				t.Fatalf("expected the event to be accepted, got %d", status)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if status, err := post("pipeline-2"); err != nil || status != http.StatusAccepted {
+		t.Fatalf("expected the event to be accepted, got %d, %v", status, err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("receive failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("receive did not stop after --count events")
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	documents, err := validation.DecodeDocuments("received", data)
+	if err != nil || len(documents) != 2 {
+		t.Fatalf("expected 2 received events, got %d: %v", len(documents), err)
+	}
+	if subject, _ := documents[1].Event["subject"].(map[string]interface{}); subject["id"] != "pipeline-2" {
+		t.Errorf("unexpected received event: %v", documents[1].Event)
+	}
+}
+
+func TestRelayCommand(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	// The sink fails events of the pipeline-failing subject
+	forwarded := make(chan []byte, 2)
+	var failedAttempts int32
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer relay" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if bytes.Contains(body, []byte(`"pipeline-failing"`)) {
+			atomic.AddInt32(&failedAttempts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		forwarded <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	outputFile := filepath.Join(t.TempDir(), "events.ndjson")
+	os.Args = []string{"cdevents-cli", "relay", "--from", "http://" + address + "/events", "--to", sink.URL, "--to", "file://" + outputFile,
+		"--headers", "Authorization=Bearer relay",
+		"--type", "dev.cdevents.pipelinerun.*", "--source", "https://relay.example.com", "--custom", "relay.zone=a", "--count", "1"}
+	cmd.SetErr(io.Discard)
+	defer cmd.SetErr(nil)
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Execute()
+	}()
+
+	factory := events.NewEventFactory("test-source")
+	task, err := factory.CreateTaskRunEvent("started", "task-1", "compile", "pipeline-1", "", "", "https://ci.example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	pipeline, err := factory.CreatePipelineRunEvent("started", "pipeline-1", "p", "", "", "https://ci.example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	relayTarget, err := transport.NewHTTPTransport("http://" + address + "/events")
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+
+	// Wait for the relay to start; the task event is filtered out
+	deadline := time.Now().Add(5 * time.Second)
+	for relayTarget.Send(context.Background(), task) != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("relay did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Events the target does not accept are retried, then answered with 502 so producers can retry them
+	failing, err := factory.CreatePipelineRunEvent("started", "pipeline-failing", "p", "", "", "https://ci.example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	ce, err := api.AsCloudEvent(failing)
+	if err != nil {
+		t.Fatalf("failed to create CloudEvent: %v", err)
+	}
+	body, _ := json.Marshal(ce)
+	resp, err := http.Post("http://"+address+"/events", "application/cloudevents+json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to send event to the relay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected 502 for an event the target failed, got %d", resp.StatusCode)
+	}
+	if attempts := atomic.LoadInt32(&failedAttempts); attempts != 4 {
+		t.Errorf("expected the failed event to be sent with 3 retries, got %d attempts", attempts)
+	}
+
+	if err := relayTarget.Send(context.Background(), pipeline); err != nil {
+		t.Fatalf("failed to send event to the relay: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("relay failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("relay did not stop after --count events")
+	}
+
+	if len(forwarded) != 1 {
+		t.Fatalf("expected only the pipeline event to be forwarded, got %d events", len(forwarded))
+	}
+	var event struct {
+		Context    map[string]interface{} `json:"context"`
+		CustomData map[string]interface{} `json:"customData"`
+	}
+	if err := json.Unmarshal(<-forwarded, &event); err != nil {
+		t.Fatalf("failed to decode forwarded event: %v", err)
+	}
+	relay, _ := event.CustomData["relay"].(map[string]interface{})
+	if event.Context["id"] != pipeline.GetId() || event.Context["source"] != "https://relay.example.com" || relay["zone"] != "a" {
+		t.Errorf("unexpected forwarded event: %+v", event)
+	}
+
+	// The file target got both events, the sink failing one of them does not affect it
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read relayed events: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 events appended to the file, got %d:\n%s", len(lines), data)
+	}
+	for i, id := range []string{failing.GetId(), pipeline.GetId()} {
+		if err := json.Unmarshal([]byte(lines[i]), &event); err != nil || event.Context["id"] != id {
+			t.Errorf("expected event %s on line %d, got %s (%v)", id, i+1, lines[i], err)
+		}
+	}
+}
+
+func TestWrapCommand(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	received := make(chan []byte, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	cmd.SetOut(io.Discard)
+	defer func() {
+		cmd.SetErr(nil)
+		cmd.SetOut(nil)
+	}()
+
+	os.Args = []string{"cdevents-cli", "wrap", "--kind", "task", "--id", "build-42", "--name", "compile", "--pipeline", "pipeline-7",
+		"--target", server.URL, "--", "sh", "-c", "echo compiling; echo 'error: undefined symbol' >&2; exit 3"}
+	err := cmd.Execute()
+	var exitErr *cmd.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("expected the exit status of the command, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "error: undefined symbol") {
+		t.Errorf("expected the command's stderr to be passed through, got %q", stderr.String())
+	}
+
+	type wrapEvent struct {
+		Context struct {
+			ID    string        `json:"id"`
+			Type  string        `json:"type"`
+			Links []interface{} `json:"links"`
+		} `json:"context"`
+		Subject struct {
+			Content map[string]interface{} `json:"content"`
+		} `json:"subject"`
+		CustomData map[string]interface{} `json:"customData"`
+	}
+	var started, finished wrapEvent
+	for _, event := range []*wrapEvent{&started, &finished} {
+		select {
+		case body := <-received:
+			if err := json.Unmarshal(body, event); err != nil {
+				t.Fatalf("failed to decode event: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no event received")
+		}
+	}
+	if !strings.HasPrefix(started.Context.Type, "dev.cdevents.taskrun.started.") || !strings.HasPrefix(finished.Context.Type, "dev.cdevents.taskrun.finished.") {
+		t.Fatalf("unexpected event types: %s, %s", started.Context.Type, finished.Context.Type)
+	}
+	if finished.Subject.Content["outcome"] != "failure" || finished.Subject.Content["errors"] != "error: undefined symbol" {
+		t.Errorf("unexpected finished subject: %v", finished.Subject.Content)
+	}
+	if finished.CustomData["exitCode"] != float64(3) || finished.CustomData["durationSeconds"] == nil {
+		t.Errorf("expected the exit code and duration as custom data, got %v", finished.CustomData)
+	}
+	if len(finished.Context.Links) != 1 {
+		t.Errorf("expected the finished event to link to the started event, got %v", finished.Context.Links)
+	}
+
+	os.Args = []string{"cdevents-cli", "wrap", "--kind", "pipeline", "--id", "pipeline-7", "--name", "release", "--target", server.URL, "--", "true"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected a successful command to succeed, got %v", err)
+	}
+	<-received
+	var pipelineFinished wrapEvent
+	if err := json.Unmarshal(<-received, &pipelineFinished); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if pipelineFinished.Subject.Content["outcome"] != "success" || pipelineFinished.CustomData["exitCode"] != float64(0) {
+		t.Errorf("unexpected finished event: %+v", pipelineFinished)
+	}
+}
+
+func TestFromCI(t *testing.T) {
+	originalArgs := os.Args
+	defer func() {
+		// Reset the flags filled from the CI environment for the following tests
+		cmd.SetOut(io.Discard)
+		os.Args = []string{"cdevents-cli", "generate", "task", "started", "--id", "1", "--name", "reset", "--url", "", "--pipeline", "", "--source", "", "--from-ci=false"}
+		cmd.Execute()
+		cmd.SetOut(nil)
+		os.Args = originalArgs
+	}()
+
+	for _, name := range []string{"GITLAB_CI", "JENKINS_URL", "TEKTON_PIPELINE_RUN", "TF_BUILD", "CIRCLECI", "BUILDKITE"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "acme/shop")
+	t.Setenv("GITHUB_RUN_ID", "123")
+	t.Setenv("GITHUB_RUN_ATTEMPT", "1")
+	t.Setenv("GITHUB_WORKFLOW", "release")
+	t.Setenv("GITHUB_JOB", "compile")
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	// Clear the subject flags left by earlier tests so they are filled from the environment
+	os.Args = []string{"cdevents-cli", "generate", "task", "started", "--id", "", "--name", "", "--url", "", "--pipeline", "", "--source", "", "--from-ci"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to generate event from the CI environment: %v", err)
+	}
+	var event struct {
+		Context map[string]interface{} `json:"context"`
+		Subject struct {
+			ID      string                 `json:"id"`
+			Content map[string]interface{} `json:"content"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("output should be valid JSON: %v", err)
+	}
+	if event.Subject.ID != "123-compile" || event.Subject.Content["taskName"] != "compile" {
+		t.Errorf("expected the subject from the CI job, got %s %v", event.Subject.ID, event.Subject.Content)
+	}
+	if event.Subject.Content["url"] != "https://github.com/acme/shop/actions/runs/123" {
+		t.Errorf("expected the job URL, got %v", event.Subject.Content["url"])
+	}
+	if event.Context["source"] != "https://github.com/acme/shop" {
+		t.Errorf("expected the repository as source, got %v", event.Context["source"])
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	os.Args = []string{"cdevents-cli", "generate", "task", "started", "--from-ci"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "no supported CI environment") {
+		t.Errorf("expected an error outside of CI, got %v", err)
+	}
+}
+
+func TestImportJUnit(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	report := filepath.Join(t.TempDir(), "results.xml")
+	if err := os.WriteFile(report, []byte(`<testsuite name="cart" timestamp="2024-05-01T12:00:00Z" time="3">
+  <testcase name="adds" classname="cart" time="1"/>
+  <testcase name="removes" classname="cart" time="2"><failure message="expected 0 items"/></testcase>
+  <testcase name="checks out" classname="cart"><skipped/></testcase>
+</testsuite>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+
+	os.Args = []string{"cdevents-cli", "import", "junit", report, "--environment", "ci"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to import report: %v", err)
+	}
+	type testEvent struct {
+		Context map[string]interface{} `json:"context"`
+		Subject struct {
+			ID      string                 `json:"id"`
+			Content map[string]interface{} `json:"content"`
+		} `json:"subject"`
+	}
+	var types []string
+	var finished testEvent
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var event testEvent
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatalf("output should be a stream of JSON events: %v", err)
+		}
+		eventType := strings.Split(event.Context["type"].(string), ".")
+		types = append(types, eventType[2]+"."+eventType[3])
+		if event.Subject.ID == "cart.removes" && eventType[3] == "finished" {
+			finished = event
+		}
+	}
+	want := "testsuiterun.started testcaserun.started testcaserun.finished testcaserun.started testcaserun.finished testcaserun.skipped testsuiterun.finished"
+	if strings.Join(types, " ") != want {
+		t.Errorf("unexpected events:\n got %s\nwant %s", strings.Join(types, " "), want)
+	}
+	if finished.Subject.Content["outcome"] != "fail" || finished.Subject.Content["reason"] != "expected 0 items" || finished.Context["timestamp"] != "2024-05-01T12:00:03Z" {
+		t.Errorf("unexpected finished event: %+v", finished)
+	}
+
+	var received int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	defer cmd.SetErr(nil)
+	os.Args = []string{"cdevents-cli", "import", "junit", report, "--environment", "ci", "--send", "--target", server.URL}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to send imported events: %v", err)
+	}
+	if received != 7 || !strings.Contains(stderr.String(), "Sent 7 of 7 events") {
+		t.Errorf("expected 7 events to be sent, got %d: %s", received, stderr.String())
+	}
+}
+
+func TestImportStreams(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+	defer cmd.SetIn(nil)
+
+	eventTypes := func() []string {
+		var types []string
+		decoder := json.NewDecoder(&out)
+		for decoder.More() {
+			var event struct {
+				Context map[string]interface{} `json:"context"`
+			}
+			if err := decoder.Decode(&event); err != nil {
+				t.Fatalf("output should be a stream of JSON events: %v", err)
+			}
+			eventType := strings.Split(event.Context["type"].(string), ".")
+			types = append(types, eventType[2]+"."+eventType[3])
+		}
+		out.Reset()
+		return types
+	}
+
+	cmd.SetIn(strings.NewReader(`{"Time":"2024-05-01T12:00:00Z","Action":"start","Package":"example.com/a"}
+{"Time":"2024-05-01T12:00:01Z","Action":"run","Package":"example.com/a","Test":"TestA"}
+{"Time":"2024-05-01T12:00:02Z","Action":"fail","Package":"example.com/a","Test":"TestA","Elapsed":1}
+{"Time":"2024-05-01T12:00:02Z","Action":"fail","Package":"example.com/a","Elapsed":2}
+`))
+	os.Args = []string{"cdevents-cli", "import", "gotest", "--environment", "ci", "--send=false"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to import go test stream: %v", err)
+	}
+	want := "testsuiterun.started testcaserun.started testcaserun.finished testsuiterun.finished"
+	if got := strings.Join(eventTypes(), " "); got != want {
+		t.Errorf("unexpected go test events:\n got %s\nwant %s", got, want)
+	}
+
+	stream := filepath.Join(t.TempDir(), "results.tap")
+	if err := os.WriteFile(stream, []byte("1..2\nok 1 - first\nok 2 - second # SKIP later\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"cdevents-cli", "import", "tap", stream, "--suite", "unit", "--environment", "ci", "--send=false"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to import TAP stream: %v", err)
+	}
+	want = "testsuiterun.started testcaserun.started testcaserun.finished testcaserun.skipped testsuiterun.finished"
+	if got := strings.Join(eventTypes(), " "); got != want {
+		t.Errorf("unexpected TAP events:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestTranslateGitHub(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+	defer cmd.SetIn(nil)
+	cmd.SetErr(io.Discard)
+	defer cmd.SetErr(nil)
+
+	payload := `{"action": "closed", "pull_request": {"number": 42, "merged": true},
+		"repository": {"name": "hello-world", "full_name": "octo-org/hello-world", "html_url": "https://github.com/octo-org/hello-world"}}`
+	cmd.SetIn(strings.NewReader(payload))
+	os.Args = []string{"cdevents-cli", "translate", "github", "--event", "pull_request", "--send=false"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to translate payload: %v", err)
+	}
+	var event struct {
+		Context map[string]interface{} `json:"context"`
+		Subject map[string]interface{} `json:"subject"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("expected a JSON event, got %q: %v", out.String(), err)
+	}
+	if eventType, _ := event.Context["type"].(string); !strings.HasPrefix(eventType, "dev.cdevents.change.merged.") || event.Subject["id"] != "42" {
+		t.Errorf("unexpected translated event: %v", event)
+	}
+
+	// Payloads are verified against their signature
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "secret")
+	out.Reset()
+	cmd.SetIn(strings.NewReader(payload))
+	os.Args = []string{"cdevents-cli", "translate", "github", "--event", "pull_request", "--signature", "sha256=00"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Errorf("expected a signature error, got %v", err)
+	}
+	os.Args = []string{"cdevents-cli", "translate", "github", "--event", "pull_request", "--signature", ""}
+	cmd.SetIn(strings.NewReader(payload))
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("failed to reset --signature: %v", err)
+	}
+}
+
+func TestReceiveGitHubWebhooks(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	cmd.SetErr(io.Discard)
+	defer cmd.SetErr(nil)
+
+	// Webhooks are not accepted unverified
+	t.Setenv("GITLAB_WEBHOOK_TOKEN", "")
+	os.Args = []string{"cdevents-cli", "receive", "--address", address, "--gitlab-path", "/gitlab"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "$GITLAB_WEBHOOK_TOKEN") {
+		t.Fatalf("expected receive to refuse webhooks without secret token, got %v", err)
+	}
+	outputFile := filepath.Join(t.TempDir(), "received.ndjson")
+	os.Args = []string{"cdevents-cli", "receive", "--address", address, "--path", "/events", "--gitlab-path", "", "--github-path", "/github", "--count", "1", "--output-file", outputFile}
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "")
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "$GITHUB_WEBHOOK_SECRET") {
+		t.Fatalf("expected receive to refuse webhooks without secret, got %v", err)
+	}
+
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "secret")
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Execute()
+	}()
+
+	payload := `{"ref": "refs/heads/feature", "created": true,
+		"repository": {"name": "hello-world", "full_name": "octo-org/hello-world", "html_url": "https://github.com/octo-org/hello-world"}}`
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		req, _ := http.NewRequest(http.MethodPost, "http://"+address+"/github", strings.NewReader(payload))
+		req.Header.Set("X-GitHub-Event", "push")
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(payload))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusAccepted {
+				t.Fatalf("expected the webhook to be accepted, got %d", resp.StatusCode)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("receive failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("receive did not stop after --count events")
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if !strings.Contains(string(data), "dev.cdevents.branch.created.") {
+		t.Errorf("expected a branch created event, got %s", data)
+	}
+}
+
+func TestTranslateGitLab(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	defer cmd.SetOut(nil)
+	defer cmd.SetIn(nil)
+	cmd.SetErr(io.Discard)
+	defer cmd.SetErr(nil)
+
+	payload := filepath.Join(t.TempDir(), "job-hook.json")
+	if err := os.WriteFile(payload, []byte(`{"object_kind": "build", "build_id": 1977, "build_name": "test", "build_status": "success", "pipeline_id": 31,
+		"project": {"name": "Gitlab Test", "path_with_namespace": "gitlab-org/gitlab-test", "web_url": "https://gitlab.example.com/gitlab-org/gitlab-test"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITLAB_WEBHOOK_TOKEN", "secret")
+	os.Args = []string{"cdevents-cli", "translate", "gitlab", payload, "--token", "secret", "--send=false"}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("failed to translate payload: %v", err)
+	}
+	var event struct {
+		Context map[string]interface{} `json:"context"`
+		Subject map[string]interface{} `json:"subject"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("expected a JSON event, got %q: %v", out.String(), err)
+	}
+	if eventType, _ := event.Context["type"].(string); !strings.HasPrefix(eventType, "dev.cdevents.taskrun.finished.") || event.Subject["id"] != "1977" {
+		t.Errorf("unexpected translated event: %v", event)
+	}
+
+	// Payloads are verified against the secret token
+	os.Args = []string{"cdevents-cli", "translate", "gitlab", payload, "--token", "other"}
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Errorf("expected a token error, got %v", err)
+	}
+	os.Args = []string{"cdevents-cli", "translate", "gitlab", payload, "--token", ""}
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("failed to reset --token: %v", err)
+	}
+}
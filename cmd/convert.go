@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/spf13/cobra"
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert [file|-]...",
+	Short: "Convert CDEvents between spec versions",
+	Long: `Convert CDEvents read from files or stdin to another CDEvents spec version.
+
+Events are upgraded or downgraded to the equivalent event type of the target
+version. Fields the target version does not define, such as the v0.4 chainId
+and links when converting to v0.3, are dropped and reported on stderr.
+Converted events are validated against the schemas of the target version.
+
+Supported spec versions: ` + strings.Join(events.SpecVersions(), ", ") + `
+
+Examples:
+  # Downgrade an event for a consumer that speaks v0.3
+  cdevents-cli convert event.json --to 0.3
+
+  # Upgrade events and fail if anything would be lost
+  cat events.ndjson | cdevents-cli convert --to 0.4 --strict`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to := cmd.Flag("to").Value.String()
+		if _, err := events.ResolveSpecVersion(to); err != nil {
+			return err
+		}
+		inputs, err := expandInputs(args)
+		if err != nil {
+			return err
+		}
+		strict, _ := cmd.Flags().GetBool("strict")
+		// Usage is not helpful once the arguments have been accepted
+		cmd.SilenceUsage = true
+
+		format := cmd.Flag("output").Value.String()
+		for _, input := range inputs {
+			documents, err := readDocuments(cmd, input)
+			if err != nil {
+				return err
+			}
+			for _, doc := range documents {
+				eventMap, _ := doc.CDEvent()
+				if eventMap == nil {
+					return fmt.Errorf("%s: no CDEvent found", doc.Name)
+				}
+				event, changes, err := events.ConvertEvent(eventMap, to)
+				if err != nil {
+					return fmt.Errorf("%s: %w", doc.Name, err)
+				}
+				if len(changes) > 0 {
+					lines := make([]string, 0, len(changes))
+					for _, change := range changes {
+						lines = append(lines, "  "+change.String())
+					}
+					if strict {
+						return fmt.Errorf("%s: conversion to spec version %s is lossy:\n%s", doc.Name, event.GetVersion(), strings.Join(lines, "\n"))
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s: conversion to spec version %s is lossy:\n%s\n", doc.Name, event.GetVersion(), strings.Join(lines, "\n"))
+				}
+				if err := outputEvent(cmd, event, format); err != nil {
+					return fmt.Errorf("%s: %w", doc.Name, err)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().String("to", "", "Target spec version (0.3, 0.4, 0.5)")
+	convertCmd.Flags().Bool("strict", false, "Fail instead of dropping fields the target spec version does not define")
+	convertCmd.Flags().Bool("no-validate", false, "Skip validating converted events against the CDEvents schemas and semantic rules")
+	convertCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+// addEmitterFlags adds the output, transport and validation flags of commands emitting events with eventEmitter
+// to a command and its subcommands
+func addEmitterFlags(cmd *cobra.Command) {
+	// Output destination flags
+	cmd.PersistentFlags().String("output-file", "-", "Write events to file instead of stdout (- for stdout)")
+	cmd.PersistentFlags().Bool("append", true, "Append events to the output file instead of replacing it with each event")
+
+	// Transport flags
+	cmd.PersistentFlags().Bool("send", false, "Send events to --target instead of printing them")
+	cmd.PersistentFlags().StringP("target", "t", "console", "Target to send events to with --send (console, http://..., file://...)")
+	cmd.PersistentFlags().IntP("retries", "r", 3, "Number of retry attempts")
+	cmd.PersistentFlags().Duration("timeout", 30*time.Second, "Request timeout")
+
+	// Schema validation flag
+	cmd.PersistentFlags().Bool("no-validate", false, "Skip validating events against the CDEvents schemas and semantic rules")
+	cmd.PersistentFlags().StringSlice("disable-rule", []string{}, "Semantic rules to skip (finished-outcome, outcome-errors, taskrun-pipelinerun, timestamp-not-in-future)")
+	cmd.PersistentFlags().String("policy", "", "Policy file with organisation conventions events must follow")
+}
+
+// eventEmitter prints events in the --output format, or sends them to --target with --send
+type eventEmitter struct {
+	cmd       *cobra.Command
+	transport transport.Transport
+	sent      int
+	failed    int
+}
+
+// newEventEmitter creates an emitter for the flags added by addEmitterFlags
+func newEventEmitter(cmd *cobra.Command) (*eventEmitter, error) {
+	e := &eventEmitter{cmd: cmd}
+	if send, _ := cmd.Flags().GetBool("send"); send {
+		var err error
+		e.transport, err = transport.NewTransportFactory().CreateTransport(cmd.Flag("target").Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transport: %w", err)
+		}
+	}
+	// Usage is not helpful once events are emitted
+	cmd.SilenceUsage = true
+	return e, nil
+}
+
+// emit prints the event, or sends it reporting delivery failures as warnings
+func (e *eventEmitter) emit(event api.CDEvent) error {
+	if e.transport == nil {
+		format := e.cmd.Flag("output").Value.String()
+		if err := outputEvent(e.cmd, event, format); err != nil {
+			return err
+		}
+		// Keep events printed to stdout line separated, as files are
+		if outputFile := e.cmd.Flag("output-file").Value.String(); (outputFile == "" || outputFile == "-") && !output.IsBinaryFormat(format) {
+			fmt.Fprintln(e.cmd.OutOrStdout())
+		}
+		return nil
+	}
+
+	if err := validateEvent(e.cmd, event); err != nil {
+		return err
+	}
+	retries, _ := e.cmd.Flags().GetInt("retries")
+	timeout, _ := e.cmd.Flags().GetDuration("timeout")
+	if err := deliverEvent(e.transport, event, retries, timeout); err != nil {
+		e.failed++
+		fmt.Fprintf(e.cmd.ErrOrStderr(), "Warning: failed to send %s event of %s: %v\n", event.GetType(), event.GetSubjectId(), err)
+		return nil
+	}
+	e.sent++
+	return nil
+}
+
+// close reports the sent events, failing when some could not be sent
+func (e *eventEmitter) close() error {
+	if e.transport == nil {
+		return nil
+	}
+	fmt.Fprintf(e.cmd.ErrOrStderr(), "Sent %d of %d events to %s, %d failed\n", e.sent, e.sent+e.failed, e.cmd.Flag("target").Value.String(), e.failed)
+	if e.failed > 0 {
+		return fmt.Errorf("%d of %d events failed to send", e.failed, e.sent+e.failed)
+	}
+	return nil
+}
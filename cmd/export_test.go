@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ResetFlags restores the flags of all commands to their defaults and clears their Changed state,
+// so tests don't depend on the flags set by the tests that ran before them
+func ResetFlags() {
+	var reset func(c *cobra.Command)
+	reset = func(c *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags()} {
+			flags.VisitAll(resetFlag)
+		}
+		for _, sub := range c.Commands() {
+			reset(sub)
+		}
+	}
+	reset(rootCmd)
+}
+
+// resetFlag restores a flag to its default value, slice flags would append to their values with Set
+func resetFlag(flag *pflag.Flag) {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		var values []string
+		if defaults := strings.Trim(flag.DefValue, "[]"); defaults != "" {
+			values = strings.Split(defaults, ",")
+		}
+		slice.Replace(values)
+	} else {
+		flag.Value.Set(flag.DefValue)
+	}
+	flag.Changed = false
+}
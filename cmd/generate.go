@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/brunseba/cdevents-tools/pkg/events"
 	"github.com/brunseba/cdevents-tools/pkg/output"
+	"github.com/brunseba/cdevents-tools/pkg/policy"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
 	"github.com/cdevents/sdk-go/pkg/api"
 
 	"github.com/spf13/cobra"
@@ -23,6 +27,7 @@ Supported event types:
 - build: Build events (queued, started, finished)
 - service: Service deployment events (deployed, published, removed, rolledback, upgraded)
 - test: Test events (testcase-queued, testcase-started, testcase-finished, etc.)
+- custom: Custom dev.cdeventsx events with user schemas
 
 Examples:
   # Generate a pipeline started event
@@ -35,18 +40,65 @@ Examples:
   cdevents-cli generate task started --id "task-101" --name "my-task" --custom-json '{"key":"value"}'
 
 # Generate a service deployed event
-  cdevents-cli generate service deployed --id "service-789" --name "my-service" --environment "prod"`,
+  cdevents-cli generate service deployed --id "service-789" --name "my-service" --environment "prod" --artifact-id "pkg:oci/my-service@v1.0.0"
+
+# Show the curl command that would send a pipeline started event
+  cdevents-cli generate pipeline started --id "pipeline-123" --name "my-pipeline" --output curl --http-target http://localhost:8080/events
+
+# Generate the event described by a template
+  cdevents-cli generate --from-template deploy.yaml --set subject.id=my-service --var ENVIRONMENT=prod`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flag("from-template").Value.String() == "" {
+			return cmd.Help()
+		}
+		event, err := eventFromTemplate(cmd)
+		if err != nil {
+			return err
+		}
+		return outputEvent(cmd, event, cmd.Flag("output").Value.String())
+	},
 }
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
+
+	// Flags used by the http, http-structured and curl output formats
+	generateCmd.PersistentFlags().String("http-target", output.DefaultHTTPTarget, "Target URL for http and curl output formats")
+	generateCmd.PersistentFlags().StringSlice("http-header", []string{}, "HTTP headers for http and curl output formats (format: key=value)")
+
+	viper.BindPFlag("http-target", generateCmd.PersistentFlags().Lookup("http-target"))
+	viper.BindPFlag("http-headers", generateCmd.PersistentFlags().Lookup("http-header"))
+
+	// Output destination flags
+	generateCmd.PersistentFlags().String("output-file", "-", "Write output to file instead of stdout (- for stdout)")
+	generateCmd.PersistentFlags().Bool("append", false, "Append to the output file instead of replacing it")
+
+	// Schema validation flag
+	generateCmd.PersistentFlags().Bool("no-validate", false, "Skip validating events against the CDEvents schemas and semantic rules")
+	generateCmd.PersistentFlags().StringSlice("disable-rule", []string{}, "Semantic rules to skip (finished-outcome, outcome-errors, taskrun-pipelinerun, timestamp-not-in-future)")
+	generateCmd.PersistentFlags().String("policy", "", "Policy file with organisation conventions events must follow")
+
+	addContextFlags(generateCmd)
+	addTemplateFlags(generateCmd)
+}
+
+// addContextFlags adds the event source, context attribute, chain ID and link flags to a command and its subcommands
+func addContextFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("source", "s", "", "Event source (defaults to hostname)")
+	cmd.PersistentFlags().String("event-id", "", "Event ID (defaults to a random UUID)")
+	cmd.PersistentFlags().String("timestamp", "", "Event timestamp as RFC3339 or unix seconds, e.g. to backfill historical runs (defaults to now)")
+	cmd.PersistentFlags().String("subject-source", "", "Source of the event subject (defaults to the event source)")
+	cmd.PersistentFlags().String("chain-id", "", "Chain ID correlating the events of a delivery flow (spec version 0.4 or later)")
+	cmd.PersistentFlags().String("chain-id-env", events.DefaultChainIDEnv, "Environment variable the chain ID is read from when --chain-id is not set (empty to disable)")
+	cmd.PersistentFlags().String("link-from", "", "ID of the event that preceded this one, added as a PATH link")
+	cmd.PersistentFlags().String("link-relation", "", "Add the --link-from event as a RELATION link of this kind, e.g. caused-by, instead of a PATH link")
 }
 
 // Common flags for all generate commands
 func addCommonGenerateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("id", "i", "", "Subject ID (required)")
 	cmd.Flags().StringP("name", "n", "", "Subject name (required)")
-	cmd.Flags().StringP("source", "s", "", "Event source (defaults to hostname)")
 	cmd.Flags().StringP("url", "u", "", "Subject URL")
 	cmd.Flags().StringP("outcome", "", "", "Outcome for finished events (success, failure, error, cancel)")
 	cmd.Flags().StringP("errors", "", "", "Error details for failed events")
@@ -54,25 +106,103 @@ func addCommonGenerateFlags(cmd *cobra.Command) {
 	cmd.MarkFlagRequired("id")
 	cmd.MarkFlagRequired("name")
 
-	// Custom data flag
+	addCustomDataFlags(cmd)
+}
+
+// addCustomDataFlags adds the custom data flags to a command
+func addCustomDataFlags(cmd *cobra.Command) {
 	cmd.Flags().String("custom-json", "", "Custom data in JSON format")
+	cmd.Flags().String("custom-data", "", "Custom data, inline or read from @file (@- for stdin), JSON unless --custom-data-content-type is set")
+	cmd.Flags().String("custom-yaml", "", "Custom data in YAML format, inline or read from @file")
+	cmd.Flags().StringArray("custom", []string{}, "Custom data field as key=value, dotted keys create nested objects (repeatable)")
+	cmd.Flags().String("custom-data-content-type", "", "Content type of --custom-data, e.g. application/xml or application/octet-stream (default application/json)")
 }
 
-// parseCustomData returns custom data parsed from JSON only
+// parseCustomData returns the custom data set by --custom-json, --custom-data or --custom-yaml,
+// with the fields of --custom key=value pairs set on top
 func parseCustomData(cmd *cobra.Command) (*events.CustomData, error) {
 	customJSON, err := cmd.Flags().GetString("custom-json")
 	if err != nil {
 		return nil, err
 	}
+	customData, _ := cmd.Flags().GetString("custom-data")
+	customYAML, _ := cmd.Flags().GetString("custom-yaml")
+	fields, _ := cmd.Flags().GetStringArray("custom")
+	contentType, _ := cmd.Flags().GetString("custom-data-content-type")
 
-	// Parse custom data from JSON only
-	if customJSON != "" {
-		return events.ParseCustomDataFromJSON(customJSON)
+	set := 0
+	for _, value := range []string{customJSON, customData, customYAML} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("only one of --custom-json, --custom-data and --custom-yaml can be set")
+	}
+	if contentType != "" && contentType != events.JSONContentType && customData == "" {
+		return nil, fmt.Errorf("--custom-data-content-type %s requires --custom-data", contentType)
 	}
 
-	return nil, nil
+	var result *events.CustomData
+	switch {
+	case customJSON != "":
+		result, err = events.ParseCustomDataFromJSON(customJSON)
+	case customData != "":
+		var data []byte
+		if data, err = readInlineOrFile(cmd, customData); err != nil {
+			return nil, err
+		}
+		result, err = events.ParseCustomData(data, contentType)
+	case customYAML != "":
+		var data []byte
+		if data, err = readInlineOrFile(cmd, customYAML); err != nil {
+			return nil, err
+		}
+		result, err = events.ParseCustomDataFromYAML(string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --custom %q: expected key=value", field)
+		}
+		if result == nil {
+			result = &events.CustomData{}
+		}
+		if err := result.SetField(strings.TrimSpace(key), value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// readInlineOrFile returns an inline value, or the content of the file of an @file value
+func readInlineOrFile(cmd *cobra.Command, value string) ([]byte, error) {
+	filename, ok := strings.CutPrefix(value, "@")
+	if !ok {
+		return []byte(value), nil
+	}
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return data, nil
 }
-func getDefaultSource() string {
+
+// getDefaultSource returns the event source set by --source, the source configuration key or the hostname
+func getDefaultSource(cmd *cobra.Command) string {
+	if flag := cmd.Flag("source"); flag != nil && flag.Changed && flag.Value.String() != "" {
+		return flag.Value.String()
+	}
 	if source := viper.GetString("source"); source != "" {
 		return source
 	}
@@ -84,29 +214,226 @@ func getDefaultSource() string {
 	return fmt.Sprintf("cdevents-cli/%s", hostname)
 }
 
-// outputEvent formats and outputs the event
-func outputEvent(event interface{}, format string) error {
-	return outputEventWithCustomData(event, nil, format)
+// newEventFactory creates an event factory for the spec version set by --spec-version or the spec-version configuration key
+func newEventFactory(cmd *cobra.Command) (*events.EventFactory, error) {
+	specVersion := viper.GetString("spec-version")
+	if flag := cmd.Flag("spec-version"); flag != nil && flag.Changed {
+		specVersion = flag.Value.String()
+	}
+	return events.NewEventFactoryForSpecVersion(getDefaultSource(cmd), specVersion)
+}
+
+// contextOptions returns the event options for the context attributes, chain ID and links set by the context flags.
+// A chain ID read from the environment is only propagated to events of spec versions that support it.
+func contextOptions(cmd *cobra.Command, factory *events.EventFactory) ([]events.EventOption, error) {
+	var opts []events.EventOption
+	flagValue := func(name string) string {
+		if flag := cmd.Flag(name); flag != nil {
+			return flag.Value.String()
+		}
+		return ""
+	}
+
+	// The factory already uses --source, setting it again overrides the source of a template
+	if flag := cmd.Flag("source"); flag != nil && flag.Changed && flag.Value.String() != "" {
+		opts = append(opts, events.WithSource(flag.Value.String()))
+	}
+	if id := flagValue("event-id"); id != "" {
+		opts = append(opts, events.WithEventID(id))
+	}
+	if value := flagValue("timestamp"); value != "" {
+		timestamp, err := events.ParseTimestamp(value)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, events.WithTimestamp(timestamp))
+	}
+	if subjectSource := flagValue("subject-source"); subjectSource != "" {
+		opts = append(opts, events.WithSubjectSource(subjectSource))
+	}
+
+	chainID := flagValue("chain-id")
+	if chainID == "" && factory.SupportsLinks() {
+		if env := flagValue("chain-id-env"); env != "" {
+			chainID = os.Getenv(env)
+		}
+	}
+	if chainID != "" {
+		opts = append(opts, events.WithChainID(chainID))
+	}
+
+	linkFrom, linkRelation := flagValue("link-from"), flagValue("link-relation")
+	switch {
+	case linkRelation != "" && linkFrom == "":
+		return nil, fmt.Errorf("--link-relation requires --link-from")
+	case linkRelation != "":
+		opts = append(opts, events.WithRelationLink(linkFrom, linkRelation))
+	case linkFrom != "":
+		opts = append(opts, events.WithPathLink(linkFrom))
+	}
+	return opts, nil
 }
 
-// outputEventWithCustomData formats and outputs the event with custom data
-func outputEventWithCustomData(event interface{}, customData *events.CustomData, format string) error {
+// outputEvent formats and outputs the event, including the custom data it carries
+func outputEvent(cmd *cobra.Command, event interface{}, format string) error {
 	if cdEvent, ok := event.(api.CDEvent); ok {
-		// Convert events.CustomData to output.CustomData
-		var outputCustomData *output.CustomData
-		if customData != nil {
-			outputCustomData = &output.CustomData{
-				Data:        customData.Data,
-				ContentType: customData.ContentType,
-			}
+		if err := validateEvent(cmd, cdEvent); err != nil {
+			return err
 		}
 
-		formatted, err := output.FormatOutputWithCustomData(cdEvent, outputCustomData, format)
+		var formatted string
+		var err error
+		switch format {
+		case "http", "http-structured":
+			formatted, err = output.FormatHTTPRequest(cdEvent, httpRenderOptions(format == "http-structured"))
+		case "curl":
+			formatted, err = output.FormatCurl(cdEvent, httpRenderOptions(false))
+		default:
+			formatted, err = output.FormatOutput(cdEvent, format)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to format output: %w", err)
 		}
-		fmt.Print(formatted)
-		return nil
+		return writeOutput(cmd, formatted, format)
 	}
 	return fmt.Errorf("invalid event type")
 }
+
+// validateEvent validates the event against its CDEvents schema and the semantic rules unless --no-validate is set,
+// then evaluates the policy set by --policy, if any
+func validateEvent(cmd *cobra.Command, event api.CDEvent) error {
+	if flag := cmd.Flag("no-validate"); flag == nil || flag.Value.String() != "true" {
+		if err := checkEvent(cmd, event); err != nil {
+			return err
+		}
+	}
+
+	p, err := loadPolicy(cmd)
+	if err != nil || p == nil {
+		return err
+	}
+	eventMap, err := output.EventToMap(event)
+	if err != nil {
+		return err
+	}
+	return reportPolicyViolations(cmd, p.Evaluate(eventMap))
+}
+
+// checkEvent validates the event against its CDEvents schema and the semantic rules
+func checkEvent(cmd *cobra.Command, event api.CDEvent) error {
+	validator, err := validation.Default()
+	if err != nil {
+		return fmt.Errorf("failed to load schemas: %w", err)
+	}
+	if err := validator.ValidateEvent(event); err != nil {
+		return fmt.Errorf("invalid event (use --no-validate to skip): %w", err)
+	}
+
+	ruleSet, err := newRuleSet(cmd)
+	if err != nil {
+		return err
+	}
+	violations, err := ruleSet.CheckEvent(event)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		lines := make([]string, 0, len(violations))
+		for _, violation := range violations {
+			lines = append(lines, "  "+violation.String())
+		}
+		return fmt.Errorf("event breaks semantic rules (use --disable-rule or --no-validate to skip):\n%s", strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// newRuleSet creates the semantic rule set with the rules named by --disable-rule disabled
+func newRuleSet(cmd *cobra.Command) (*events.RuleSet, error) {
+	ruleSet := events.NewRuleSet()
+	if flag := cmd.Flag("disable-rule"); flag != nil {
+		disabled, err := cmd.Flags().GetStringSlice("disable-rule")
+		if err != nil {
+			return nil, err
+		}
+		if err := ruleSet.Disable(disabled...); err != nil {
+			return nil, err
+		}
+	}
+	return ruleSet, nil
+}
+
+// loadPolicy loads the policy file set by --policy or the policy configuration key, or returns nil when none is set
+func loadPolicy(cmd *cobra.Command) (*policy.Policy, error) {
+	policyFile := viper.GetString("policy")
+	if flag := cmd.Flag("policy"); flag != nil && flag.Changed {
+		policyFile = flag.Value.String()
+	}
+	if policyFile == "" {
+		return nil, nil
+	}
+	return policy.Load(policyFile)
+}
+
+// reportPolicyViolations writes policy warnings to stderr and returns an error for policy errors
+func reportPolicyViolations(cmd *cobra.Command, violations []policy.Violation) error {
+	var lines []string
+	for _, violation := range violations {
+		if violation.Severity == policy.SeverityWarning {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", violation.String())
+			continue
+		}
+		lines = append(lines, "  "+violation.String())
+	}
+	if len(lines) > 0 {
+		return fmt.Errorf("event breaks policy:\n%s", strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// writeOutput writes formatted output to the command's output file or writer
+func writeOutput(cmd *cobra.Command, formatted, format string) error {
+	outputFile := "-"
+	if flag := cmd.Flag("output-file"); flag != nil {
+		outputFile = flag.Value.String()
+	}
+	if outputFile == "" || outputFile == "-" {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), formatted)
+		return err
+	}
+
+	// Keep text records line separated so appended files stay readable
+	if !output.IsBinaryFormat(format) && !strings.HasSuffix(formatted, "\n") {
+		formatted += "\n"
+	}
+
+	appendMode := false
+	if flag := cmd.Flag("append"); flag != nil {
+		appendMode = flag.Value.String() == "true"
+	}
+	if err := output.WriteFile(outputFile, []byte(formatted), appendMode); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// httpRenderOptions builds the HTTP rendering options from the configuration
+func httpRenderOptions(structured bool) output.HTTPRenderOptions {
+	return output.HTTPRenderOptions{
+		Target:     viper.GetString("http-target"),
+		Headers:    parseHeaders(viper.GetStringSlice("http-headers")),
+		Structured: structured,
+	}
+}
+
+// parseHeaders parses headers in key=value format, ignoring malformed entries
+func parseHeaders(headers []string) map[string]string {
+	parsed := make(map[string]string, len(headers))
+	for _, header := range headers {
+		key, value, ok := strings.Cut(header, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		parsed[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return parsed
+}
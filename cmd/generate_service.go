@@ -12,8 +12,15 @@ var generateServiceCmd = &cobra.Command{
 	Short: "Generate service events",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		factory := events.NewEventFactory(getDefaultSource())
+		factory, err := newEventFactory(cmd)
+		if err != nil {
+			return err
+		}
 		eventType := args[0]
+		opts, err := contextOptions(cmd, factory)
+		if err != nil {
+			return err
+		}
 
 		// Parse custom data
 		customData, err := parseCustomData(cmd)
@@ -28,18 +35,20 @@ var generateServiceCmd = &cobra.Command{
 			cmd.Flag("environment").Value.String(),
 			cmd.Flag("url").Value.String(),
 			customData,
+			append(opts, events.WithArtifactID(cmd.Flag("artifact-id").Value.String()))...,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create service event: %w", err)
 		}
 
 		format := cmd.Flag("output").Value.String()
-		return outputEvent(event, format)
+		return outputEvent(cmd, event, format)
 	},
 }
 
 func init() {
 	addCommonGenerateFlags(generateServiceCmd)
 	generateServiceCmd.Flags().StringP("environment", "e", "", "Environment ID")
+	generateServiceCmd.Flags().String("artifact-id", "", "Artifact ID (PURL) of the deployed service (deployed, rolledback, upgraded)")
 	generateCmd.AddCommand(generateServiceCmd)
 }
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/brunseba/cdevents-tools/pkg/events"
 	"github.com/spf13/cobra"
 )
 
@@ -12,8 +11,15 @@ var generateTaskCmd = &cobra.Command{
 	Short: "Generate task events",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		factory := events.NewEventFactory(getDefaultSource())
+		factory, err := newEventFactory(cmd)
+		if err != nil {
+			return err
+		}
 		eventType := args[0]
+		opts, err := contextOptions(cmd, factory)
+		if err != nil {
+			return err
+		}
 
 		// Parse custom data
 		customData, err := parseCustomData(cmd)
@@ -30,13 +36,14 @@ var generateTaskCmd = &cobra.Command{
 			cmd.Flag("errors").Value.String(),
 			cmd.Flag("url").Value.String(),
 			customData,
+			opts...,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create task event: %w", err)
 		}
 
 		format := cmd.Flag("output").Value.String()
-		return outputEvent(event, format)
+		return outputEvent(cmd, event, format)
 	},
 }
 
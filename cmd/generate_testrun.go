@@ -12,8 +12,15 @@ var generateTestCmd = &cobra.Command{
 	Short: "Generate test events",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		factory := events.NewEventFactory(getDefaultSource())
+		factory, err := newEventFactory(cmd)
+		if err != nil {
+			return err
+		}
 		eventType := args[0]
+		opts, err := contextOptions(cmd, factory)
+		if err != nil {
+			return err
+		}
 
 		// Parse custom data
 		customData, err := parseCustomData(cmd)
@@ -29,17 +36,24 @@ var generateTestCmd = &cobra.Command{
 			cmd.Flag("errors").Value.String(),
 			cmd.Flag("url").Value.String(),
 			customData,
+			append(opts,
+				events.WithEnvironment(cmd.Flag("environment").Value.String()),
+				events.WithTestOutput(cmd.Flag("output-type").Value.String(), cmd.Flag("output-format").Value.String()),
+			)...,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create test event: %w", err)
 		}
 
 		format := cmd.Flag("output").Value.String()
-		return outputEvent(event, format)
+		return outputEvent(cmd, event, format)
 	},
 }
 
 func init() {
 	addCommonGenerateFlags(generateTestCmd)
+	generateTestCmd.Flags().StringP("environment", "e", "", "Environment ID the tests run in")
+	generateTestCmd.Flags().String("output-type", "", "Type of the published test output (report, video, image, log, other)")
+	generateTestCmd.Flags().String("output-format", "", "Media type of the published test output, e.g. application/xml")
 	generateCmd.AddCommand(generateTestCmd)
 }
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/testresults"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import test reports and streams as CDEvents",
+	Long: `Import test reports and test streams as test suite and test case events, so
+test results can be reported without one CLI call per test case.
+
+Every suite becomes a testsuite started and finished event, and every case a
+testcase started and finished event, or a testcase skipped event. Timestamps and
+durations come from the report or stream; reports without timestamps are assumed
+to have finished at import time. Failure, error and skip messages are set as reasons.
+
+Events are printed in the --output format, or sent to --target with --send.`,
+}
+
+var importJUnitCmd = &cobra.Command{
+	Use:   "junit [flags] report.xml...",
+	Short: "Import JUnit XML reports",
+	Long: `Import JUnit XML reports, with a testsuites or testsuite root element.
+
+Failed and errored test cases have the fail and error outcomes, suites have the
+error outcome when a case errored, fail when a case failed and pass otherwise.
+
+Examples:
+  # Print the events of a report
+  cdevents-cli import junit target/surefire-reports/*.xml --environment ci
+
+  # Send them
+  cdevents-cli import junit results.xml --environment staging --send --target http://localhost:8080/events`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		emitter, err := newTestEventEmitter(cmd)
+		if err != nil {
+			return err
+		}
+		for _, input := range args {
+			suites, err := readJUnitReport(cmd, input)
+			if err != nil {
+				return err
+			}
+			for i := range suites {
+				// Reports without timestamp are written once their suite finished
+				if suites[i].Started.IsZero() {
+					suites[i].EndAt(time.Now())
+				}
+				if err := testresults.Replay(&suites[i], emitter); err != nil {
+					return err
+				}
+			}
+		}
+		return emitter.close()
+	},
+}
+
+var importGoTestCmd = &cobra.Command{
+	Use:   "gotest [flags] [file]",
+	Short: "Import go test -json streams",
+	Long: `Import a go test -json stream, read from stdin unless a file is given.
+
+Packages are imported as suites and tests, including subtests, as cases. Events
+are emitted while the stream is read, so they can be sent live while tests run.
+Packages without tests that ran are left out, unless they failed, e.g. to build;
+failed and skipped tests carry their last output lines as reason.
+
+Examples:
+  # Send the events of the tests while they run
+  go test -json ./... | cdevents-cli import gotest --environment ci --send --target http://localhost:8080/events
+
+  # Print the events of a recorded stream
+  cdevents-cli import gotest test-results.json --environment ci`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importStream(cmd, args, func(r io.Reader, h testresults.Handler) error {
+			return testresults.ReadGoTest(r, h)
+		})
+	},
+}
+
+var importTAPCmd = &cobra.Command{
+	Use:   "tap [flags] [file]",
+	Short: "Import TAP streams",
+	Long: `Import a TAP (Test Anything Protocol) stream, read from stdin unless a file is given.
+
+The stream is imported as a suite named --suite and its test points as cases.
+Events are emitted while the stream is read; TAP has no timings, so cases are
+timed from the previous test point. SKIP and failed TODO tests are skipped cases,
+failed tests carry the message of their YAML block as reason, and a bail out or
+missing test points of the plan make the suite error.
+
+Examples:
+  # Send the events of a prove run
+  prove -v t/ | cdevents-cli import tap --suite unit --environment ci --send --target http://localhost:8080/events`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := cmd.Flag("suite").Value.String()
+		return importStream(cmd, args, func(r io.Reader, h testresults.Handler) error {
+			return testresults.ReadTAP(r, name, h)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importJUnitCmd)
+	importCmd.AddCommand(importGoTestCmd)
+	importCmd.AddCommand(importTAPCmd)
+
+	importTAPCmd.Flags().String("suite", "tap", "Name of the test suite the stream is imported as")
+
+	importCmd.PersistentFlags().StringP("environment", "e", "", "Environment ID the tests ran in (required)")
+	importCmd.PersistentFlags().StringP("source", "s", "", "Event source (defaults to hostname)")
+	importCmd.PersistentFlags().String("subject-source", "", "Source of the event subjects (defaults to the event source)")
+	importCmd.PersistentFlags().String("chain-id", "", "Chain ID correlating the events of a delivery flow (spec version 0.4 or later)")
+	importCmd.PersistentFlags().String("chain-id-env", events.DefaultChainIDEnv, "Environment variable the chain ID is read from when --chain-id is not set (empty to disable)")
+	importCmd.MarkPersistentFlagRequired("environment")
+
+	addEmitterFlags(importCmd)
+}
+
+// readJUnitReport parses a JUnit report file, - for stdin
+func readJUnitReport(cmd *cobra.Command, input string) ([]testresults.Suite, error) {
+	var r io.Reader = cmd.InOrStdin()
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open report: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	suites, err := testresults.ParseJUnit(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", input, err)
+	}
+	return suites, nil
+}
+
+// importStream reads a test stream from stdin, or the file of args, passing it to an emitter
+func importStream(cmd *cobra.Command, args []string, read func(io.Reader, testresults.Handler) error) error {
+	var r io.Reader = cmd.InOrStdin()
+	if len(args) == 1 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open test stream: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	emitter, err := newTestEventEmitter(cmd)
+	if err != nil {
+		return err
+	}
+	if err := read(r, emitter); err != nil {
+		return err
+	}
+	return emitter.close()
+}
+
+// testEventEmitter is a testresults.Handler creating the events of test suite and test case runs, printing or
+// sending them as they are created.
+// Case events are linked to the started event of their suite or case, when the spec version supports links.
+type testEventEmitter struct {
+	*eventEmitter
+	factory     *events.EventFactory
+	opts        []events.EventOption
+	environment string
+	// startedIDs holds the IDs of the started events of suites and cases, by suite and case ID
+	startedIDs map[string]string
+}
+
+// newTestEventEmitter creates an emitter printing events, or sending them to --target with --send
+func newTestEventEmitter(cmd *cobra.Command) (*testEventEmitter, error) {
+	factory, err := newEventFactory(cmd)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := contextOptions(cmd, factory)
+	if err != nil {
+		return nil, err
+	}
+	emitter, err := newEventEmitter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return &testEventEmitter{
+		eventEmitter: emitter,
+		factory:      factory,
+		opts:         opts,
+		environment:  cmd.Flag("environment").Value.String(),
+		startedIDs:   map[string]string{},
+	}, nil
+}
+
+// SuiteStarted emits the testsuite started event of a suite
+func (e *testEventEmitter) SuiteStarted(suite *testresults.Suite) error {
+	event, err := e.create("testsuite-started", suite.ID, suite.Name, "", "", suite.Started, "")
+	if err != nil {
+		return err
+	}
+	e.startedIDs[suite.ID] = event.GetId()
+	return e.emit(event)
+}
+
+// CaseStarted emits the testcase started event of a case
+func (e *testEventEmitter) CaseStarted(suite *testresults.Suite, testCase testresults.Case) error {
+	event, err := e.create("testcase-started", testCase.ID, testCase.Name, "", "", testCase.Started, e.startedIDs[suite.ID])
+	if err != nil {
+		return err
+	}
+	e.startedIDs[suite.ID+"/"+testCase.ID] = event.GetId()
+	return e.emit(event)
+}
+
+// CaseFinished emits the testcase finished event of a case, or its testcase skipped event
+func (e *testEventEmitter) CaseFinished(suite *testresults.Suite, testCase testresults.Case) error {
+	key := suite.ID + "/" + testCase.ID
+	eventType, outcome, linkFrom := "testcase-finished", testCase.Outcome, e.startedIDs[key]
+	if testCase.Outcome == testresults.OutcomeSkip {
+		eventType, outcome, linkFrom = "testcase-skipped", "", e.startedIDs[suite.ID]
+	}
+	delete(e.startedIDs, key)
+	event, err := e.create(eventType, testCase.ID, testCase.Name, outcome, testCase.Message, testCase.Started.Add(testCase.Duration), linkFrom)
+	if err != nil {
+		return err
+	}
+	return e.emit(event)
+}
+
+// SuiteFinished emits the testsuite finished event of a suite, with the outcome of its cases.
+// Passed suites have no reason, as the outcome-errors rule requires.
+func (e *testEventEmitter) SuiteFinished(suite *testresults.Suite) error {
+	outcome, reason := suite.Outcome(), ""
+	if outcome != testresults.OutcomePass {
+		reason = suite.Summary()
+	}
+	event, err := e.create("testsuite-finished", suite.ID, suite.Name, outcome, reason, suite.Finished(), e.startedIDs[suite.ID])
+	if err != nil {
+		return err
+	}
+	delete(e.startedIDs, suite.ID)
+	return e.emit(event)
+}
+
+// create creates a test event of the environment that happened at timestamp, linked from the linkFrom event if set
+func (e *testEventEmitter) create(eventType, id, name, outcome, reason string, timestamp time.Time, linkFrom string) (api.CDEvent, error) {
+	opts := append([]events.EventOption{}, e.opts...)
+	opts = append(opts, events.WithEnvironment(e.environment), events.WithTimestamp(timestamp))
+	if linkFrom != "" && e.factory.SupportsLinks() {
+		opts = append(opts, events.WithPathLink(linkFrom))
+	}
+	event, err := e.factory.CreateTestEvent(eventType, id, name, outcome, reason, "", nil, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s event of %s: %w", eventType, id, err)
+	}
+	return event, nil
+}
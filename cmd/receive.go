@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var receiveCmd = &cobra.Command{
+	Use:     "receive",
+	Aliases: []string{"listen"},
+	Short:   "Receive CDEvents with a local CloudEvents sink",
+	Long: `Start a local HTTP server receiving CDEvents, to test producers and the send
+command without an external sink.
+
+The server accepts binary, structured and batch CloudEvents carrying CDEvents,
+and events sent with send --encoding protobuf or avro.
+Received events are validated like generated events, unless --no-validate is set,
+and printed in any output format or appended to --output-file. Invalid events are
+rejected with 400 Bad Request.
+
+With --github-path or --gitlab-path, GitHub or GitLab webhooks are accepted on
+that path as well and translated into CDEvents as by translate. Their
+X-Hub-Signature-256 signature is verified with the secret read from
+--github-secret-env, their X-Gitlab-Token with the token read from
+--gitlab-token-env. Without a secret, receive refuses to start, unless
+--insecure-accept-unverified is set.
+
+The server runs until interrupted, or until --count events have been received.
+
+Examples:
+  # Print received events as YAML
+  cdevents-cli receive --address localhost:8080 --output yaml
+
+  # In another terminal
+  cdevents-cli send --target http://localhost:8080/ pipeline started --id "pipeline-123" --name "my-pipeline"
+
+  # Record the next 10 events as newline delimited JSON
+  cdevents-cli receive --count 10 --output-file events.ndjson
+
+  # Print the events translated from GitHub webhooks
+  GITHUB_WEBHOOK_SECRET=... cdevents-cli receive --address :8080 --github-path /github`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address := cmd.Flag("address").Value.String()
+		path := cmd.Flag("path").Value.String()
+		count, _ := cmd.Flags().GetInt("count")
+		format := cmd.Flag("output").Value.String()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// The receiver passes one event at a time, so the handler needs no locking
+		received := 0
+		receiver := transport.NewReceiver(func(_ context.Context, event api.CDEvent) error {
+			if err := outputEvent(cmd, event, format); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Rejected event %s: %v\n", event.GetId(), err)
+				return err
+			}
+			// Keep events printed to stdout line separated, as files are
+			if outputFile := cmd.Flag("output-file").Value.String(); (outputFile == "" || outputFile == "-") && !output.IsBinaryFormat(format) {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+			received++
+			if count > 0 && received >= count {
+				cancel()
+			}
+			return nil
+		})
+		routes, err := webhookRoutes(cmd, receiver)
+		if err != nil {
+			return err
+		}
+		routes = append([]route{{path: path, name: "CDEvents", handler: receiver}}, routes...)
+		if err := serveEvents(ctx, cmd, address, routes); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Received %d events\n", received)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(receiveCmd)
+
+	receiveCmd.Flags().String("address", "localhost:8080", "Address to listen on, e.g. :8080 for all interfaces")
+	receiveCmd.Flags().String("path", "/", "HTTP path events are accepted on")
+	receiveCmd.Flags().Int("count", 0, "Stop after receiving this many events (0 to run until interrupted)")
+	addWebhookFlags(receiveCmd)
+
+	// Output destination flags
+	receiveCmd.Flags().String("output-file", "-", "Write received events to file instead of stdout (- for stdout)")
+	receiveCmd.Flags().Bool("append", true, "Append received events to the output file instead of replacing it with each event")
+
+	// Schema validation flag
+	receiveCmd.Flags().Bool("no-validate", false, "Accept events without validating them against the CDEvents schemas and semantic rules")
+	receiveCmd.Flags().StringSlice("disable-rule", []string{}, "Semantic rules to skip (finished-outcome, outcome-errors, taskrun-pipelinerun, timestamp-not-in-future)")
+	receiveCmd.Flags().String("policy", "", "Policy file with organisation conventions events must follow")
+}
+
+// serveEvents serves routes, such as a receiver, on an address until ctx is done or the process is interrupted
+func serveEvents(ctx context.Context, cmd *cobra.Command, address string, routes []route) error {
+	mux := http.NewServeMux()
+	paths := map[string]bool{}
+	for _, r := range routes {
+		if paths[r.path] {
+			return fmt.Errorf("path %s is served twice, %s need a path of their own", r.path, r.name)
+		}
+		paths[r.path] = true
+		mux.Handle(r.path, r.handler)
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	// Usage is not helpful once the server has started
+	cmd.SilenceUsage = true
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	for _, r := range routes {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Receiving %s on http://%s%s\n", r.name, listener.Addr(), r.path)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var relayCmd = &cobra.Command{
+	Use:   "relay",
+	Short: "Relay CDEvents from one transport to others",
+	Long: `Receive CloudEvents carrying CDEvents and forward them to one or more targets,
+e.g. at network boundaries where CI runners can only reach HTTP.
+
+Events are received over HTTP as binary, structured or batch CloudEvents, or in
+the protobuf and avro encodings of send, and forwarded to every --to target with
+the retry logic of send. HTTP targets get the --headers and --encoding of the
+relay, file:// targets are appended one JSON event per line, and console prints
+events to stdout. Events can be filtered by type and enriched with a source and
+custom data fields before they are forwarded. Events that can't be delivered are
+answered with 502 Bad Gateway, so producers can retry them.
+
+With --github-path or --gitlab-path, GitHub or GitLab webhooks are translated
+into CDEvents as by translate and relayed like received events, bridging tools
+without CDEvents support into the event bus.
+
+Examples:
+  # Forward pipeline events received on port 8080 to an HTTP sink and a file
+  cdevents-cli relay --from http://:8080 --to https://events.example.com/webhook \
+    --to file://events.ndjson --type 'dev.cdevents.pipelinerun.*'
+
+  # Tag events relayed out of a network zone
+  cdevents-cli relay --from http://localhost:8080/events --to https://events.example.com/webhook \
+    --source https://relay.zone-a.example.com --custom relay.zone=a
+
+  # Bridge GitHub webhooks, verified with the secret in $GITHUB_WEBHOOK_SECRET
+  cdevents-cli relay --from http://:8080/events --github-path /github --to https://events.example.com/webhook
+
+  # Bridge a self-hosted GitLab, verified with the secret token in $GITLAB_WEBHOOK_TOKEN
+  cdevents-cli relay --from http://:8080/events --gitlab-path /gitlab --to https://events.example.com/webhook`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, eventPath, err := parseRelaySource(cmd.Flag("from").Value.String())
+		if err != nil {
+			return err
+		}
+		types, _ := cmd.Flags().GetStringSlice("type")
+		for _, pattern := range types {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid --type pattern %q: %w", pattern, err)
+			}
+		}
+		source := cmd.Flag("source").Value.String()
+		fields, _ := cmd.Flags().GetStringArray("custom")
+		for _, field := range fields {
+			if _, _, ok := strings.Cut(field, "="); !ok {
+				return fmt.Errorf("invalid --custom %q: expected key=value", field)
+			}
+		}
+		retries, _ := cmd.Flags().GetInt("retries")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		count, _ := cmd.Flags().GetInt("count")
+
+		targets, _ := cmd.Flags().GetStringArray("to")
+		headers, _ := cmd.Flags().GetStringSlice("headers")
+		options := httpOptions(cmd.Flag("encoding").Value.String(), headers)
+		transports := make([]transport.Transport, 0, len(targets))
+		factory := transport.NewTransportFactory()
+		for _, target := range targets {
+			t, err := factory.CreateTransport(target, options...)
+			if err != nil {
+				return fmt.Errorf("failed to create transport for %s: %w", target, err)
+			}
+			transports = append(transports, t)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// Events are checked one at a time, delivery to the targets runs concurrently, for all events
+		// and targets, so that a slow target does not hold up other producers and targets
+		var mu sync.Mutex
+		relayed, skipped := 0, 0
+		receiver := transport.NewConcurrentReceiver(func(_ context.Context, event api.CDEvent) error {
+			mu.Lock()
+			if !matchesEventType(event, types) {
+				skipped++
+				mu.Unlock()
+				return nil
+			}
+			err := enrichEvent(event, source, fields)
+			if err == nil {
+				err = validateEvent(cmd, event)
+			}
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Rejected event %s: %v\n", event.GetId(), err)
+				mu.Unlock()
+				return err
+			}
+			mu.Unlock()
+
+			failed := make([]string, len(transports))
+			var wg sync.WaitGroup
+			for i, t := range transports {
+				wg.Add(1)
+				go func(i int, t transport.Transport) {
+					defer wg.Done()
+					if err := deliverEvent(t, event, retries, timeout); err != nil {
+						failed[i] = fmt.Sprintf("%s: %v", targets[i], err)
+					}
+				}(i, t)
+			}
+			wg.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			var failures []string
+			for _, failure := range failed {
+				if failure != "" {
+					failures = append(failures, failure)
+				}
+			}
+			if len(failures) > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to relay event %s:\n  %s\n", event.GetId(), strings.Join(failures, "\n  "))
+				return fmt.Errorf("%w to %s", transport.ErrNotDelivered, strings.Join(failures, "; "))
+			}
+			relayed++
+			if count > 0 && relayed >= count {
+				cancel()
+			}
+			return nil
+		})
+		routes, err := webhookRoutes(cmd, receiver)
+		if err != nil {
+			return err
+		}
+		routes = append([]route{{path: eventPath, name: "CDEvents", handler: receiver}}, routes...)
+		if err := serveEvents(ctx, cmd, address, routes); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Relayed %d events, skipped %d\n", relayed, skipped)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(relayCmd)
+
+	relayCmd.Flags().String("from", "", "Source to receive events from, e.g. http://:8080 or http://localhost:8080/events (required)")
+	relayCmd.Flags().StringArray("to", []string{}, "Target to forward events to, as for send --target (required, repeatable)")
+	relayCmd.Flags().StringSlice("type", []string{}, "Only relay events whose type matches one of these glob patterns, e.g. dev.cdevents.pipelinerun.*")
+	relayCmd.Flags().String("source", "", "Replace the source of relayed events")
+	relayCmd.Flags().StringArray("custom", []string{}, "Custom data field set on relayed events as key=value, dotted keys create nested objects (repeatable)")
+	relayCmd.Flags().IntP("retries", "r", 3, "Number of retry attempts per target")
+	relayCmd.Flags().Duration("timeout", 30*time.Second, "Request timeout per target")
+	relayCmd.Flags().StringSliceP("headers", "H", []string{}, "HTTP headers added to requests to HTTP targets (format: key=value)")
+	relayCmd.Flags().String("encoding", transport.EncodingBinary, "Encoding of events forwarded to HTTP targets (binary, protobuf, avro)")
+	relayCmd.Flags().Int("count", 0, "Stop after relaying this many events (0 to run until interrupted)")
+	addWebhookFlags(relayCmd)
+	relayCmd.MarkFlagRequired("from")
+	relayCmd.MarkFlagRequired("to")
+
+	// Schema validation flag
+	relayCmd.Flags().Bool("no-validate", false, "Relay events without validating them against the CDEvents schemas and semantic rules")
+	relayCmd.Flags().StringSlice("disable-rule", []string{}, "Semantic rules to skip (finished-outcome, outcome-errors, taskrun-pipelinerun, timestamp-not-in-future)")
+	relayCmd.Flags().String("policy", "", "Policy file with organisation conventions relayed events must follow")
+}
+
+// parseRelaySource returns the listen address and path of a relay source such as http://:8080/events
+func parseRelaySource(source string) (string, string, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid --from %q: %w", source, err)
+	}
+	if u.Scheme != "http" {
+		return "", "", fmt.Errorf("unsupported relay source %q: only http:// sources are supported", source)
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("invalid --from %q: expected an address such as http://:8080", source)
+	}
+	eventPath := u.Path
+	if eventPath == "" {
+		eventPath = "/"
+	}
+	return u.Host, eventPath, nil
+}
+
+// matchesEventType reports whether the type of an event matches one of the glob patterns, any type without patterns
+func matchesEventType(event api.CDEvent, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	eventType := event.GetType().String()
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, eventType); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// enrichEvent replaces the source of an event, if set, and sets key=value custom data fields on it
+func enrichEvent(event api.CDEvent, source string, fields []string) error {
+	if source != "" {
+		event.SetSource(source)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	customData, err := events.EventCustomData(event)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		if err := customData.SetField(strings.TrimSpace(key), value); err != nil {
+			return err
+		}
+	}
+	return events.SetEventCustomData(event, customData)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -25,7 +26,8 @@ This tool supports:
 - Sending events via HTTP, Kafka, or other transports
 - Loading event templates from YAML files
 - Integration with CI/CD systems`,
-	Version: "0.1.0",
+	Version:           "0.1.0",
+	PersistentPreRunE: applyCIDefaults,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -33,17 +35,36 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// SetOut sets the writer commands print their output to; nil restores os.Stdout.
+func SetOut(w io.Writer) {
+	rootCmd.SetOut(w)
+}
+
+// SetErr sets the writer commands print warnings and errors to; nil restores os.Stderr.
+func SetErr(w io.Writer) {
+	rootCmd.SetErr(w)
+}
+
+// SetIn sets the reader commands read their input from; nil restores os.Stdin.
+func SetIn(r io.Reader) {
+	rootCmd.SetIn(r)
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.cdevents-cli.yaml)")
-	rootCmd.PersistentFlags().StringP("output", "o", "json", "output format (json, yaml, cloudevent)")
+	rootCmd.PersistentFlags().StringP("output", "o", "json", "output format (json, canonical-json, yaml, cloudevent, http, http-structured, curl, protobuf, avro, github, gitlab-dotenv, shell)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().String("spec-version", "0.4", "CDEvents spec version of generated and sent events (0.3, 0.4, 0.5)")
+	rootCmd.PersistentFlags().Bool("from-ci", false, "Default the subject ID, name, URL, pipeline and source of events from the CI environment")
 	
 	// Bind flags to viper
 	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("spec-version", rootCmd.PersistentFlags().Lookup("spec-version"))
+	viper.BindPFlag("from-ci", rootCmd.PersistentFlags().Lookup("from-ci"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -26,7 +26,30 @@ Examples:
   cdevents-cli send --target console build finished --id "build-456" --name "my-build" --outcome "success"
   
   # Send a service deployed event to a file
-  cdevents-cli send --target file://events.json service deployed --id "service-789" --name "my-service"`,
+  cdevents-cli send --target file://events.json service deployed --id "service-789" --name "my-service"
+
+  # Send the event described by a template
+  cdevents-cli send --target http://localhost:8080/events --from-template deploy.yaml --set subject.id=my-service
+
+  # Send the events of a file, 8 at a time
+  cdevents-cli send --target http://localhost:8080/events --from-file events.ndjson --concurrency 8`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if input := cmd.Flag("from-file").Value.String(); input != "" {
+			if cmd.Flag("from-template").Value.String() != "" {
+				return fmt.Errorf("--from-file and --from-template are mutually exclusive")
+			}
+			return sendBatch(cmd, input)
+		}
+		if cmd.Flag("from-template").Value.String() == "" {
+			return cmd.Help()
+		}
+		event, err := eventFromTemplate(cmd)
+		if err != nil {
+			return err
+		}
+		return sendEvent(cmd, event, viper.GetString("target"), viper.GetInt("retries"), viper.GetDuration("timeout"))
+	},
 }
 
 func init() {
@@ -37,35 +60,46 @@ func init() {
 	sendCmd.PersistentFlags().IntP("retries", "r", 3, "Number of retry attempts")
 	sendCmd.PersistentFlags().DurationP("timeout", "", 30*time.Second, "Request timeout")
 	sendCmd.PersistentFlags().StringSliceP("headers", "H", []string{}, "HTTP headers (format: key=value)")
+	sendCmd.PersistentFlags().String("encoding", transport.EncodingBinary, "Encoding of events sent to HTTP targets (binary, protobuf, avro)")
 	
 	// Bind flags to viper
 	viper.BindPFlag("target", sendCmd.PersistentFlags().Lookup("target"))
 	viper.BindPFlag("retries", sendCmd.PersistentFlags().Lookup("retries"))
 	viper.BindPFlag("timeout", sendCmd.PersistentFlags().Lookup("timeout"))
 	viper.BindPFlag("headers", sendCmd.PersistentFlags().Lookup("headers"))
+	viper.BindPFlag("encoding", sendCmd.PersistentFlags().Lookup("encoding"))
+
+	// Schema validation flag
+	sendCmd.PersistentFlags().Bool("no-validate", false, "Skip validating events against the CDEvents schemas and semantic rules")
+	sendCmd.PersistentFlags().StringSlice("disable-rule", []string{}, "Semantic rules to skip (finished-outcome, outcome-errors, taskrun-pipelinerun, timestamp-not-in-future)")
+	sendCmd.PersistentFlags().String("policy", "", "Policy file with organisation conventions events must follow")
+
+	addContextFlags(sendCmd)
+	addTemplateFlags(sendCmd)
+
+	// Batch flags
+	sendCmd.Flags().String("from-file", "", "Send the events of a file (- for stdin) in any format accepted by validate, e.g. newline delimited JSON")
+	sendCmd.Flags().Int("concurrency", 4, "Number of events of --from-file sent at a time, events of the same subject are sent in order")
+	sendCmd.Flags().Int("progress-every", 1000, "Report progress every N events of --from-file (0 to disable)")
 }
 
 // sendEvent sends an event using the specified transport
-func sendEvent(event interface{}, target string, retries int, timeout time.Duration) error {
+func sendEvent(cmd *cobra.Command, event interface{}, target string, retries int, timeout time.Duration) error {
 	cdEvent, ok := event.(api.CDEvent)
 	if !ok {
 		return fmt.Errorf("invalid event type")
 	}
+	if err := validateEvent(cmd, cdEvent); err != nil {
+		return err
+	}
 
 	factory := transport.NewTransportFactory()
-	transport, err := factory.CreateTransport(target)
+	transport, err := factory.CreateTransport(target, transportOptions()...)
 	if err != nil {
 		return fmt.Errorf("failed to create transport: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	if retries > 0 {
-	return SendEventWithRetry(ctx, transport, cdEvent, retries)
-	}
-
-	return transport.Send(ctx, cdEvent)
+	return deliverEvent(transport, cdEvent, retries, timeout)
 }
 
 // SendEventWithRetry sends an event with retry logic
@@ -86,3 +120,16 @@ func SendEventWithRetry(ctx context.Context, transport transport.Transport, even
 
 	return fmt.Errorf("failed to send event after %d retries: %w", maxRetries, lastErr)
 }
+
+// transportOptions returns the options of the transports of send
+func transportOptions() []transport.HTTPOption {
+	return httpOptions(viper.GetString("encoding"), viper.GetStringSlice("headers"))
+}
+
+// httpOptions returns the options of HTTP transports sending events in the encoding with the key=value headers
+func httpOptions(encoding string, headers []string) []transport.HTTPOption {
+	return []transport.HTTPOption{
+		transport.WithEncoding(encoding),
+		transport.WithHTTPHeaders(parseHeaders(headers)),
+	}
+}
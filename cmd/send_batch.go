@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// batchEvent is an event read by send --from-file, named after its position in the input
+type batchEvent struct {
+	name  string
+	event api.CDEvent
+}
+
+// batchProgress counts the events of a batch, reporting progress as events complete
+type batchProgress struct {
+	mu     sync.Mutex
+	cmd    *cobra.Command
+	every  int
+	sent   int
+	failed int
+}
+
+// done records the outcome of an event
+func (p *batchProgress) done(name string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		p.failed++
+		fmt.Fprintf(p.cmd.ErrOrStderr(), "Failed to send %s: %v\n", name, err)
+	} else {
+		p.sent++
+	}
+	if completed := p.sent + p.failed; p.every > 0 && completed%p.every == 0 {
+		fmt.Fprintf(p.cmd.ErrOrStderr(), "Progress: %d events (%d sent, %d failed)\n", completed, p.sent, p.failed)
+	}
+}
+
+// sendBatch sends the events of a file, or of stdin for "-", through the configured transport.
+// Events are sent by --concurrency workers while the input is read, so that long streams are not
+// held in memory; events of the same subject go to the same worker, so they are delivered in input order.
+func sendBatch(cmd *cobra.Command, input string) error {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if concurrency < 1 {
+		return fmt.Errorf("invalid --concurrency %d: must be at least 1", concurrency)
+	}
+	every, _ := cmd.Flags().GetInt("progress-every")
+	target := viper.GetString("target")
+	retries := viper.GetInt("retries")
+	timeout := viper.GetDuration("timeout")
+
+	reader, name, err := openInput(cmd, input)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	t, err := transport.NewTransportFactory().CreateTransport(target, transportOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to create transport: %w", err)
+	}
+	// Usage is not helpful once the input is open
+	cmd.SilenceUsage = true
+
+	progress := &batchProgress{cmd: cmd, every: every}
+	queues := make([]chan batchEvent, concurrency)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan batchEvent, 64)
+		wg.Add(1)
+		go func(queue <-chan batchEvent) {
+			defer wg.Done()
+			for e := range queue {
+				progress.done(e.name, deliverEvent(t, e.event, retries, timeout))
+			}
+		}(queues[i])
+	}
+
+	total := 0
+	readErr := validation.StreamDocuments(name, reader, func(doc validation.Document) error {
+		total++
+		eventMap, _ := doc.CDEvent()
+		if eventMap == nil {
+			progress.done(doc.Name, fmt.Errorf("no CDEvent found"))
+			return nil
+		}
+		event, err := events.DecodeEvent(eventMap)
+		if err != nil {
+			progress.done(doc.Name, err)
+			return nil
+		}
+		if err := validateEvent(cmd, event); err != nil {
+			progress.done(doc.Name, err)
+			return nil
+		}
+		queues[subjectQueue(event, concurrency)] <- batchEvent{name: doc.Name, event: event}
+		return nil
+	})
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Sent %d of %d events to %s, %d failed\n", progress.sent, total, target, progress.failed)
+	if readErr != nil {
+		return fmt.Errorf("stopped reading events: %w", readErr)
+	}
+	if progress.failed > 0 {
+		return fmt.Errorf("%d of %d events failed to send", progress.failed, total)
+	}
+	return nil
+}
+
+// subjectQueue returns the worker queue of an event, the same for all events of a subject
+func subjectQueue(event api.CDEvent, queues int) int {
+	h := fnv.New32a()
+	h.Write([]byte(event.GetSubjectSource() + "\x00" + event.GetSubjectId()))
+	return int(h.Sum32() % uint32(queues))
+}
+
+// deliverEvent sends an event with its own timeout, retrying when retries is positive
+func deliverEvent(t transport.Transport, event api.CDEvent, retries int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if retries > 0 {
+		return SendEventWithRetry(ctx, t, event, retries)
+	}
+	return t.Send(ctx, event)
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/brunseba/cdevents-tools/pkg/events"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -13,8 +12,15 @@ var sendPipelineCmd = &cobra.Command{
 	Short: "Send pipeline events",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		factory := events.NewEventFactory(getDefaultSource())
+		factory, err := newEventFactory(cmd)
+		if err != nil {
+			return err
+		}
 		eventType := args[0]
+		opts, err := contextOptions(cmd, factory)
+		if err != nil {
+			return err
+		}
 
 		customData, err := parseCustomData(cmd)
 		if err != nil {
@@ -29,6 +35,7 @@ var sendPipelineCmd = &cobra.Command{
 			cmd.Flag("errors").Value.String(),
 			cmd.Flag("url").Value.String(),
 			customData,
+			opts...,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create pipeline event: %w", err)
@@ -38,7 +45,7 @@ var sendPipelineCmd = &cobra.Command{
 		retries := viper.GetInt("retries")
 		timeout := viper.GetDuration("timeout")
 
-		return sendEvent(event, target, retries, timeout)
+		return sendEvent(cmd, event, target, retries, timeout)
 	},
 }
 
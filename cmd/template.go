@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brunseba/cdevents-tools/pkg/template"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+// addTemplateFlags adds the event template flags to a command
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from-template", "", "YAML event template describing the event type, subject fields, custom data and links")
+	cmd.Flags().StringArray("set", []string{}, "Override a template field as key=value, e.g. subject.id=my-service (repeatable)")
+	cmd.Flags().StringArray("var", []string{}, "Template variable as name=value, variables default to the environment (repeatable)")
+}
+
+// eventFromTemplate creates the event described by the template set by --from-template.
+// Context flags such as --event-id override the template.
+func eventFromTemplate(cmd *cobra.Command) (api.CDEvent, error) {
+	vars := map[string]string{}
+	values, _ := cmd.Flags().GetStringArray("var")
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --var %q: expected name=value", value)
+		}
+		vars[strings.TrimSpace(name)] = v
+	}
+	lookup := func(name string) (string, bool) {
+		if value, ok := vars[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+	overrides, _ := cmd.Flags().GetStringArray("set")
+
+	t, err := template.Load(cmd.Flag("from-template").Value.String(), lookup, overrides)
+	if err != nil {
+		return nil, err
+	}
+	factory, err := newEventFactory(cmd)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := contextOptions(cmd, factory)
+	if err != nil {
+		return nil, err
+	}
+	event, err := t.Create(factory, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event from template: %w", err)
+	}
+	return event, nil
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/brunseba/cdevents-tools/pkg/webhook"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+var translateCmd = &cobra.Command{
+	Use:   "translate",
+	Short: "Translate webhook payloads of CI/CD tools into CDEvents",
+	Long: `Translate the webhook payloads of tools without native CDEvents support into
+CDEvents, e.g. to replay recorded deliveries or to test a mapping.
+
+Events are printed in the --output format, or sent to --target with --send. To
+translate webhooks as they are delivered, serve them with receive or relay and
+their --github-path and --gitlab-path flags.`,
+}
+
+var translateGitHubCmd = &cobra.Command{
+	Use:   "github [flags] [file]",
+	Short: "Translate GitHub webhook payloads",
+	Long: `Translate a GitHub webhook payload, read from stdin unless a file is given.
+--event is the event name GitHub sends in the X-GitHub-Event header.
+
+Events are sourced from the repository URL and timestamped with the time of the
+change the payload reports:
+  push               branch created and deleted (pushes of commits are ignored)
+  pull_request       change created (opened, reopened), updated (edited,
+                     synchronize, ready_for_review), merged and abandoned (closed)
+  workflow_run       pipelineRun queued (requested), started and finished
+  workflow_job       taskRun started (in_progress) and finished
+  release            artifact published, as pkg:github/owner/repo@tag
+  deployment_status  service deployed (success), with pkg:github/owner/repo@sha
+  issues             ticket created, updated and closed (spec version 0.4 or later)
+Deployment payloads request a deployment and translate into no event, the service
+is reported deployed by the successful deployment_status payload.
+
+With --signature, the payload is verified against the X-Hub-Signature-256 value
+it was delivered with, using the webhook secret read from --github-secret-env.
+
+Examples:
+  # Print the events of a recorded delivery
+  cdevents-cli translate github --event pull_request payload.json
+
+  # Verify and send it
+  cdevents-cli translate github --event workflow_run --signature "sha256=..." payload.json \
+    --send --target http://localhost:8080/events`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payload, err := readPayload(cmd, args)
+		if err != nil {
+			return err
+		}
+		if signature := cmd.Flag("signature").Value.String(); signature != "" {
+			env := cmd.Flag("github-secret-env").Value.String()
+			secret := os.Getenv(env)
+			if secret == "" {
+				return fmt.Errorf("--signature requires the webhook secret in $%s", env)
+			}
+			if err := webhook.VerifyGitHubSignature(secret, signature, payload); err != nil {
+				return err
+			}
+		}
+
+		factory, err := newEventFactory(cmd)
+		if err != nil {
+			return err
+		}
+		eventType := cmd.Flag("event").Value.String()
+		cdEvents, err := webhook.TranslateGitHub(factory, eventType, payload)
+		if err != nil {
+			return err
+		}
+		if len(cdEvents) == 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: GitHub %s payload translates into no events\n", eventType)
+		}
+		return emitTranslated(cmd, cdEvents)
+	},
+}
+
+var translateGitLabCmd = &cobra.Command{
+	Use:   "gitlab [flags] [file]",
+	Short: "Translate GitLab webhook payloads",
+	Long: `Translate a GitLab webhook payload, read from stdin unless a file is given.
+Payloads are translated by their object_kind.
+
+Events are sourced from the project URL and timestamped with the time of the
+change the payload reports:
+  push           branch created and deleted (pushes of commits are ignored)
+  merge_request  change created (open, reopen), updated (update), reviewed
+                 (approved), merged (merge) and abandoned (close)
+  pipeline       pipelineRun queued (pending), started (running) and finished
+  build          taskRun started (running) and finished, of job hooks
+  deployment     service deployed (success), with pkg:gitlab/group/project@sha
+  release        artifact published (create), as pkg:gitlab/group/project@tag
+  issue          ticket created (open), updated (reopen, update) and closed
+                 (spec version 0.4 or later)
+
+With --token, the payload is verified against the X-Gitlab-Token value it was
+delivered with, using the secret token read from --gitlab-token-env.
+
+Examples:
+  # Print the events of a recorded delivery
+  cdevents-cli translate gitlab pipeline-hook.json
+
+  # Send them
+  cdevents-cli translate gitlab merge-request-hook.json --send --target http://localhost:8080/events`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payload, err := readPayload(cmd, args)
+		if err != nil {
+			return err
+		}
+		if token := cmd.Flag("token").Value.String(); token != "" {
+			env := cmd.Flag("gitlab-token-env").Value.String()
+			secret := os.Getenv(env)
+			if secret == "" {
+				return fmt.Errorf("--token requires the webhook secret token in $%s", env)
+			}
+			if err := webhook.VerifyGitLabToken(secret, token); err != nil {
+				return err
+			}
+		}
+
+		factory, err := newEventFactory(cmd)
+		if err != nil {
+			return err
+		}
+		cdEvents, err := webhook.TranslateGitLab(factory, payload)
+		if err != nil {
+			return err
+		}
+		if len(cdEvents) == 0 {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Warning: GitLab payload translates into no events")
+		}
+		return emitTranslated(cmd, cdEvents)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(translateCmd)
+	translateCmd.AddCommand(translateGitHubCmd)
+	translateCmd.AddCommand(translateGitLabCmd)
+
+	translateGitHubCmd.Flags().String("event", "", "GitHub event name of the payload, as in the X-GitHub-Event header (required)")
+	translateGitHubCmd.Flags().String("signature", "", "X-Hub-Signature-256 value to verify the payload against")
+	translateGitHubCmd.Flags().String("github-secret-env", defaultGitHubSecretEnv, "Environment variable the webhook secret is read from")
+	translateGitHubCmd.MarkFlagRequired("event")
+
+	translateGitLabCmd.Flags().String("token", "", "X-Gitlab-Token value to verify the payload against")
+	translateGitLabCmd.Flags().String("gitlab-token-env", defaultGitLabTokenEnv, "Environment variable the webhook secret token is read from")
+
+	addEmitterFlags(translateCmd)
+}
+
+// readPayload reads a webhook payload from stdin, or the file of args
+func readPayload(cmd *cobra.Command, args []string) ([]byte, error) {
+	var r io.Reader = cmd.InOrStdin()
+	if len(args) == 1 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to open payload: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	return payload, nil
+}
+
+// emitTranslated prints translated events, or sends them with --send
+func emitTranslated(cmd *cobra.Command, cdEvents []api.CDEvent) error {
+	emitter, err := newEventEmitter(cmd)
+	if err != nil {
+		return err
+	}
+	for _, event := range cdEvents {
+		if err := emitter.emit(event); err != nil {
+			return err
+		}
+	}
+	return emitter.close()
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brunseba/cdevents-tools/pkg/policy"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file|glob|-]...",
+	Short: "Validate CDEvents against the CDEvents schemas",
+	Long: `Validate CDEvents read from files, globs or stdin against the CDEvents schemas
+and the semantic rules, such as outcomes matching reported errors. With --policy,
+events are also checked against the conventions of a policy file; policy warnings
+are reported without failing validation.
+
+Each input may hold a single JSON or YAML event, newline delimited JSON,
+multi-document YAML, or a JSON array such as the CloudEvents batch format.
+Events may be plain CDEvents or structured CloudEvents carrying a CDEvent,
+in which case the CloudEvents context attributes are checked as well.
+
+The command exits with a non-zero status when any event fails validation.
+
+Examples:
+  # Validate a single event
+  cdevents-cli validate event.json
+
+  # Validate all events in a directory and write a JUnit report
+  cdevents-cli validate 'events/*.json' --report junit > validation.xml
+
+  # Validate events generated by another tool
+  my-producer | cdevents-cli validate -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report := cmd.Flag("report").Value.String()
+		if !isReportFormat(report) {
+			return fmt.Errorf("unsupported report format: %s (supported: %s)", report, strings.Join(validation.ReportFormats, ", "))
+		}
+
+		inputs, err := expandInputs(args)
+		if err != nil {
+			return err
+		}
+		ruleSet, err := newRuleSet(cmd)
+		if err != nil {
+			return err
+		}
+		p, err := loadPolicy(cmd)
+		if err != nil {
+			return err
+		}
+		// Usage is not helpful once the arguments have been accepted
+		cmd.SilenceUsage = true
+
+		validator, err := validation.Default()
+		if err != nil {
+			return fmt.Errorf("failed to load schemas: %w", err)
+		}
+
+		var results []validation.Result
+		for _, input := range inputs {
+			documents, err := readDocuments(cmd, input)
+			if err != nil {
+				results = append(results, validation.Result{
+					Name:   input,
+					Errors: []validation.FieldError{{Message: err.Error()}},
+				})
+				continue
+			}
+			for _, doc := range documents {
+				result := validator.Check(doc)
+				if eventMap, prefix := doc.CDEvent(); eventMap != nil {
+					for _, violation := range ruleSet.Check(eventMap) {
+						result.Valid = false
+						result.Errors = append(result.Errors, validation.FieldError{
+							Path:    prefix + violation.Path,
+							Message: fmt.Sprintf("%s (%s)", violation.Message, violation.Rule),
+						})
+					}
+					if p != nil {
+						addPolicyViolations(&result, prefix, p.Evaluate(eventMap))
+					}
+				}
+				results = append(results, result)
+			}
+		}
+
+		if err := validation.WriteReport(cmd.OutOrStdout(), report, results); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		if failed := validation.Failed(results); failed > 0 {
+			return fmt.Errorf("%d of %d events failed validation", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().String("report", "text", "Report format (text, json, junit)")
+	validateCmd.Flags().StringSlice("disable-rule", []string{}, "Semantic rules to skip (finished-outcome, outcome-errors, taskrun-pipelinerun, timestamp-not-in-future)")
+	validateCmd.Flags().String("policy", "", "Policy file with organisation conventions events must follow")
+}
+
+// addPolicyViolations records policy errors as validation errors and policy warnings as warnings
+func addPolicyViolations(result *validation.Result, prefix string, violations []policy.Violation) {
+	for _, violation := range violations {
+		fieldErr := validation.FieldError{
+			Path:    prefix + violation.Path,
+			Message: fmt.Sprintf("%s (%s)", violation.Message, violation.Rule),
+		}
+		if violation.Severity == policy.SeverityWarning {
+			result.Warnings = append(result.Warnings, fieldErr)
+			continue
+		}
+		result.Valid = false
+		result.Errors = append(result.Errors, fieldErr)
+	}
+}
+
+// isReportFormat reports whether the format is a supported report format
+func isReportFormat(format string) bool {
+	for _, supported := range validation.ReportFormats {
+		if format == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// expandInputs expands glob patterns, defaulting to stdin when no input is given
+func expandInputs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	var inputs []string
+	for _, arg := range args {
+		if arg == "-" || !strings.ContainsAny(arg, "*?[") {
+			inputs = append(inputs, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %s: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", arg)
+		}
+		inputs = append(inputs, matches...)
+	}
+	return inputs, nil
+}
+
+// readDocuments reads the events of a file, or of stdin for "-"
+func readDocuments(cmd *cobra.Command, input string) ([]validation.Document, error) {
+	var data []byte
+	var err error
+	name := input
+	if input == "-" {
+		name = "stdin"
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return validation.DecodeDocuments(name, data)
+}
+
+// openInput opens a file, or stdin for "-", returning the name its events are reported by
+func openInput(cmd *cobra.Command, input string) (io.ReadCloser, string, error) {
+	if input == "-" {
+		return io.NopCloser(cmd.InOrStdin()), "stdin", nil
+	}
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, input, fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	return file, input, nil
+}
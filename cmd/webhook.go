@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/brunseba/cdevents-tools/pkg/webhook"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// defaultGitHubSecretEnv is the environment variable GitHub webhook secrets are read from by default
+	defaultGitHubSecretEnv = "GITHUB_WEBHOOK_SECRET"
+	// defaultGitLabTokenEnv is the environment variable GitLab webhook secret tokens are read from by default
+	defaultGitLabTokenEnv = "GITLAB_WEBHOOK_TOKEN"
+)
+
+// route is an HTTP path served by serveEvents
+type route struct {
+	path    string
+	name    string
+	handler http.Handler
+}
+
+// addWebhookFlags adds the flags serving webhooks translated into CDEvents to a command
+func addWebhookFlags(cmd *cobra.Command) {
+	cmd.Flags().String("github-path", "", "HTTP path GitHub webhooks are accepted and translated on, e.g. /github (empty to disable)")
+	cmd.Flags().String("github-secret-env", defaultGitHubSecretEnv, "Environment variable the GitHub webhook secret is read from")
+	cmd.Flags().String("gitlab-path", "", "HTTP path GitLab webhooks are accepted and translated on, e.g. /gitlab (empty to disable)")
+	cmd.Flags().String("gitlab-token-env", defaultGitLabTokenEnv, "Environment variable the GitLab webhook secret token is read from")
+	cmd.Flags().Bool("insecure-accept-unverified", false, "Accept webhooks unverified when their secret is not set, instead of refusing to start")
+}
+
+// webhookRoutes returns the routes of the webhooks enabled by the flags of addWebhookFlags, passing translated
+// events to the receiver. Webhooks without secret are refused, unless --insecure-accept-unverified is set.
+func webhookRoutes(cmd *cobra.Command, receiver *transport.Receiver) ([]route, error) {
+	githubPath := cmd.Flag("github-path").Value.String()
+	gitlabPath := cmd.Flag("gitlab-path").Value.String()
+	if githubPath == "" && gitlabPath == "" {
+		return nil, nil
+	}
+	factory, err := newEventFactory(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []route
+	if githubPath != "" {
+		secret, err := webhookSecret(cmd, "github-secret-env", "GitHub")
+		if err != nil {
+			return nil, err
+		}
+		handler := webhook.NewGitHubHandler(factory, secret, receiver)
+		if secret == "" {
+			handler = webhook.NewHandler(nil, webhook.GitHubTranslator(factory), receiver)
+		}
+		routes = append(routes, route{path: githubPath, name: "GitHub webhooks", handler: handler})
+	}
+	if gitlabPath != "" {
+		secret, err := webhookSecret(cmd, "gitlab-token-env", "GitLab")
+		if err != nil {
+			return nil, err
+		}
+		handler := webhook.NewGitLabHandler(factory, secret, receiver)
+		if secret == "" {
+			handler = webhook.NewHandler(nil, webhook.GitLabTranslator(factory), receiver)
+		}
+		routes = append(routes, route{path: gitlabPath, name: "GitLab webhooks", handler: handler})
+	}
+	return routes, nil
+}
+
+// webhookSecret reads the webhook secret of a producer from the environment variable named by a flag. When it is
+// not set, it fails unless --insecure-accept-unverified is set, then warning that webhooks are accepted unverified.
+func webhookSecret(cmd *cobra.Command, envFlag, producer string) (string, error) {
+	env := cmd.Flag(envFlag).Value.String()
+	secret := os.Getenv(env)
+	if secret != "" {
+		return secret, nil
+	}
+	if insecure, _ := cmd.Flags().GetBool("insecure-accept-unverified"); !insecure {
+		return "", fmt.Errorf("%s webhooks require a secret in $%s, or --insecure-accept-unverified", producer, env)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Warning: $%s is not set, %s webhooks are accepted unverified\n", env, producer)
+	return "", nil
+}
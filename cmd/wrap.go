@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+// ExitError asks for the process to exit with a status, e.g. the status of a wrapped command
+type ExitError struct {
+	Code int
+}
+
+// Error returns the exit status as message
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// wrapTailBytes bounds the stderr kept for the errors of finished events
+const wrapTailBytes = 64 * 1024
+
+var wrapCmd = &cobra.Command{
+	Use:   "wrap [flags] -- command [args...]",
+	Short: "Run a command and emit started and finished events",
+	Long: `Run a command, emitting a started event before it runs and a finished event
+once it exits, so any CI step can report CDEvents without scripting.
+
+The outcome of the finished event is derived from the exit status: success for 0,
+cancel when the command was interrupted (SIGINT or SIGTERM) and failure otherwise.
+Failed events carry the last lines of the command's stderr as errors, and all
+finished events carry the exit code and duration as custom data. Signals are
+forwarded to the command, and wrap exits with the command's exit status.
+
+Events are sent to --target. Events that can't be sent are reported as warnings,
+without changing the exit status.
+
+Examples:
+  # Report a compile task of a pipeline
+  cdevents-cli wrap --kind task --id build-42 --name compile --pipeline pipeline-7 \
+    --target http://localhost:8080/events -- make build
+
+  # Report a build
+  cdevents-cli wrap --kind build --id "$CI_JOB_ID" --name image --artifact-id "pkg:oci/app@v1.0.0" -- docker build -t app .`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kind := cmd.Flag("kind").Value.String()
+		if kind != "task" && kind != "pipeline" && kind != "build" {
+			return fmt.Errorf("unsupported kind %q (supported: task, pipeline, build)", kind)
+		}
+		tailLines, _ := cmd.Flags().GetInt("stderr-lines")
+		factory, err := newEventFactory(cmd)
+		if err != nil {
+			return err
+		}
+		opts, err := contextOptions(cmd, factory)
+		if err != nil {
+			return err
+		}
+		customData, err := parseCustomData(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to parse custom data: %w", err)
+		}
+		// Usage and errors are not helpful once the command runs, which reports its own
+		cmd.SilenceUsage = true
+
+		started, err := createWrapEvent(cmd, factory, kind, "started", "", "", customData, opts...)
+		if err != nil {
+			return err
+		}
+		emitWrapEvent(cmd, started)
+
+		child := exec.Command(args[0], args[1:]...)
+		child.Stdin = cmd.InOrStdin()
+		child.Stdout = cmd.OutOrStdout()
+		tail := &tailBuffer{max: wrapTailBytes}
+		child.Stderr = &teeWriter{cmd.ErrOrStderr(), tail}
+
+		start := time.Now()
+		code, interrupted, runErr := runForwardingSignals(child)
+		duration := time.Since(start)
+
+		outcome := "success"
+		var errorText string
+		switch {
+		case runErr != nil:
+			outcome = "error"
+			errorText = runErr.Error()
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: failed to run %s: %v\n", args[0], runErr)
+		case code == 0:
+		case interrupted:
+			outcome = "cancel"
+			errorText = tail.lastLines(tailLines)
+		default:
+			outcome = "failure"
+			errorText = tail.lastLines(tailLines)
+		}
+		if outcome != "success" && errorText == "" {
+			errorText = fmt.Sprintf("exit status %d", code)
+		}
+
+		if customData == nil {
+			customData = &events.CustomData{}
+		}
+		for _, field := range [][2]string{
+			{"exitCode", strconv.Itoa(code)},
+			{"durationSeconds", strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)},
+		} {
+			if err := customData.SetField(field[0], field[1]); err != nil {
+				return err
+			}
+		}
+		if factory.SupportsLinks() && started != nil {
+			opts = append(opts, events.WithPathLink(started.GetId()))
+		}
+		finished, err := createWrapEvent(cmd, factory, kind, "finished", outcome, errorText, customData, opts...)
+		if err != nil {
+			return err
+		}
+		emitWrapEvent(cmd, finished)
+
+		if code != 0 {
+			cmd.SilenceErrors = true
+			return &ExitError{Code: code}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(wrapCmd)
+
+	wrapCmd.Flags().String("kind", "task", "Kind of activity the command is (task, pipeline, build)")
+	wrapCmd.Flags().StringP("id", "i", "", "Subject ID (required)")
+	wrapCmd.Flags().StringP("name", "n", "", "Subject name (required)")
+	wrapCmd.Flags().StringP("pipeline", "p", "", "Pipeline ID of task events")
+	wrapCmd.Flags().StringP("source", "s", "", "Event source (defaults to hostname)")
+	wrapCmd.Flags().StringP("url", "u", "", "Subject URL")
+	wrapCmd.Flags().String("artifact-id", "", "Artifact ID (PURL) produced by build commands, required to send build finished events")
+	wrapCmd.Flags().Int("stderr-lines", 20, "Number of trailing stderr lines reported as errors of failed commands")
+	wrapCmd.MarkFlagRequired("id")
+	wrapCmd.MarkFlagRequired("name")
+	addCustomDataFlags(wrapCmd)
+
+	// Transport flags
+	wrapCmd.Flags().StringP("target", "t", "console", "Target to send events to (console, http://..., file://...)")
+	wrapCmd.Flags().IntP("retries", "r", 3, "Number of retry attempts")
+	wrapCmd.Flags().Duration("timeout", 30*time.Second, "Request timeout")
+
+	// Context flags, the finished event is linked to the started event
+	wrapCmd.Flags().String("subject-source", "", "Source of the event subject (defaults to the event source)")
+	wrapCmd.Flags().String("chain-id", "", "Chain ID correlating the events of a delivery flow (spec version 0.4 or later)")
+	wrapCmd.Flags().String("chain-id-env", events.DefaultChainIDEnv, "Environment variable the chain ID is read from when --chain-id is not set (empty to disable)")
+
+	// Schema validation flag
+	wrapCmd.Flags().Bool("no-validate", false, "Skip validating events against the CDEvents schemas and semantic rules")
+	wrapCmd.Flags().StringSlice("disable-rule", []string{}, "Semantic rules to skip (finished-outcome, outcome-errors, taskrun-pipelinerun, timestamp-not-in-future)")
+	wrapCmd.Flags().String("policy", "", "Policy file with organisation conventions events must follow")
+}
+
+// createWrapEvent creates a started or finished event of the wrapped activity
+func createWrapEvent(cmd *cobra.Command, factory *events.EventFactory, kind, eventType, outcome, errorText string, customData *events.CustomData, opts ...events.EventOption) (api.CDEvent, error) {
+	id := cmd.Flag("id").Value.String()
+	name := cmd.Flag("name").Value.String()
+	url := cmd.Flag("url").Value.String()
+
+	var event api.CDEvent
+	var err error
+	switch kind {
+	case "pipeline":
+		event, err = factory.CreatePipelineRunEvent(eventType, id, name, outcome, errorText, url, customData, opts...)
+	case "build":
+		opts = append(opts, events.WithArtifactID(cmd.Flag("artifact-id").Value.String()))
+		event, err = factory.CreateBuildEvent(eventType, id, name, outcome, errorText, url, customData, opts...)
+	default:
+		event, err = factory.CreateTaskRunEvent(eventType, id, name, cmd.Flag("pipeline").Value.String(), outcome, errorText, url, customData, opts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s %s event: %w", kind, eventType, err)
+	}
+	return event, nil
+}
+
+// emitWrapEvent sends an event to --target, reporting failures as warnings so they don't fail the wrapped command
+func emitWrapEvent(cmd *cobra.Command, event api.CDEvent) {
+	retries, _ := cmd.Flags().GetInt("retries")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if err := sendEvent(cmd, event, cmd.Flag("target").Value.String(), retries, timeout); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to send %s event: %v\n", event.GetType(), err)
+	}
+}
+
+// runForwardingSignals runs a command, forwarding the signals the process receives to it.
+// It returns the exit code, 128+signal when the command was killed, and whether it was interrupted.
+func runForwardingSignals(child *exec.Cmd) (int, bool, error) {
+	if err := child.Start(); err != nil {
+		return 127, false, err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	forwarded := make(chan bool)
+	go func() {
+		interrupted := false
+		for {
+			select {
+			case sig := <-signals:
+				interrupted = interrupted || sig == os.Interrupt || sig == syscall.SIGTERM
+				child.Process.Signal(sig)
+			case <-done:
+				forwarded <- interrupted
+				return
+			}
+		}
+	}()
+
+	err := child.Wait()
+	close(done)
+	interrupted := <-forwarded
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return 1, interrupted, err
+	}
+	if exitErr == nil {
+		return 0, interrupted, nil
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		sig := status.Signal()
+		return 128 + int(sig), interrupted || sig == syscall.SIGINT || sig == syscall.SIGTERM, nil
+	}
+	return exitErr.ExitCode(), interrupted, nil
+}
+
+// teeWriter writes to an output and keeps the tail of what was written
+type teeWriter struct {
+	out  io.Writer
+	tail *tailBuffer
+}
+
+// Write writes p to the output and the tail
+func (w *teeWriter) Write(p []byte) (int, error) {
+	w.tail.Write(p)
+	return w.out.Write(p)
+}
+
+// tailBuffer keeps the last bytes written to it
+type tailBuffer struct {
+	max  int
+	data []byte
+}
+
+// Write appends p, dropping the oldest bytes beyond the maximum
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if len(b.data) > b.max {
+		b.data = b.data[len(b.data)-b.max:]
+	}
+	return len(p), nil
+}
+
+// lastLines returns the last n non-empty lines written
+func (b *tailBuffer) lastLines(n int) string {
+	lines := strings.Split(strings.TrimRight(string(b.data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
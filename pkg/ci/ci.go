@@ -0,0 +1,218 @@
+package ci
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// Getenv returns the value of an environment variable, empty when it is not set
+type Getenv func(name string) string
+
+// Environment describes the CI run and job a command runs in
+type Environment struct {
+	// Provider names the CI system, e.g. github-actions
+	Provider string
+	// Source identifies the CI system and project, used as event source
+	Source string
+
+	// RunID, RunName and RunURL describe the pipeline run
+	RunID   string
+	RunName string
+	RunURL  string
+
+	// JobID, JobName and JobURL describe the job or step of the run, a task run
+	JobID   string
+	JobName string
+	JobURL  string
+}
+
+// Providers lists the supported CI systems, in detection order
+var Providers = []string{"github-actions", "gitlab-ci", "jenkins", "tekton", "azure-pipelines", "circleci", "buildkite"}
+
+// detectors detects the supported CI systems, nil when the environment is not theirs
+var detectors = map[string]func(env Getenv) *Environment{
+	"github-actions":  gitHubActions,
+	"gitlab-ci":       gitLabCI,
+	"jenkins":         jenkins,
+	"tekton":          tekton,
+	"azure-pipelines": azurePipelines,
+	"circleci":        circleCI,
+	"buildkite":       buildkite,
+}
+
+// Detect returns the CI environment described by the environment variables, nil outside of a supported CI system
+func Detect(env Getenv) *Environment {
+	for _, provider := range Providers {
+		if e := detectors[provider](env); e != nil {
+			e.Provider = provider
+			return e
+		}
+	}
+	return nil
+}
+
+// gitHubActions describes a GitHub Actions workflow run and job
+func gitHubActions(env Getenv) *Environment {
+	if env("GITHUB_ACTIONS") != "true" {
+		return nil
+	}
+	repository := strings.TrimSuffix(env("GITHUB_SERVER_URL"), "/") + "/" + env("GITHUB_REPOSITORY")
+	runID := env("GITHUB_RUN_ID")
+	runURL := repository + "/actions/runs/" + runID
+	if attempt := env("GITHUB_RUN_ATTEMPT"); attempt != "" && attempt != "1" {
+		runURL += "/attempts/" + attempt
+	}
+	return &Environment{
+		Source:  repository,
+		RunID:   runID,
+		RunName: env("GITHUB_WORKFLOW"),
+		RunURL:  runURL,
+		// Jobs have no ID of their own in the environment, only their key in the workflow
+		JobID:   joinID(runID, env("GITHUB_JOB")),
+		JobName: env("GITHUB_JOB"),
+		JobURL:  runURL,
+	}
+}
+
+// gitLabCI describes a GitLab CI pipeline and job
+func gitLabCI(env Getenv) *Environment {
+	if env("GITLAB_CI") != "true" {
+		return nil
+	}
+	return &Environment{
+		Source:  env("CI_PROJECT_URL"),
+		RunID:   env("CI_PIPELINE_ID"),
+		RunName: cmp.Or(env("CI_PIPELINE_NAME"), env("CI_PROJECT_PATH")),
+		RunURL:  env("CI_PIPELINE_URL"),
+		JobID:   env("CI_JOB_ID"),
+		JobName: env("CI_JOB_NAME"),
+		JobURL:  env("CI_JOB_URL"),
+	}
+}
+
+// jenkins describes a Jenkins build and the stage it runs, if any
+func jenkins(env Getenv) *Environment {
+	if env("JENKINS_URL") == "" {
+		return nil
+	}
+	runID := cmp.Or(env("BUILD_TAG"), joinID(env("JOB_NAME"), env("BUILD_NUMBER")))
+	e := &Environment{
+		Source:  cmp.Or(env("JOB_URL"), env("JENKINS_URL")),
+		RunID:   runID,
+		RunName: env("JOB_NAME"),
+		RunURL:  env("BUILD_URL"),
+		JobID:   runID,
+		JobName: env("JOB_NAME"),
+		JobURL:  env("BUILD_URL"),
+	}
+	if stage := env("STAGE_NAME"); stage != "" {
+		e.JobID = joinID(runID, stage)
+		e.JobName = stage
+	}
+	return e
+}
+
+// tekton describes a Tekton pipeline run and task run. Tekton sets no environment variables,
+// so steps map its context variables, e.g. TEKTON_PIPELINE_RUN to $(context.pipelineRun.name).
+func tekton(env Getenv) *Environment {
+	if env("TEKTON_PIPELINE_RUN") == "" && env("TEKTON_TASK_RUN") == "" {
+		return nil
+	}
+	namespace := cmp.Or(env("TEKTON_NAMESPACE"), "default")
+	dashboard := strings.TrimSuffix(env("TEKTON_DASHBOARD_URL"), "/")
+	runURL := func(kind, name string) string {
+		if dashboard == "" || name == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s/#/namespaces/%s/%s/%s", dashboard, namespace, kind, name)
+	}
+	source := "tekton/" + namespace
+	if dashboard != "" {
+		source = dashboard + "/#/namespaces/" + namespace
+	}
+	return &Environment{
+		Source:  source,
+		RunID:   cmp.Or(env("TEKTON_PIPELINE_RUN_UID"), env("TEKTON_PIPELINE_RUN")),
+		RunName: cmp.Or(env("TEKTON_PIPELINE"), env("TEKTON_PIPELINE_RUN")),
+		RunURL:  runURL("pipelineruns", env("TEKTON_PIPELINE_RUN")),
+		JobID:   cmp.Or(env("TEKTON_TASK_RUN_UID"), env("TEKTON_TASK_RUN")),
+		JobName: cmp.Or(env("TEKTON_TASK"), env("TEKTON_TASK_RUN")),
+		JobURL:  runURL("taskruns", env("TEKTON_TASK_RUN")),
+	}
+}
+
+// azurePipelines describes an Azure Pipelines build and job
+func azurePipelines(env Getenv) *Environment {
+	if !strings.EqualFold(env("TF_BUILD"), "true") {
+		return nil
+	}
+	project := strings.TrimSuffix(env("SYSTEM_COLLECTIONURI"), "/") + "/" + env("SYSTEM_TEAMPROJECT")
+	runURL := project + "/_build/results?buildId=" + env("BUILD_BUILDID")
+	jobURL := runURL
+	if jobID := env("SYSTEM_JOBID"); jobID != "" {
+		jobURL += "&view=logs&j=" + jobID
+	}
+	return &Environment{
+		Source:  project,
+		RunID:   env("BUILD_BUILDID"),
+		RunName: env("BUILD_DEFINITIONNAME"),
+		RunURL:  runURL,
+		JobID:   cmp.Or(env("SYSTEM_JOBID"), env("BUILD_BUILDID")),
+		JobName: cmp.Or(env("SYSTEM_JOBDISPLAYNAME"), env("AGENT_JOBNAME")),
+		JobURL:  jobURL,
+	}
+}
+
+// circleCI describes a CircleCI workflow and job
+func circleCI(env Getenv) *Environment {
+	if env("CIRCLECI") != "true" {
+		return nil
+	}
+	project := env("CIRCLE_PROJECT_USERNAME") + "/" + env("CIRCLE_PROJECT_REPONAME")
+	runID := cmp.Or(env("CIRCLE_WORKFLOW_ID"), env("CIRCLE_BUILD_NUM"))
+	runURL := env("CIRCLE_BUILD_URL")
+	if workflow := env("CIRCLE_WORKFLOW_ID"); workflow != "" {
+		runURL = "https://app.circleci.com/pipelines/workflows/" + workflow
+	}
+	return &Environment{
+		Source:  "https://app.circleci.com/pipelines/" + project,
+		RunID:   runID,
+		RunName: project,
+		RunURL:  runURL,
+		JobID:   cmp.Or(env("CIRCLE_WORKFLOW_JOB_ID"), env("CIRCLE_BUILD_NUM")),
+		JobName: env("CIRCLE_JOB"),
+		JobURL:  env("CIRCLE_BUILD_URL"),
+	}
+}
+
+// buildkite describes a Buildkite build and job
+func buildkite(env Getenv) *Environment {
+	if env("BUILDKITE") != "true" {
+		return nil
+	}
+	jobURL := env("BUILDKITE_BUILD_URL")
+	if jobID := env("BUILDKITE_JOB_ID"); jobID != "" && jobURL != "" {
+		jobURL += "#" + jobID
+	}
+	return &Environment{
+		Source:  "https://buildkite.com/" + env("BUILDKITE_ORGANIZATION_SLUG") + "/" + env("BUILDKITE_PIPELINE_SLUG"),
+		RunID:   env("BUILDKITE_BUILD_ID"),
+		RunName: cmp.Or(env("BUILDKITE_PIPELINE_NAME"), env("BUILDKITE_PIPELINE_SLUG")),
+		RunURL:  env("BUILDKITE_BUILD_URL"),
+		JobID:   env("BUILDKITE_JOB_ID"),
+		JobName: cmp.Or(env("BUILDKITE_LABEL"), env("BUILDKITE_STEP_KEY")),
+		JobURL:  jobURL,
+	}
+}
+
+// joinID joins the parts of an ID with dashes, skipping empty parts
+func joinID(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "-")
+}
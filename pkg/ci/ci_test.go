@@ -0,0 +1,120 @@
+package ci_test
+
+import (
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/ci"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name string
+		env  map[string]string
+		want ci.Environment
+	}{
+		{
+			name: "github actions",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true", "GITHUB_SERVER_URL": "https://github.com", "GITHUB_REPOSITORY": "acme/shop",
+				"GITHUB_RUN_ID": "123", "GITHUB_RUN_ATTEMPT": "1", "GITHUB_WORKFLOW": "release", "GITHUB_JOB": "compile",
+			},
+			want: ci.Environment{
+				Provider: "github-actions", Source: "https://github.com/acme/shop",
+				RunID: "123", RunName: "release", RunURL: "https://github.com/acme/shop/actions/runs/123",
+				JobID: "123-compile", JobName: "compile", JobURL: "https://github.com/acme/shop/actions/runs/123",
+			},
+		},
+		{
+			name: "gitlab ci",
+			env: map[string]string{
+				"GITLAB_CI": "true", "CI_PROJECT_URL": "https://gitlab.com/acme/shop", "CI_PROJECT_PATH": "acme/shop",
+				"CI_PIPELINE_ID": "77", "CI_PIPELINE_URL": "https://gitlab.com/acme/shop/-/pipelines/77",
+				"CI_JOB_ID": "901", "CI_JOB_NAME": "test", "CI_JOB_URL": "https://gitlab.com/acme/shop/-/jobs/901",
+			},
+			want: ci.Environment{
+				Provider: "gitlab-ci", Source: "https://gitlab.com/acme/shop",
+				RunID: "77", RunName: "acme/shop", RunURL: "https://gitlab.com/acme/shop/-/pipelines/77",
+				JobID: "901", JobName: "test", JobURL: "https://gitlab.com/acme/shop/-/jobs/901",
+			},
+		},
+		{
+			name: "jenkins stage",
+			env: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/", "JOB_URL": "https://jenkins.example.com/job/shop/",
+				"JOB_NAME": "shop", "BUILD_NUMBER": "42", "BUILD_TAG": "jenkins-shop-42",
+				"BUILD_URL": "https://jenkins.example.com/job/shop/42/", "STAGE_NAME": "Test",
+			},
+			want: ci.Environment{
+				Provider: "jenkins", Source: "https://jenkins.example.com/job/shop/",
+				RunID: "jenkins-shop-42", RunName: "shop", RunURL: "https://jenkins.example.com/job/shop/42/",
+				JobID: "jenkins-shop-42-Test", JobName: "Test", JobURL: "https://jenkins.example.com/job/shop/42/",
+			},
+		},
+		{
+			name: "tekton",
+			env: map[string]string{
+				"TEKTON_PIPELINE_RUN": "release-x7k2", "TEKTON_PIPELINE": "release", "TEKTON_TASK_RUN": "release-x7k2-build",
+				"TEKTON_TASK": "build", "TEKTON_NAMESPACE": "ci", "TEKTON_DASHBOARD_URL": "https://tekton.example.com",
+			},
+			want: ci.Environment{
+				Provider: "tekton", Source: "https://tekton.example.com/#/namespaces/ci",
+				RunID: "release-x7k2", RunName: "release", RunURL: "https://tekton.example.com/#/namespaces/ci/pipelineruns/release-x7k2",
+				JobID: "release-x7k2-build", JobName: "build", JobURL: "https://tekton.example.com/#/namespaces/ci/taskruns/release-x7k2-build",
+			},
+		},
+		{
+			name: "azure pipelines",
+			env: map[string]string{
+				"TF_BUILD": "True", "SYSTEM_COLLECTIONURI": "https://dev.azure.com/acme/", "SYSTEM_TEAMPROJECT": "shop",
+				"BUILD_BUILDID": "555", "BUILD_DEFINITIONNAME": "shop-ci", "SYSTEM_JOBID": "j-1", "SYSTEM_JOBDISPLAYNAME": "Build",
+			},
+			want: ci.Environment{
+				Provider: "azure-pipelines", Source: "https://dev.azure.com/acme/shop",
+				RunID: "555", RunName: "shop-ci", RunURL: "https://dev.azure.com/acme/shop/_build/results?buildId=555",
+				JobID: "j-1", JobName: "Build", JobURL: "https://dev.azure.com/acme/shop/_build/results?buildId=555&view=logs&j=j-1",
+			},
+		},
+		{
+			name: "circleci",
+			env: map[string]string{
+				"CIRCLECI": "true", "CIRCLE_PROJECT_USERNAME": "acme", "CIRCLE_PROJECT_REPONAME": "shop",
+				"CIRCLE_WORKFLOW_ID": "wf-1", "CIRCLE_WORKFLOW_JOB_ID": "job-1", "CIRCLE_JOB": "test",
+				"CIRCLE_BUILD_NUM": "88", "CIRCLE_BUILD_URL": "https://circleci.com/gh/acme/shop/88",
+			},
+			want: ci.Environment{
+				Provider: "circleci", Source: "https://app.circleci.com/pipelines/acme/shop",
+				RunID: "wf-1", RunName: "acme/shop", RunURL: "https://app.circleci.com/pipelines/workflows/wf-1",
+				JobID: "job-1", JobName: "test", JobURL: "https://circleci.com/gh/acme/shop/88",
+			},
+		},
+		{
+			name: "buildkite",
+			env: map[string]string{
+				"BUILDKITE": "true", "BUILDKITE_ORGANIZATION_SLUG": "acme", "BUILDKITE_PIPELINE_SLUG": "shop",
+				"BUILDKITE_BUILD_ID": "b-1", "BUILDKITE_BUILD_URL": "https://buildkite.com/acme/shop/builds/12",
+				"BUILDKITE_JOB_ID": "j-1", "BUILDKITE_LABEL": ":go: test",
+			},
+			want: ci.Environment{
+				Provider: "buildkite", Source: "https://buildkite.com/acme/shop",
+				RunID: "b-1", RunName: "shop", RunURL: "https://buildkite.com/acme/shop/builds/12",
+				JobID: "j-1", JobName: ":go: test", JobURL: "https://buildkite.com/acme/shop/builds/12#j-1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := ci.Detect(func(name string) string { return tc.env[name] })
+			if env == nil {
+				t.Fatalf("expected %s to be detected", tc.name)
+			}
+			if *env != tc.want {
+				t.Errorf("unexpected environment:\n got %+v\nwant %+v", *env, tc.want)
+			}
+		})
+	}
+
+	if env := ci.Detect(func(string) string { return "" }); env != nil {
+		t.Errorf("expected no CI environment, got %+v", env)
+	}
+}
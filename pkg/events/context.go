@@ -0,0 +1,103 @@
+package events
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/google/uuid"
+)
+
+// FactoryOption configures an EventFactory
+type FactoryOption func(*EventFactory)
+
+// WithClock sets the clock events get their timestamp from, time.Now by default
+func WithClock(now func() time.Time) FactoryOption {
+	return func(ef *EventFactory) {
+		ef.now = now
+	}
+}
+
+// WithIDGenerator sets the generator of event IDs, random UUIDs by default
+func WithIDGenerator(newID func() string) FactoryOption {
+	return func(ef *EventFactory) {
+		ef.newID = newID
+	}
+}
+
+// newUUID returns a random UUID
+func newUUID() string {
+	return uuid.New().String()
+}
+
+// WithEventID sets the ID of the event instead of a generated one
+func WithEventID(id string) EventOption {
+	return func(o *eventOptions) {
+		o.eventID = id
+	}
+}
+
+// WithTimestamp sets the timestamp of the event instead of the current time, e.g. to backfill historical runs
+func WithTimestamp(timestamp time.Time) EventOption {
+	return func(o *eventOptions) {
+		o.timestamp = timestamp
+	}
+}
+
+// WithSource sets the source of the event instead of the default source of the factory
+func WithSource(source string) EventOption {
+	return func(o *eventOptions) {
+		o.source = source
+	}
+}
+
+// WithSubjectSource sets the source of the event subject, which defaults to the event source
+func WithSubjectSource(source string) EventOption {
+	return func(o *eventOptions) {
+		o.subjectSource = source
+	}
+}
+
+// setContext sets the context attributes and subject ID of a new event
+func (ef *EventFactory) setContext(event api.CDEvent, subjectID string, options *eventOptions) error {
+	id := options.eventID
+	if id == "" {
+		id = ef.newID()
+	}
+	source := options.source
+	if source == "" {
+		source = ef.defaultSource
+	}
+	timestamp := options.timestamp
+	if timestamp.IsZero() {
+		timestamp = ef.now()
+	}
+
+	event.SetId(id)
+	event.SetSource(source)
+	event.SetTimestamp(timestamp)
+	event.SetSubjectId(subjectID)
+	if options.subjectSource != "" {
+		event.SetSubjectSource(options.subjectSource)
+	}
+	return applyLinks(event, options)
+}
+
+// ParseTimestamp parses an RFC3339 timestamp, such as 2024-05-01T12:00:00Z, or unix seconds, such as 1714564800
+func ParseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if timestamp, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return timestamp, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(seconds, 0) && !math.IsNaN(seconds) {
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(math.Round(fraction*1e9))).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q: expected RFC3339, e.g. 2024-05-01T12:00:00Z, or unix seconds", value)
+}
@@ -0,0 +1,100 @@
+package events_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+)
+
+func TestFactoryClockAndIDGenerator(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	n := 0
+	factory := events.NewEventFactory("test-source",
+		events.WithClock(func() time.Time { return now }),
+		events.WithIDGenerator(func() string {
+			n++
+			return fmt.Sprintf("event-%d", n)
+		}),
+	)
+
+	// Events are reproducible with an injected clock and ID generator
+	for _, want := range []string{"event-1", "event-2"} {
+		event, err := factory.CreateBuildEvent("started", "build-123", "test-build", "", "", "", nil)
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if event.GetId() != want || !event.GetTimestamp().Equal(now) {
+			t.Errorf("expected %s at %v, got %s at %v", want, now, event.GetId(), event.GetTimestamp())
+		}
+	}
+}
+
+func TestContextOverrides(t *testing.T) {
+	timestamp := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	for _, specVersion := range events.SpecVersions() {
+		factory, err := events.NewEventFactoryForSpecVersion("test-source", specVersion)
+		if err != nil {
+			t.Fatalf("failed to create factory: %v", err)
+		}
+		event, err := factory.CreatePipelineRunEvent("started", "pipeline-123", "test-pipeline", "", "", "https://ci.example.com/pipelines/123", nil,
+			events.WithEventID("backfill-1"),
+			events.WithTimestamp(timestamp),
+			events.WithSource("https://ci.example.com"),
+			events.WithSubjectSource("https://git.example.com/repo"),
+		)
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if event.GetId() != "backfill-1" || !event.GetTimestamp().Equal(timestamp) || event.GetSource() != "https://ci.example.com" {
+			t.Errorf("spec version %s: context overrides not applied: %s %v %s", specVersion, event.GetId(), event.GetTimestamp(), event.GetSource())
+		}
+		if event.GetSubjectSource() != "https://git.example.com/repo" {
+			t.Errorf("spec version %s: expected the subject source, got %q", specVersion, event.GetSubjectSource())
+		}
+		if err := validator.ValidateEvent(event); err != nil {
+			t.Errorf("spec version %s: event should be valid: %v", specVersion, err)
+		}
+	}
+
+	// Without overrides the factory defaults are used, the SDK sets the subject source to the event source
+	event, err := events.NewEventFactory("test-source").CreateTaskRunEvent("started", "task-123", "test-task", "", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	data, _ := json.Marshal(event)
+	if event.GetId() == "" || event.GetSource() != "test-source" || event.GetSubjectSource() != "test-source" {
+		t.Errorf("unexpected context: %s", data)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-05-01T12:00:00Z", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"2024-05-01T14:00:00.5+02:00", time.Date(2024, 5, 1, 12, 0, 0, 500000000, time.UTC)},
+		{"1714564800", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"1714564800.25", time.Date(2024, 5, 1, 12, 0, 0, 250000000, time.UTC)},
+	}
+	for _, tc := range testCases {
+		got, err := events.ParseTimestamp(tc.value)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v", tc.value, got, err, tc.want)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "2024-05-01", "Inf"} {
+		if _, err := events.ParseTimestamp(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+// FieldChange describes a field that could not be carried over unchanged when converting an event
+type FieldChange struct {
+	// Path is the JSON pointer of the field within the original event
+	Path string `json:"path"`
+	// Message describes what happened to the field
+	Message string `json:"message"`
+}
+
+// String returns the change as "path: message"
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %s", c.Path, c.Message)
+}
+
+// ConvertEvent converts a decoded CDEvent to another spec version, such as 0.3, 0.4 or 0.5.
+// Fields the target version does not define are dropped and reported as lossy changes.
+func ConvertEvent(eventMap map[string]interface{}, specVersion string) (api.CDEvent, []FieldChange, error) {
+	target, err := lookupSpecPackage(specVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	context, _ := eventMap["context"].(map[string]interface{})
+	eventType, _ := context["type"].(string)
+	if !strings.HasPrefix(eventType, "dev.cdevents.") {
+		return nil, nil, fmt.Errorf("cannot convert event type %q: only dev.cdevents events have spec versions", eventType)
+	}
+	subject, predicate := eventTypeParts(eventMap)
+	targetType, err := target.eventType(subject, predicate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot convert %s: %w", eventType, err)
+	}
+
+	// Work on a plain JSON copy so the original map is untouched and numbers compare equal
+	source, err := jsonCopy(eventMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	sourceContext, _ := source["context"].(map[string]interface{})
+	delete(sourceContext, "version")
+	delete(sourceContext, "specversion")
+	sourceContext[target.versionField] = target.version
+	sourceContext["type"] = targetType
+
+	event, err := target.newEvent(targetType, target.version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create %s event: %w", targetType, err)
+	}
+	alignSubject(source, event)
+	if subject == "testcaserun" || subject == "testsuiterun" {
+		sourceSubject, _ := source["subject"].(map[string]interface{})
+		content, _ := sourceSubject["content"].(map[string]interface{})
+		if outcome, ok := content["outcome"].(string); ok {
+			// Spec version 0.5 renamed the test outcomes pass and fail to success and failure
+			content["outcome"] = target.testOutcome(outcome)
+		}
+	}
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert event to spec version %s: %w", target.version, err)
+	}
+
+	converted, err := jsonCopy(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	var changes []FieldChange
+	diffFields("", source, converted, target.version, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return event, changes, nil
+}
+
+// DecodeEvent decodes a CDEvent, such as an event read from a file, in its own spec version.
+// Every call returns a new event, so decoded events can be sent concurrently.
+func DecodeEvent(eventMap map[string]interface{}) (api.CDEvent, error) {
+	context, _ := eventMap["context"].(map[string]interface{})
+	target, err := lookupSpecPackage(ContextVersion(context))
+	if err != nil {
+		return nil, err
+	}
+	typeName, _ := context["type"].(string)
+	eventType, err := api.ParseType(typeName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event type %q: %w", typeName, err)
+	}
+
+	var event api.CDEvent
+	if eventType.Custom != "" {
+		if target.newCustomEvent == nil {
+			return nil, fmt.Errorf("custom events are not defined in spec version %s", target.version)
+		}
+		event, err = target.newCustomEvent()
+	} else {
+		versioned, ok := target.types[eventType.UnversionedString()]
+		if !ok {
+			return nil, fmt.Errorf("event type %s is not defined in spec version %s", typeName, target.version)
+		}
+		event, err = target.newEvent(versioned, target.version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s event: %w", typeName, err)
+	}
+
+	data, err := json.Marshal(eventMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", typeName, err)
+	}
+	return event, nil
+}
+
+// alignSubject adapts the subject of a decoded event to the fields of the target event.
+// Spec version 0.5 renamed the url of subjects to uri and dropped the subject type implied by the event type.
+func alignSubject(source map[string]interface{}, event api.CDEvent) {
+	subject, _ := source["subject"].(map[string]interface{})
+	subjectValue := reflect.Indirect(reflect.ValueOf(event)).FieldByName("Subject")
+	if subject == nil || !subjectValue.IsValid() {
+		return
+	}
+	if !jsonFields(subjectValue.Type())["type"] {
+		delete(subject, "type")
+	}
+
+	content, _ := subject["content"].(map[string]interface{})
+	contentValue := subjectValue.FieldByName("Content")
+	if content == nil || !contentValue.IsValid() {
+		return
+	}
+	contentFields := jsonFields(contentValue.Type())
+	for from, to := range map[string]string{"url": "uri", "uri": "url"} {
+		if value, ok := content[from]; ok && !contentFields[from] && contentFields[to] && content[to] == nil {
+			content[to] = value
+			delete(content, from)
+		}
+	}
+}
+
+// jsonFields returns the JSON field names of a struct type, including the fields of embedded structs
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for name := range jsonFields(field.Type) {
+				fields[name] = true
+			}
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// jsonCopy returns a value as a JSON decoded map
+func jsonCopy(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return result, nil
+}
+
+// diffFields records the fields of source that are missing or different in converted
+func diffFields(path string, source, converted interface{}, specVersion string, changes *[]FieldChange) {
+	sourceMap, ok := source.(map[string]interface{})
+	if !ok {
+		if !reflect.DeepEqual(source, converted) {
+			*changes = append(*changes, FieldChange{
+				Path:    path,
+				Message: fmt.Sprintf("changed from %s to %s", jsonString(source), jsonString(converted)),
+			})
+		}
+		return
+	}
+
+	convertedMap, _ := converted.(map[string]interface{})
+	for key, value := range sourceMap {
+		fieldPath := path + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+		convertedValue, found := convertedMap[key]
+		if !found {
+			if !isEmptyValue(value) {
+				*changes = append(*changes, FieldChange{
+					Path:    fieldPath,
+					Message: fmt.Sprintf("dropped, not defined in spec version %s", specVersion),
+				})
+			}
+			continue
+		}
+		diffFields(fieldPath, value, convertedValue, specVersion, changes)
+	}
+}
+
+// isEmptyValue reports whether a decoded JSON value is null or empty, so dropping it loses nothing
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// jsonString returns a value as compact JSON for messages
+func jsonString(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
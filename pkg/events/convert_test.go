@@ -0,0 +1,238 @@
+package events_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+func TestSpecVersions(t *testing.T) {
+	testCases := []struct {
+		version string
+		want    string
+	}{
+		{"", "0.4.1"},
+		{"0.4", "0.4.1"},
+		{"v0.4.1", "0.4.1"},
+		{"0.3", "0.3.0"},
+		{"0.3.0", "0.3.0"},
+		{"0.5", "0.5.0"},
+	}
+	for _, tc := range testCases {
+		got, err := events.ResolveSpecVersion(tc.version)
+		if err != nil || got != tc.want {
+			t.Errorf("ResolveSpecVersion(%q) = %q, %v, want %q", tc.version, got, err, tc.want)
+		}
+	}
+
+	if _, err := events.ResolveSpecVersion("0.6"); err == nil || !strings.Contains(err.Error(), "0.3.0, 0.4.1, 0.5.0") {
+		t.Errorf("expected an unsupported spec version error listing the supported versions, got %v", err)
+	}
+}
+
+func TestEventFactoryForSpecVersion(t *testing.T) {
+	factory, err := events.NewEventFactoryForSpecVersion("https://ci.example.com", "0.3")
+	if err != nil {
+		t.Fatalf("failed to create factory: %v", err)
+	}
+	if factory.SpecVersion() != "0.3.0" {
+		t.Errorf("expected spec version 0.3.0, got %s", factory.SpecVersion())
+	}
+
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	pipeline, err := factory.CreatePipelineRunEvent("finished", "pipeline-1", "my-pipeline", "success", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create pipeline event: %v", err)
+	}
+	if pipeline.GetType().String() != "dev.cdevents.pipelinerun.finished.0.1.1" || pipeline.GetVersion() != "0.3.0" {
+		t.Errorf("unexpected v0.3 event: %s %s", pipeline.GetType(), pipeline.GetVersion())
+	}
+	if err := validator.ValidateEvent(pipeline); err != nil {
+		t.Errorf("v0.3 pipeline event should match the v0.3.0 schema: %v", err)
+	}
+
+	test, err := factory.CreateTestEvent("testcase-finished", "test-1", "my-test", "success", "", "", nil, events.WithEnvironment("ci"))
+	if err != nil {
+		t.Fatalf("failed to create test event: %v", err)
+	}
+	if err := validator.ValidateEvent(test); err != nil {
+		t.Errorf("v0.3 test event should match the v0.3.0 schema: %v", err)
+	}
+
+	// Events introduced after v0.3 are rejected
+	if _, err := factory.CreateTestEvent("testcase-skipped", "test-1", "my-test", "", "", "", nil); err == nil || !strings.Contains(err.Error(), "not defined in spec version 0.3.0") {
+		t.Errorf("expected testcase-skipped to be rejected for v0.3, got %v", err)
+	}
+
+	// Spec version 0.5 names test outcomes success and failure instead of pass and fail
+	v05, err := events.NewEventFactoryForSpecVersion("https://ci.example.com", "0.5")
+	if err != nil {
+		t.Fatalf("failed to create factory: %v", err)
+	}
+	for _, outcome := range []string{"pass", "failed"} {
+		test, err := v05.CreateTestEvent("testcase-finished", "test-1", "my-test", outcome, "", "", nil, events.WithEnvironment("ci"))
+		if err != nil {
+			t.Fatalf("failed to create test event: %v", err)
+		}
+		if err := validator.ValidateEvent(test); err != nil {
+			t.Errorf("v0.5 test event with outcome %s should match the v0.5.0 schema: %v", outcome, err)
+		}
+	}
+	if _, err := events.NewEventFactoryForSpecVersion("source", "0.6"); err == nil {
+		t.Errorf("expected an error for spec version 0.6")
+	}
+}
+
+func TestConvertEvent(t *testing.T) {
+	// A v0.4 event with links has no v0.3 equivalent for its chain ID
+	var event map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"context": {"version": "0.4.1", "id": "1", "source": "ci", "type": "dev.cdevents.pipelinerun.finished.0.2.0",
+			"timestamp": "2024-01-01T00:00:00Z", "chainId": "chain-1"},
+		"subject": {"id": "pipeline-1", "type": "pipelineRun", "content": {"pipelineName": "p", "outcome": "success"}},
+		"customData": {"team": "platform"}
+	}`), &event)
+	if err != nil {
+		t.Fatalf("failed to parse event: %v", err)
+	}
+
+	converted, changes, err := events.ConvertEvent(event, "0.3")
+	if err != nil {
+		t.Fatalf("failed to convert event: %v", err)
+	}
+	if converted.GetType().String() != "dev.cdevents.pipelinerun.finished.0.1.1" || converted.GetVersion() != "0.3.0" {
+		t.Errorf("unexpected converted event: %s %s", converted.GetType(), converted.GetVersion())
+	}
+	if len(changes) != 1 || changes[0].Path != "/context/chainId" {
+		t.Errorf("expected the chain ID to be reported as dropped, got %v", changes)
+	}
+	if data, _ := converted.GetCustomData(); data == nil {
+		t.Errorf("custom data should be kept")
+	}
+
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	if err := validator.ValidateEvent(converted); err != nil {
+		t.Errorf("converted event should match the v0.3.0 schema: %v", err)
+	}
+
+	// Upgrading back is lossless
+	factory, _ := events.NewEventFactoryForSpecVersion("ci", "0.3")
+	v03, err := factory.CreateTaskRunEvent("started", "task-1", "my-task", "pipeline-1", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create task event: %v", err)
+	}
+	data, _ := json.Marshal(v03)
+	var v03Map map[string]interface{}
+	json.Unmarshal(data, &v03Map)
+	upgraded, changes, err := events.ConvertEvent(v03Map, "0.4")
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected a lossless upgrade, got %v, %v", changes, err)
+	}
+	if err := validator.ValidateEvent(upgraded); err != nil {
+		t.Errorf("upgraded event should match the v0.4.1 schema: %v", err)
+	}
+
+	// From spec version 0.5 the context carries the version as specversion and subject URLs are named uri
+	pipeline, _ := factory.CreatePipelineRunEvent("started", "pipeline-1", "my-pipeline", "", "", "https://ci.example.com/1", nil)
+	data, _ = json.Marshal(pipeline)
+	var pipelineMap map[string]interface{}
+	json.Unmarshal(data, &pipelineMap)
+	v05, changes, err := events.ConvertEvent(pipelineMap, "0.5")
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected a lossless upgrade, got %v, %v", changes, err)
+	}
+	if v05.GetVersion() != "0.5.0" || v05.GetType().String() != "dev.cdevents.pipelinerun.started.0.3.0" {
+		t.Errorf("unexpected v0.5 event: %s %s", v05.GetType(), v05.GetVersion())
+	}
+	v05Map, _ := json.Marshal(v05)
+	if !strings.Contains(string(v05Map), `"specversion":"0.5.0"`) || !strings.Contains(string(v05Map), `"uri":"https://ci.example.com/1"`) {
+		t.Errorf("expected a specversion context field and a subject uri, got %s", v05Map)
+	}
+	if err := validator.ValidateEvent(v05); err != nil {
+		t.Errorf("upgraded event should match the v0.5.0 schema: %v", err)
+	}
+	var downgradeMap map[string]interface{}
+	json.Unmarshal(v05Map, &downgradeMap)
+	v04, changes, err := events.ConvertEvent(downgradeMap, "0.4")
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected a lossless downgrade, got %v, %v", changes, err)
+	}
+	if err := validator.ValidateEvent(v04); err != nil {
+		t.Errorf("downgraded event should match the v0.4.1 schema: %v", err)
+	}
+
+	// Events without an equivalent in the target version cannot be converted
+	event["context"].(map[string]interface{})["type"] = "dev.cdevents.ticket.created.0.1.0"
+	if _, _, err := events.ConvertEvent(event, "0.3"); err == nil || !strings.Contains(err.Error(), "not defined in spec version 0.3.0") {
+		t.Errorf("expected a conversion error for ticket events, got %v", err)
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	decode := func(text string) (api.CDEvent, error) {
+		var eventMap map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &eventMap); err != nil {
+			t.Fatalf("failed to parse event: %v", err)
+		}
+		return events.DecodeEvent(eventMap)
+	}
+
+	const pipeline = `{
+		"context": {"version": "0.4.1", "id": "%s", "source": "ci", "type": "dev.cdevents.pipelinerun.started.0.2.0", "timestamp": "2024-01-01T00:00:00Z"},
+		"subject": {"id": "pipeline-1", "content": {"pipelineName": "p", "url": "https://ci.example.com/1"}}
+	}`
+	first, err := decode(fmt.Sprintf(pipeline, "event-1"))
+	if err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	second, err := decode(fmt.Sprintf(pipeline, "event-2"))
+	if err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if first.GetId() != "event-1" || second.GetId() != "event-2" || first.GetSubjectId() != "pipeline-1" {
+		t.Errorf("decoded events should not share state: %s %s", first.GetId(), second.GetId())
+	}
+	if first.GetVersion() != "0.4.1" || first.GetType().String() != "dev.cdevents.pipelinerun.started.0.2.0" {
+		t.Errorf("unexpected event: %s %s", first.GetVersion(), first.GetType())
+	}
+
+	custom, err := decode(`{
+		"context": {"version": "0.4.1", "id": "1", "source": "ci", "type": "dev.cdeventsx.flags-flag.flipped.0.1.0", "timestamp": "2024-01-01T00:00:00Z"},
+		"subject": {"id": "checkout-v2", "content": {"enabled": true}}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to decode custom event: %v", err)
+	}
+	if custom.GetType().String() != "dev.cdeventsx.flags-flag.flipped.0.1.0" {
+		t.Errorf("unexpected custom event type: %s", custom.GetType())
+	}
+
+	v05, err := decode(`{
+		"context": {"specversion": "0.5.0", "id": "1", "source": "ci", "type": "dev.cdevents.pipelinerun.started.0.3.0", "timestamp": "2024-01-01T00:00:00Z"},
+		"subject": {"id": "pipeline-1", "content": {"pipelineName": "p", "uri": "https://ci.example.com/1"}}
+	}`)
+	if err != nil {
+		t.Fatalf("failed to decode v0.5 event: %v", err)
+	}
+	if v05.GetVersion() != "0.5.0" || v05.GetSubjectId() != "pipeline-1" {
+		t.Errorf("unexpected v0.5 event: %s %s", v05.GetVersion(), v05.GetSubjectId())
+	}
+
+	if _, err := decode(`{"context": {"version": "0.3.0", "type": "dev.cdevents.ticket.created.0.1.0"}}`); err == nil || !strings.Contains(err.Error(), "not defined in spec version 0.3.0") {
+		t.Errorf("expected an undefined type error, got %v", err)
+	}
+	if _, err := decode(`{"context": {"version": "0.4.1", "type": "pipeline"}}`); err == nil {
+		t.Errorf("expected an invalid type error")
+	}
+}
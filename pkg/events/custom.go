@@ -0,0 +1,174 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	jsonschema "github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// CustomSchema is a user JSON schema for the subject content of custom dev.cdeventsx events
+type CustomSchema struct {
+	// URI identifies the schema, it is set as the schemaUri of custom events
+	URI string
+
+	schema *jsonschema.Schema
+}
+
+// LoadCustomSchema reads a JSON schema for the subject content of custom events.
+// The schema URI is the $id of the schema, or the file URI of the schema file when it has none.
+func LoadCustomSchema(filename string) (*CustomSchema, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema file: %w", err)
+	}
+	schema, err := ParseCustomSchema(data, "file://"+filepath.ToSlash(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %w", filename, err)
+	}
+	return schema, nil
+}
+
+// ParseCustomSchema parses a JSON schema for the subject content of custom events,
+// identified by its $id or by uri when it has none.
+// The schema is also registered with the SDK, which checks events carrying its URI when rendering them as CloudEvents.
+func ParseCustomSchema(data []byte, uri string) (*CustomSchema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	object, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema must be a JSON object")
+	}
+	if id, _ := object["$id"].(string); id != "" {
+		uri = id
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(uri, doc); err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	schema, err := compiler.Compile(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+	if err := registerCustomSchema(uri, object); err != nil {
+		return nil, err
+	}
+	return &CustomSchema{URI: uri, schema: schema}, nil
+}
+
+// registerCustomSchema registers an event schema with the SDK under the schema URI, which applies the
+// content schema to the subject content. A URI is registered once, as the SDK can't replace schemas.
+func registerCustomSchema(uri string, content map[string]interface{}) error {
+	if _, found := api.CompiledCustomSchemas[uri]; found {
+		return nil
+	}
+
+	// The content schema gets its own ID so its local references still resolve
+	contentURI := strings.TrimSuffix(uri, "/") + "/subject-content"
+	contentSchema := make(map[string]interface{}, len(content))
+	for key, value := range content {
+		if key != "$id" {
+			contentSchema[key] = value
+		}
+	}
+	eventSchema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"subject": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"content": map[string]interface{}{"$ref": contentURI},
+				},
+			},
+		},
+	}
+	// The content schema is loaded first, so the event schema reference resolves
+	schemas := []struct {
+		id     string
+		schema map[string]interface{}
+	}{{contentURI, contentSchema}, {uri, eventSchema}}
+	for _, s := range schemas {
+		data, err := json.Marshal(s.schema)
+		if err != nil {
+			return fmt.Errorf("failed to marshal schema: %w", err)
+		}
+		var exists *jsonschema.ResourceExistsError
+		if err := api.LoadJsonSchema(s.id, data); err != nil && !errors.As(err, &exists) {
+			return fmt.Errorf("failed to register schema %s: %w", s.id, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks subject content against the schema
+func (s *CustomSchema) Validate(content interface{}) error {
+	// Go through JSON so content of any Go type is checked as the event carries it
+	data, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subject content: %w", err)
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal subject content: %w", err)
+	}
+	if err := s.schema.Validate(value); err != nil {
+		return fmt.Errorf("subject content does not match schema %s: %w", s.URI, err)
+	}
+	return nil
+}
+
+// CreateCustomEvent creates a custom event of a dev.cdeventsx type, e.g. dev.cdeventsx.mytool-resource.created.0.1.0.
+// The subject content is validated against the schema, if any, whose URI is set as the schemaUri of the event.
+func (ef *EventFactory) CreateCustomEvent(eventType, subjectID string, content interface{}, schema *CustomSchema, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
+	customType, err := api.ParseType(eventType)
+	if err != nil || customType.Custom == "" {
+		return nil, fmt.Errorf("invalid custom event type %q: expected %s.<tool>-<subject>.<predicate>.<version>, e.g. dev.cdeventsx.mytool-resource.created.0.1.0", eventType, api.CustomEventTypeRoot)
+	}
+	if ef.spec.newCustomEvent == nil {
+		return nil, fmt.Errorf("custom events are not defined in spec version %s", ef.spec.version)
+	}
+	event, err := ef.spec.newCustomEvent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create custom event: %w", err)
+	}
+
+	event.SetEventType(*customType)
+	if err := ef.setContext(event, subjectID, options); err != nil {
+		return nil, err
+	}
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+	if schema != nil {
+		if err := schema.Validate(content); err != nil {
+			return nil, err
+		}
+		event.SetSchemaUri(schema.URI)
+	}
+	event.SetSubjectContent(content)
+
+	// Apply custom data if provided
+	if customData != nil {
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,120 @@
+package events_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+const approvalSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://schemas.example.com/approvals/deployment-approved.json",
+  "type": "object",
+  "properties": {
+    "approver": {"type": "string"},
+    "environment": {"$ref": "#/$defs/environment"}
+  },
+  "required": ["approver", "environment"],
+  "$defs": {"environment": {"enum": ["staging", "prod"]}}
+}`
+
+func TestCreateCustomEvent(t *testing.T) {
+	schema, err := events.ParseCustomSchema([]byte(approvalSchema), "file:///unused.json")
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	if schema.URI != "https://schemas.example.com/approvals/deployment-approved.json" {
+		t.Errorf("expected the $id as schema URI, got %s", schema.URI)
+	}
+
+	factory := events.NewEventFactory("https://ci.example.com")
+	content := map[string]interface{}{"approver": "alice", "environment": "prod"}
+	event, err := factory.CreateCustomEvent("dev.cdeventsx.approvals-deployment.approved.0.1.0", "approval-1", content, schema, nil,
+		events.WithChainID("chain-1"))
+	if err != nil {
+		t.Fatalf("failed to create custom event: %v", err)
+	}
+	if event.GetType().String() != "dev.cdeventsx.approvals-deployment.approved.0.1.0" || event.GetSubjectId() != "approval-1" {
+		t.Errorf("unexpected event: %s %s", event.GetType(), event.GetSubjectId())
+	}
+	v04, ok := event.(api.CDEventReaderV04)
+	if !ok || v04.GetSchemaUri() != schema.URI || v04.GetChainId() != "chain-1" {
+		t.Errorf("expected the schema URI and chain ID to be set")
+	}
+
+	// The event matches the custom event schema, and the SDK checks the content when rendering a CloudEvent
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	if err := validator.ValidateEvent(event); err != nil {
+		t.Errorf("custom event should be valid: %v", err)
+	}
+	ce, err := api.AsCloudEvent(event)
+	if err != nil {
+		t.Fatalf("custom event should render as CloudEvent: %v", err)
+	}
+	if ce.Type() != "dev.cdeventsx.approvals-deployment.approved.0.1.0" {
+		t.Errorf("expected the custom type as CloudEvent type, got %s", ce.Type())
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	if !strings.Contains(string(data), `"content":{"approver":"alice","environment":"prod"}`) {
+		t.Errorf("expected the subject content, got %s", data)
+	}
+
+	testCases := []struct {
+		name        string
+		eventType   string
+		content     interface{}
+		specVersion string
+		want        string
+	}{
+		{"content breaks schema", "dev.cdeventsx.approvals-deployment.approved.0.1.0", map[string]interface{}{"approver": "alice", "environment": "dev"}, "0.4", "does not match schema"},
+		{"missing content", "dev.cdeventsx.approvals-deployment.approved.0.1.0", nil, "0.4", "missing propert"},
+		{"core event type", "dev.cdevents.pipelinerun.started.0.2.0", content, "0.4", "invalid custom event type"},
+		{"spec version 0.3", "dev.cdeventsx.approvals-deployment.approved.0.1.0", content, "0.3", "not defined in spec version 0.3.0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory, err := events.NewEventFactoryForSpecVersion("https://ci.example.com", tc.specVersion)
+			if err != nil {
+				t.Fatalf("failed to create factory: %v", err)
+			}
+			_, err = factory.CreateCustomEvent(tc.eventType, "approval-1", tc.content, schema, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadCustomSchema(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "flag-flipped.json")
+	if err := os.WriteFile(filename, []byte(`{"type": "object", "required": ["flag"]}`), 0o644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	schema, err := events.LoadCustomSchema(filename)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+	// Schemas without $id are identified by their file URI
+	if !strings.HasPrefix(schema.URI, "file://") || !strings.HasSuffix(schema.URI, "/flag-flipped.json") {
+		t.Errorf("expected a file URI, got %s", schema.URI)
+	}
+	if err := schema.Validate(map[string]interface{}{"flag": "checkout-v2"}); err != nil {
+		t.Errorf("content should match the schema: %v", err)
+	}
+
+	if _, err := events.ParseCustomSchema([]byte(`{"type": 42}`), "https://schemas.example.com/invalid.json"); err == nil {
+		t.Errorf("expected an error for an invalid schema")
+	}
+}
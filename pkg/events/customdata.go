@@ -0,0 +1,177 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// JSONContentType is the custom data content type of structured custom data
+const JSONContentType = "application/json"
+
+// ParseCustomData parses custom data of a content type, application/json when empty.
+// JSON data is decoded, data of other content types such as application/xml is kept as bytes,
+// which events carry base64 encoded.
+func ParseCustomData(data []byte, contentType string) (*CustomData, error) {
+	if contentType == "" {
+		contentType = JSONContentType
+	}
+	if contentType != JSONContentType {
+		return &CustomData{
+			Data:        data,
+			ContentType: contentType,
+		}, nil
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	return ParseCustomDataFromJSON(string(data))
+}
+
+// ParseCustomDataFromYAML parses custom data from a YAML document, events carry it as JSON
+func ParseCustomDataFromYAML(yamlData string) (*CustomData, error) {
+	if strings.TrimSpace(yamlData) == "" {
+		return nil, nil
+	}
+
+	var data interface{}
+	if err := yaml.Unmarshal([]byte(yamlData), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse custom data YAML: %w", err)
+	}
+	data, err := jsonCompatible(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse custom data YAML: %w", err)
+	}
+
+	return &CustomData{
+		Data:        data,
+		ContentType: JSONContentType,
+	}, nil
+}
+
+// EventCustomData returns the custom data of an event, with JSON data decoded
+func EventCustomData(event api.CDEvent) (*CustomData, error) {
+	data, err := event.GetCustomData()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custom data: %w", err)
+	}
+	return &CustomData{
+		Data:        data,
+		ContentType: event.GetCustomDataContentType(),
+	}, nil
+}
+
+// SetEventCustomData sets custom data on an event.
+// Data of other content types than JSON is set as bytes, which the SDK serializes as base64.
+func SetEventCustomData(event api.CDEvent, customData *CustomData) error {
+	contentType := customData.ContentType
+	if contentType == "" {
+		contentType = JSONContentType
+	}
+	data := customData.Data
+	if contentType != JSONContentType {
+		if s, ok := data.(string); ok {
+			data = []byte(s)
+		}
+	}
+	if err := event.SetCustomData(contentType, data); err != nil {
+		return fmt.Errorf("failed to set custom data: %w", err)
+	}
+	return nil
+}
+
+// SetField sets a field of JSON custom data, creating the objects of a dotted path such as build.tool.
+// Values are coerced to JSON: true, false, null, numbers, quoted strings, arrays and objects
+// are decoded, anything else is kept as a string.
+func (c *CustomData) SetField(path, value string) error {
+	if c.ContentType != "" && c.ContentType != JSONContentType {
+		return fmt.Errorf("cannot set %s: fields can only be set on %s custom data, not %s", path, JSONContentType, c.ContentType)
+	}
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if key == "" {
+			return fmt.Errorf("invalid custom data field %q: empty key", path)
+		}
+	}
+
+	if c.Data == nil {
+		c.Data = map[string]interface{}{}
+	}
+	c.ContentType = JSONContentType
+	object, ok := c.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cannot set %s: custom data is not an object", path)
+	}
+	for i, key := range keys[:len(keys)-1] {
+		next, found := object[key]
+		if !found {
+			next = map[string]interface{}{}
+			object[key] = next
+		}
+		nested, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot set %s: %s is not an object", path, strings.Join(keys[:i+1], "."))
+		}
+		object = nested
+	}
+	object[keys[len(keys)-1]] = coerceValue(value)
+	return nil
+}
+
+// coerceValue returns a key=value value as the JSON value it spells, or as a string
+func coerceValue(value string) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+// jsonCompatible converts decoded YAML to values encoding/json can marshal, such as maps with string keys
+func jsonCompatible(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			object[fmt.Sprint(key)] = converted
+		}
+		return object, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	}
+
+	// Go through JSON so scalars such as timestamps get their JSON representation
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
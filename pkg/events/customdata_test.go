@@ -0,0 +1,130 @@
+package events_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+)
+
+func TestParseCustomData(t *testing.T) {
+	customData, err := events.ParseCustomData([]byte(`{"team":"platform"}`), "")
+	if err != nil {
+		t.Fatalf("failed to parse custom data: %v", err)
+	}
+	if customData.ContentType != events.JSONContentType || !reflect.DeepEqual(customData.Data, map[string]interface{}{"team": "platform"}) {
+		t.Errorf("unexpected custom data: %+v", customData)
+	}
+
+	// Other content types are kept as bytes
+	customData, err = events.ParseCustomData([]byte("<team>platform</team>"), "application/xml")
+	if err != nil {
+		t.Fatalf("failed to parse custom data: %v", err)
+	}
+	if data, ok := customData.Data.([]byte); !ok || string(data) != "<team>platform</team>" || customData.ContentType != "application/xml" {
+		t.Errorf("unexpected custom data: %+v", customData)
+	}
+
+	if _, err := events.ParseCustomData([]byte(`{"team":`), events.JSONContentType); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+}
+
+func TestParseCustomDataFromYAML(t *testing.T) {
+	customData, err := events.ParseCustomDataFromYAML("team: platform\nreplicas: 3\nports:\n  80: http\n")
+	if err != nil {
+		t.Fatalf("failed to parse custom data: %v", err)
+	}
+	want := map[string]interface{}{
+		"team":     "platform",
+		"replicas": float64(3),
+		"ports":    map[string]interface{}{"80": "http"},
+	}
+	if customData.ContentType != events.JSONContentType || !reflect.DeepEqual(customData.Data, want) {
+		t.Errorf("unexpected custom data: %#v", customData.Data)
+	}
+
+	if customData, err := events.ParseCustomDataFromYAML("  \n"); err != nil || customData != nil {
+		t.Errorf("expected no custom data for an empty document, got %+v, %v", customData, err)
+	}
+	if _, err := events.ParseCustomDataFromYAML("team: [platform"); err == nil {
+		t.Errorf("expected an error for invalid YAML")
+	}
+}
+
+func TestCustomDataSetField(t *testing.T) {
+	customData := &events.CustomData{Data: map[string]interface{}{"team": "platform"}}
+	fields := [][2]string{
+		{"build.tool", "webpack"},
+		{"build.number", "42"},
+		{"build.cached", "true"},
+		{"build.version", `"007"`},
+		{"tags", `["a","b"]`},
+		{"note", "not json"},
+	}
+	for _, field := range fields {
+		if err := customData.SetField(field[0], field[1]); err != nil {
+			t.Fatalf("failed to set %s: %v", field[0], err)
+		}
+	}
+	want := map[string]interface{}{
+		"team": "platform",
+		"build": map[string]interface{}{
+			"tool":    "webpack",
+			"number":  float64(42),
+			"cached":  true,
+			"version": "007",
+		},
+		"tags": []interface{}{"a", "b"},
+		"note": "not json",
+	}
+	if !reflect.DeepEqual(customData.Data, want) || customData.ContentType != events.JSONContentType {
+		t.Errorf("unexpected custom data: %#v", customData.Data)
+	}
+
+	testCases := []struct {
+		name       string
+		customData *events.CustomData
+		path       string
+		want       string
+	}{
+		{"not an object", customData, "team.name", "team is not an object"},
+		{"empty key", customData, "build..tool", "empty key"},
+		{"array custom data", &events.CustomData{Data: []interface{}{}}, "team", "not an object"},
+		{"xml custom data", &events.CustomData{Data: []byte("<a/>"), ContentType: "application/xml"}, "team", "application/xml"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.customData.SetField(tc.path, "value")
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestEventCustomData(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+	event, err := factory.CreatePipelineRunEvent("started", "pipeline-1", "p", "", "", "https://ci.example.com",
+		&events.CustomData{Data: map[string]interface{}{"team": "platform"}, ContentType: events.JSONContentType})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	customData, err := events.EventCustomData(event)
+	if err != nil {
+		t.Fatalf("failed to read custom data: %v", err)
+	}
+	if err := customData.SetField("relay.zone", "a"); err != nil {
+		t.Fatalf("failed to set field: %v", err)
+	}
+	if err := events.SetEventCustomData(event, customData); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
+	}
+	data, _ := event.GetCustomData()
+	want := map[string]interface{}{"team": "platform", "relay": map[string]interface{}{"zone": "a"}}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("unexpected custom data: %#v", data)
+	}
+}
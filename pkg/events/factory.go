@@ -6,8 +6,9 @@ import (
 	"time"
 
 	"github.com/cdevents/sdk-go/pkg/api"
+	cdeventsv03 "github.com/cdevents/sdk-go/pkg/api/v03"
 	cdeventsv04 "github.com/cdevents/sdk-go/pkg/api/v04"
-	"github.com/google/uuid"
+	cdeventsv05 "github.com/cdevents/sdk-go/pkg/api/v05"
 )
 
 // CustomData represents custom data that can be added to events
@@ -20,27 +21,107 @@ type CustomData struct {
 // EventFactory creates CDEvents with common functionality
 type EventFactory struct {
 	defaultSource string
+	spec          specPackage
+	now           func() time.Time
+	newID         func() string
 }
 
-// NewEventFactory creates a new EventFactory
-func NewEventFactory(defaultSource string) *EventFactory {
-	return &EventFactory{
+// EventOption sets optional subject fields on events created by EventFactory
+type EventOption func(*eventOptions)
+
+// eventOptions holds the optional subject fields set by EventOption
+type eventOptions struct {
+	environmentID string
+	artifactID    string
+	outputType    string
+	outputFormat  string
+	chainID       string
+	links         api.EmbeddedLinksArray
+	eventID       string
+	timestamp     time.Time
+	source        string
+	subjectSource string
+}
+
+// newEventOptions applies the options to an empty eventOptions
+func newEventOptions(opts []EventOption) *eventOptions {
+	options := &eventOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithEnvironment sets the environment of test events
+func WithEnvironment(environmentID string) EventOption {
+	return func(o *eventOptions) {
+		o.environmentID = environmentID
+	}
+}
+
+// WithArtifactID sets the artifact of service events and of the artifact produced by build finished events
+func WithArtifactID(artifactID string) EventOption {
+	return func(o *eventOptions) {
+		o.artifactID = artifactID
+	}
+}
+
+// WithTestOutput sets the output type (report, video, image, log, other) and format of testoutput events
+func WithTestOutput(outputType, format string) EventOption {
+	return func(o *eventOptions) {
+		o.outputType = outputType
+		o.outputFormat = format
+	}
+}
+
+// NewEventFactory creates a new EventFactory for DefaultSpecVersion
+func NewEventFactory(defaultSource string, opts ...FactoryOption) *EventFactory {
+	spec, _ := lookupSpecPackage(DefaultSpecVersion)
+	return newEventFactory(defaultSource, spec, opts)
+}
+
+// NewEventFactoryForSpecVersion creates a new EventFactory for a spec version such as 0.3, 0.4 or 0.5
+func NewEventFactoryForSpecVersion(defaultSource, specVersion string, opts ...FactoryOption) (*EventFactory, error) {
+	spec, err := lookupSpecPackage(specVersion)
+	if err != nil {
+		return nil, err
+	}
+	return newEventFactory(defaultSource, spec, opts), nil
+}
+
+// newEventFactory creates an EventFactory for a spec package and applies the options
+func newEventFactory(defaultSource string, spec specPackage, opts []FactoryOption) *EventFactory {
+	ef := &EventFactory{
 		defaultSource: defaultSource,
+		spec:          spec,
+		now:           time.Now,
+		newID:         newUUID,
 	}
+	for _, opt := range opts {
+		opt(ef)
+	}
+	return ef
+}
+
+// SpecVersion returns the spec version of the events created by the factory
+func (ef *EventFactory) SpecVersion() string {
+	return ef.spec.version
 }
 
 // CreatePipelineRunEvent creates a pipeline run event
-func (ef *EventFactory) CreatePipelineRunEvent(eventType, pipelineID, pipelineName, outcome, errors, url string, customData *CustomData) (api.CDEvent, error) {
+func (ef *EventFactory) CreatePipelineRunEvent(eventType, pipelineID, pipelineName, outcome, errors, url string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
 	var event api.CDEvent
 	var err error
 
 	switch eventType {
 	case "queued":
-		event, err = cdeventsv04.NewPipelineRunQueuedEvent()
+		event, err = ef.spec.create("pipelinerun", "queued")
 	case "started":
-		event, err = cdeventsv04.NewPipelineRunStartedEvent()
+		event, err = ef.spec.create("pipelinerun", "started")
 	case "finished":
-		event, err = cdeventsv04.NewPipelineRunFinishedEvent()
+		event, err = ef.spec.create("pipelinerun", "finished")
 	default:
 		return nil, fmt.Errorf("unsupported pipeline run event type: %s", eventType)
 	}
@@ -50,20 +131,16 @@ func (ef *EventFactory) CreatePipelineRunEvent(eventType, pipelineID, pipelineNa
 	}
 
 	// Set common fields
-	event.SetId(uuid.New().String())
-	event.SetSource(ef.defaultSource)
-	event.SetTimestamp(time.Now())
-	event.SetSubjectId(pipelineID)
+	if err := ef.setContext(event, pipelineID, options); err != nil {
+		return nil, err
+	}
 	
 	// Set pipeline-specific fields
 	if pipelineRunEvent, ok := event.(interface {
 		SetSubjectPipelineName(string)
-		SetSubjectUrl(string)
 	}); ok {
 		pipelineRunEvent.SetSubjectPipelineName(pipelineName)
-		if url != "" {
-			pipelineRunEvent.SetSubjectUrl(url)
-		}
+		setSubjectURL(event, url)
 	}
 
 	// Set outcome and errors for finished events
@@ -83,22 +160,39 @@ func (ef *EventFactory) CreatePipelineRunEvent(eventType, pipelineID, pipelineNa
 
 	// Apply custom data if provided
 	if customData != nil {
-		ef.applyCustomData(event, customData)
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
 	}
 
 	return event, nil
 }
 
+// setSubjectURL sets the URL of a pipeline or task run, the subject field is named uri from spec version 0.5
+func setSubjectURL(event api.CDEvent, url string) {
+	if url == "" {
+		return
+	}
+	switch e := event.(type) {
+	case interface{ SetSubjectUrl(string) }:
+		e.SetSubjectUrl(url)
+	case interface{ SetSubjectUri(string) }:
+		e.SetSubjectUri(url)
+	}
+}
+
 // CreateTaskRunEvent creates a task run event
-func (ef *EventFactory) CreateTaskRunEvent(eventType, taskID, taskName, pipelineRunID, outcome, errors, url string, customData *CustomData) (api.CDEvent, error) {
+func (ef *EventFactory) CreateTaskRunEvent(eventType, taskID, taskName, pipelineRunID, outcome, errors, url string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
 	var event api.CDEvent
 	var err error
 
 	switch eventType {
 	case "started":
-		event, err = cdeventsv04.NewTaskRunStartedEvent()
+		event, err = ef.spec.create("taskrun", "started")
 	case "finished":
-		event, err = cdeventsv04.NewTaskRunFinishedEvent()
+		event, err = ef.spec.create("taskrun", "finished")
 	default:
 		return nil, fmt.Errorf("unsupported task run event type: %s", eventType)
 	}
@@ -108,25 +202,19 @@ func (ef *EventFactory) CreateTaskRunEvent(eventType, taskID, taskName, pipeline
 	}
 
 	// Set common fields
-	event.SetId(uuid.New().String())
-	event.SetSource(ef.defaultSource)
-	event.SetTimestamp(time.Now())
-	event.SetSubjectId(taskID)
+	if err := ef.setContext(event, taskID, options); err != nil {
+		return nil, err
+	}
 
 	// Set task-specific fields
 	if taskRunEvent, ok := event.(interface {
 		SetSubjectTaskName(string)
-		SetSubjectUrl(string)
-		SetSubjectPipelineRun(map[string]interface{})
+		SetSubjectPipelineRun(*api.Reference)
 	}); ok {
 		taskRunEvent.SetSubjectTaskName(taskName)
-		if url != "" {
-			taskRunEvent.SetSubjectUrl(url)
-		}
+		setSubjectURL(event, url)
 		if pipelineRunID != "" {
-			taskRunEvent.SetSubjectPipelineRun(map[string]interface{}{
-				"id": pipelineRunID,
-			})
+			taskRunEvent.SetSubjectPipelineRun(&api.Reference{Id: pipelineRunID})
 		}
 	}
 
@@ -147,24 +235,28 @@ func (ef *EventFactory) CreateTaskRunEvent(eventType, taskID, taskName, pipeline
 
 	// Apply custom data if provided
 	if customData != nil {
-		ef.applyCustomData(event, customData)
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
 	}
 
 	return event, nil
 }
 
 // CreateBuildEvent creates a build event
-func (ef *EventFactory) CreateBuildEvent(eventType, buildID, buildName, outcome, errors, url string, customData *CustomData) (api.CDEvent, error) {
+func (ef *EventFactory) CreateBuildEvent(eventType, buildID, buildName, outcome, errors, url string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
 	var event api.CDEvent
 	var err error
 
 	switch eventType {
 	case "queued":
-		event, err = cdeventsv04.NewBuildQueuedEvent()
+		event, err = ef.spec.create("build", "queued")
 	case "started":
-		event, err = cdeventsv04.NewBuildStartedEvent()
+		event, err = ef.spec.create("build", "started")
 	case "finished":
-		event, err = cdeventsv04.NewBuildFinishedEvent()
+		event, err = ef.spec.create("build", "finished")
 	default:
 		return nil, fmt.Errorf("unsupported build event type: %s", eventType)
 	}
@@ -174,10 +266,9 @@ func (ef *EventFactory) CreateBuildEvent(eventType, buildID, buildName, outcome,
 	}
 
 	// Set common fields
-	event.SetId(uuid.New().String())
-	event.SetSource(ef.defaultSource)
-	event.SetTimestamp(time.Now())
-	event.SetSubjectId(buildID)
+	if err := ef.setContext(event, buildID, options); err != nil {
+		return nil, err
+	}
 
 	// Set build-specific fields  
 	if buildEvent, ok := event.(interface {
@@ -189,6 +280,11 @@ func (ef *EventFactory) CreateBuildEvent(eventType, buildID, buildName, outcome,
 			buildEvent.SetSubjectUrl(url)
 		}
 	}
+	if artifactEvent, ok := event.(interface {
+		SetSubjectArtifactId(string)
+	}); ok && options.artifactID != "" {
+		artifactEvent.SetSubjectArtifactId(options.artifactID)
+	}
 
 	// Set outcome and errors for finished events
 	if eventType == "finished" {
@@ -207,28 +303,34 @@ func (ef *EventFactory) CreateBuildEvent(eventType, buildID, buildName, outcome,
 
 	// Apply custom data if provided
 	if customData != nil {
-		ef.applyCustomData(event, customData)
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
 	}
 
 	return event, nil
 }
 
 // CreateServiceEvent creates a service deployment event
-func (ef *EventFactory) CreateServiceEvent(eventType, serviceID, serviceName, environmentID, url string, customData *CustomData) (api.CDEvent, error) {
+// The spec has no service name or URL fields, serviceName and url are accepted for compatibility only.
+// Use WithArtifactID to set the artifact required by deployed, rolledback and upgraded events.
+func (ef *EventFactory) CreateServiceEvent(eventType, serviceID, serviceName, environmentID, url string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
 	var event api.CDEvent
 	var err error
 
 	switch eventType {
 	case "deployed":
-		event, err = cdeventsv04.NewServiceDeployedEvent()
+		event, err = ef.spec.create("service", "deployed")
 	case "published":
-		event, err = cdeventsv04.NewServicePublishedEvent()
+		event, err = ef.spec.create("service", "published")
 	case "removed":
-		event, err = cdeventsv04.NewServiceRemovedEvent()
+		event, err = ef.spec.create("service", "removed")
 	case "rolledback":
-		event, err = cdeventsv04.NewServiceRolledbackEvent()
+		event, err = ef.spec.create("service", "rolledback")
 	case "upgraded":
-		event, err = cdeventsv04.NewServiceUpgradedEvent()
+		event, err = ef.spec.create("service", "upgraded")
 	default:
 		return nil, fmt.Errorf("unsupported service event type: %s", eventType)
 	}
@@ -238,58 +340,232 @@ func (ef *EventFactory) CreateServiceEvent(eventType, serviceID, serviceName, en
 	}
 
 	// Set common fields
-	event.SetId(uuid.New().String())
-	event.SetSource(ef.defaultSource)
-	event.SetTimestamp(time.Now())
-	event.SetSubjectId(serviceID)
+	if err := ef.setContext(event, serviceID, options); err != nil {
+		return nil, err
+	}
 
 	// Set service-specific fields
 	if serviceEvent, ok := event.(interface {
-		SetSubjectServiceName(string)
-		SetSubjectUrl(string)
-		SetSubjectEnvironment(map[string]interface{})
+		SetSubjectEnvironment(*api.Reference)
+	}); ok && environmentID != "" {
+		serviceEvent.SetSubjectEnvironment(&api.Reference{Id: environmentID})
+	}
+	if artifactEvent, ok := event.(interface {
+		SetSubjectArtifactId(string)
+	}); ok && options.artifactID != "" {
+		artifactEvent.SetSubjectArtifactId(options.artifactID)
+	}
+
+	// Apply custom data if provided
+	if customData != nil {
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
+
+// CreateChangeEvent creates a change event of a repository, such as a pull request being merged
+// The description is only defined for created events of spec version 0.4.
+func (ef *EventFactory) CreateChangeEvent(eventType, changeID, repositoryID, description string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
+	switch eventType {
+	case "created", "updated", "reviewed", "merged", "abandoned":
+	default:
+		return nil, fmt.Errorf("unsupported change event type: %s", eventType)
+	}
+	event, err := ef.spec.create("change", eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create change event: %w", err)
+	}
+	if err := ef.setContext(event, changeID, options); err != nil {
+		return nil, err
+	}
+
+	if changeEvent, ok := event.(interface {
+		SetSubjectRepository(*api.Reference)
+	}); ok && repositoryID != "" {
+		changeEvent.SetSubjectRepository(&api.Reference{Id: repositoryID})
+	}
+	if changeEvent, ok := event.(interface {
+		SetSubjectDescription(string)
 	}); ok {
-		serviceEvent.SetSubjectServiceName(serviceName)
-		if url != "" {
-			serviceEvent.SetSubjectUrl(url)
+		changeEvent.SetSubjectDescription(description)
+	}
+
+	if customData != nil {
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
 		}
-		if environmentID != "" {
-			serviceEvent.SetSubjectEnvironment(map[string]interface{}{
-				"id": environmentID,
-			})
+	}
+	return event, nil
+}
+
+// CreateBranchEvent creates a branch created or deleted event of a repository
+func (ef *EventFactory) CreateBranchEvent(eventType, branchID, repositoryID string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
+	switch eventType {
+	case "created", "deleted":
+	default:
+		return nil, fmt.Errorf("unsupported branch event type: %s", eventType)
+	}
+	event, err := ef.spec.create("branch", eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch event: %w", err)
+	}
+	if err := ef.setContext(event, branchID, options); err != nil {
+		return nil, err
+	}
+
+	if branchEvent, ok := event.(interface {
+		SetSubjectRepository(*api.Reference)
+	}); ok && repositoryID != "" {
+		branchEvent.SetSubjectRepository(&api.Reference{Id: repositoryID})
+	}
+
+	if customData != nil {
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
 		}
 	}
+	return event, nil
+}
+
+// CreateArtifactEvent creates an artifact packaged or published event, artifactID is usually a PURL
+// Packaged events require the change the artifact was packaged from.
+func (ef *EventFactory) CreateArtifactEvent(eventType, artifactID, changeID string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
+	switch eventType {
+	case "packaged", "published":
+	default:
+		return nil, fmt.Errorf("unsupported artifact event type: %s", eventType)
+	}
+	event, err := ef.spec.create("artifact", eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact event: %w", err)
+	}
+	if err := ef.setContext(event, artifactID, options); err != nil {
+		return nil, err
+	}
+
+	if artifactEvent, ok := event.(interface {
+		SetSubjectChange(*api.Reference)
+	}); ok && changeID != "" {
+		artifactEvent.SetSubjectChange(&api.Reference{Id: changeID})
+	}
 
-	// Apply custom data if provided
 	if customData != nil {
-		ef.applyCustomData(event, customData)
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// Ticket holds the subject fields of ticket events
+type Ticket struct {
+	Summary    string
+	TicketType string
+	Group      string
+	Creator    string
+	UpdatedBy  string
+	Assignees  []string
+	Priority   string
+	Labels     []string
+	Milestone  string
+	// Resolution of closed tickets, such as completed, withdrawn or duplicate
+	Resolution string
+	URI        string
+}
+
+// CreateTicketEvent creates a ticket created, updated or closed event, ticket events are defined from spec version 0.4
+func (ef *EventFactory) CreateTicketEvent(eventType, ticketID string, ticket Ticket, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
+
+	var event api.CDEvent
+	var err error
+	switch eventType {
+	case "created", "updated", "closed":
+		event, err = ef.spec.create("ticket", eventType)
+	default:
+		return nil, fmt.Errorf("unsupported ticket event type: %s", eventType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ticket event: %w", err)
+	}
+	if err := ef.setContext(event, ticketID, options); err != nil {
+		return nil, err
+	}
+
+	if ticketEvent, ok := event.(interface {
+		SetSubjectSummary(string)
+		SetSubjectTicketType(string)
+		SetSubjectGroup(string)
+		SetSubjectCreator(string)
+		SetSubjectAssignees([]string)
+		SetSubjectPriority(string)
+		SetSubjectLabels([]string)
+		SetSubjectMilestone(string)
+		SetSubjectUri(string)
+	}); ok {
+		ticketEvent.SetSubjectSummary(ticket.Summary)
+		ticketEvent.SetSubjectTicketType(ticket.TicketType)
+		ticketEvent.SetSubjectGroup(ticket.Group)
+		ticketEvent.SetSubjectCreator(ticket.Creator)
+		ticketEvent.SetSubjectAssignees(ticket.Assignees)
+		ticketEvent.SetSubjectPriority(ticket.Priority)
+		ticketEvent.SetSubjectLabels(ticket.Labels)
+		ticketEvent.SetSubjectMilestone(ticket.Milestone)
+		ticketEvent.SetSubjectUri(ticket.URI)
+	}
+	if updatedEvent, ok := event.(interface {
+		SetSubjectUpdatedBy(string)
+	}); ok {
+		updatedEvent.SetSubjectUpdatedBy(ticket.UpdatedBy)
+	}
+	if closedEvent, ok := event.(interface {
+		SetSubjectResolution(string)
+	}); ok {
+		closedEvent.SetSubjectResolution(ticket.Resolution)
 	}
 
+	if customData != nil {
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
+	}
 	return event, nil
 }
 
 // CreateTestEvent creates a test event
-func (ef *EventFactory) CreateTestEvent(eventType, testID, testName, outcome, errors, url string, customData *CustomData) (api.CDEvent, error) {
+// Outcomes such as success and failure are mapped to the spec values pass and fail, errors are set as the reason.
+// Use WithEnvironment to set the environment required by test case and test suite events.
+func (ef *EventFactory) CreateTestEvent(eventType, testID, testName, outcome, errors, url string, customData *CustomData, opts ...EventOption) (api.CDEvent, error) {
+	options := newEventOptions(opts)
 	var event api.CDEvent
 	var err error
 
 	switch eventType {
 	case "testcase-queued":
-		event, err = cdeventsv04.NewTestCaseRunQueuedEvent()
+		event, err = ef.spec.create("testcaserun", "queued")
 	case "testcase-started":
-		event, err = cdeventsv04.NewTestCaseRunStartedEvent()
+		event, err = ef.spec.create("testcaserun", "started")
 	case "testcase-finished":
-		event, err = cdeventsv04.NewTestCaseRunFinishedEvent()
+		event, err = ef.spec.create("testcaserun", "finished")
 	case "testcase-skipped":
-		event, err = cdeventsv04.NewTestCaseRunSkippedEvent()
+		event, err = ef.spec.create("testcaserun", "skipped")
 	case "testsuite-queued":
-		event, err = cdeventsv04.NewTestSuiteRunQueuedEvent()
+		event, err = ef.spec.create("testsuiterun", "queued")
 	case "testsuite-started":
-		event, err = cdeventsv04.NewTestSuiteRunStartedEvent()
+		event, err = ef.spec.create("testsuiterun", "started")
 	case "testsuite-finished":
-		event, err = cdeventsv04.NewTestSuiteRunFinishedEvent()
+		event, err = ef.spec.create("testsuiterun", "finished")
 	case "testoutput-published":
-		event, err = cdeventsv04.NewTestOutputPublishedEvent()
+		event, err = ef.spec.create("testoutput", "published")
 	default:
 		return nil, fmt.Errorf("unsupported test event type: %s", eventType)
 	}
@@ -299,64 +575,145 @@ func (ef *EventFactory) CreateTestEvent(eventType, testID, testName, outcome, er
 	}
 
 	// Set common fields
-	event.SetId(uuid.New().String())
-	event.SetSource(ef.defaultSource)
-	event.SetTimestamp(time.Now())
-	event.SetSubjectId(testID)
+	if err := ef.setContext(event, testID, options); err != nil {
+		return nil, err
+	}
 
 	// Set test-specific fields based on event type
-	switch eventType {
-	case "testcase-queued", "testcase-started", "testcase-finished", "testcase-skipped":
-		if testEvent, ok := event.(interface {
-			SetSubjectTestCaseName(string)
-			SetSubjectUrl(string)
-		}); ok {
-			testEvent.SetSubjectTestCaseName(testName)
-			if url != "" {
-				testEvent.SetSubjectUrl(url)
-			}
-		}
-	case "testsuite-queued", "testsuite-started", "testsuite-finished":
-		if testEvent, ok := event.(interface {
-			SetSubjectTestSuiteName(string)
-			SetSubjectUrl(string)
-		}); ok {
-			testEvent.SetSubjectTestSuiteName(testName)
-			if url != "" {
-				testEvent.SetSubjectUrl(url)
-			}
-		}
+	var environment *api.Reference
+	if options.environmentID != "" {
+		environment = &api.Reference{Id: options.environmentID}
 	}
-
-	// Set outcome and errors for finished events
-	if eventType == "testcase-finished" || eventType == "testsuite-finished" {
-		if finishedEvent, ok := event.(interface {
-			SetSubjectOutcome(string)
-			SetSubjectErrors(string)
-		}); ok {
-			if outcome != "" {
-				finishedEvent.SetSubjectOutcome(outcome)
-			}
-			if errors != "" {
-				finishedEvent.SetSubjectErrors(errors)
-			}
-		}
+	switch e := event.(type) {
+	case *cdeventsv05.TestCaseRunQueuedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunQueuedSubjectContentTestCaseV0_3_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv05.TestCaseRunStartedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunStartedSubjectContentTestCaseV0_3_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv05.TestCaseRunFinishedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunFinishedSubjectContentTestCaseV0_3_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectOutcome(ef.spec.testOutcome(outcome))
+		e.SetSubjectReason(errors)
+	case *cdeventsv05.TestCaseRunSkippedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunSkippedSubjectContentTestCaseV0_2_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectReason(errors)
+	case *cdeventsv05.TestSuiteRunQueuedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunQueuedSubjectContentTestSuiteV0_3_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv05.TestSuiteRunStartedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunStartedSubjectContentTestSuiteV0_3_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv05.TestSuiteRunFinishedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunFinishedSubjectContentTestSuiteV0_3_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectOutcome(ef.spec.testOutcome(outcome))
+		e.SetSubjectReason(errors)
+	case *cdeventsv05.TestOutputPublishedEvent:
+		e.SetSubjectUri(url)
+		e.SetSubjectOutputType(options.outputType)
+		e.SetSubjectFormat(options.outputFormat)
+	case *cdeventsv04.TestCaseRunQueuedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunQueuedSubjectContentTestCaseV0_2_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv04.TestCaseRunStartedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunStartedSubjectContentTestCaseV0_2_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv04.TestCaseRunFinishedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunFinishedSubjectContentTestCaseV0_2_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectOutcome(ef.spec.testOutcome(outcome))
+		e.SetSubjectReason(errors)
+	case *cdeventsv04.TestCaseRunSkippedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunSkippedSubjectContentTestCaseV0_1_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectReason(errors)
+	case *cdeventsv04.TestSuiteRunQueuedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunQueuedSubjectContentTestSuiteV0_2_0{Id: testID, Name: testName, Url: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv04.TestSuiteRunStartedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunStartedSubjectContentTestSuiteV0_2_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv04.TestSuiteRunFinishedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunFinishedSubjectContentTestSuiteV0_2_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectOutcome(ef.spec.testOutcome(outcome))
+		e.SetSubjectReason(errors)
+	case *cdeventsv04.TestOutputPublishedEvent:
+		e.SetSubjectUri(url)
+		e.SetSubjectOutputType(options.outputType)
+		e.SetSubjectFormat(options.outputFormat)
+	case *cdeventsv03.TestCaseRunQueuedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunQueuedSubjectContentTestCaseV0_1_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv03.TestCaseRunStartedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunStartedSubjectContentTestCaseV0_1_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv03.TestCaseRunFinishedEvent:
+		e.SetSubjectTestCase(&api.TestCaseRunFinishedSubjectContentTestCaseV0_1_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectOutcome(ef.spec.testOutcome(outcome))
+		e.SetSubjectReason(errors)
+	case *cdeventsv03.TestSuiteRunQueuedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunQueuedSubjectContentTestSuiteV0_1_0{Id: testID, Name: testName, Url: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv03.TestSuiteRunStartedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunStartedSubjectContentTestSuiteV0_1_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+	case *cdeventsv03.TestSuiteRunFinishedEvent:
+		e.SetSubjectTestSuite(&api.TestSuiteRunFinishedSubjectContentTestSuiteV0_1_0{Id: testID, Name: testName, Uri: url})
+		e.SetSubjectEnvironment(environment)
+		e.SetSubjectOutcome(ef.spec.testOutcome(outcome))
+		e.SetSubjectReason(errors)
+	case *cdeventsv03.TestOutputPublishedEvent:
+		e.SetSubjectUri(url)
+		e.SetSubjectOutputType(options.outputType)
+		e.SetSubjectFormat(options.outputFormat)
 	}
 
 	// Apply custom data if provided
 	if customData != nil {
-		ef.applyCustomData(event, customData)
+		if err := ef.applyCustomData(event, customData); err != nil {
+			return nil, err
+		}
 	}
 
 	return event, nil
 }
 
-// applyCustomData applies custom data to a CDEvent
-// Note: The current CDEvents SDK v0.4.1 doesn't support direct custom data injection,
-// so we handle custom data in the output formatters instead.
-func (ef *EventFactory) applyCustomData(event api.CDEvent, customData *CustomData) {
-	// Custom data is now handled in the output formatters
-	// This function is kept for future SDK versions that may support direct custom data
+// testOutcome maps generic outcomes to the test outcomes defined by the spec (pass, fail, cancel, error)
+func testOutcome(outcome string) string {
+	switch outcome {
+	case "success", "succeeded", "passed":
+		return "pass"
+	case "failure", "failed":
+		return "fail"
+	case "cancelled", "canceled":
+		return "cancel"
+	default:
+		return outcome
+	}
+}
+
+// testOutcomeV05 maps generic outcomes to the test outcomes defined from spec version 0.5 (success, failure, cancel, error)
+func testOutcomeV05(outcome string) string {
+	switch outcome {
+	case "pass", "passed", "succeeded":
+		return "success"
+	case "fail", "failed":
+		return "failure"
+	case "cancelled", "canceled":
+		return "cancel"
+	default:
+		return outcome
+	}
+}
+
+// applyCustomData sets custom data on a CDEvent, so every formatter and transport sees it
+func (ef *EventFactory) applyCustomData(event api.CDEvent, customData *CustomData) error {
+	return SetEventCustomData(event, customData)
 }
 
 // ParseCustomDataFromJSON parses custom data from JSON string
@@ -373,7 +730,7 @@ func ParseCustomDataFromJSON(jsonData string) (*CustomData, error) {
 
 	return &CustomData{
 		Data: data,
-		ContentType: "application/json",
+		ContentType: JSONContentType,
 	}, nil
 }
 
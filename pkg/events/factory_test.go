@@ -2,9 +2,11 @@ package events_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/cdevents/sdk-go/pkg/api"
 )
 
 func TestValidateNewPipelineRunStartedEvent(t *testing.T) {
@@ -482,8 +484,7 @@ func TestCreateServiceEvent(t *testing.T) {
 }
 
 func TestApplyCustomDataFunction(t *testing.T) {
-	// This function doesn't do anything in the current implementation
-	// but we can test that it doesn't panic
+	// Custom data is set on the event itself, so it is part of its JSON representation
 	factory := events.NewEventFactory("test-source")
 	event, err := factory.CreatePipelineRunEvent(
 		"started",
@@ -497,17 +498,36 @@ func TestApplyCustomDataFunction(t *testing.T) {
 			ContentType: "application/json",
 		},
 	)
-
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-
-	if event == nil {
-		t.Fatalf("event should not be nil")
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	if !strings.Contains(string(data), `"customData":{"key":"value"}`) || !strings.Contains(string(data), `"customDataContentType":"application/json"`) {
+		t.Errorf("expected custom data in the event, got %s", data)
 	}
 
-	// The applyCustomData function is called internally when custom data is provided
-	// This test ensures the code path is covered
+	// Other content types are carried as base64 encoded bytes
+	event, err = factory.CreateBuildEvent("finished", "build-123", "test-build", "", "", "", &events.CustomData{
+		Data: "<build><node>linux</node></build>",
+		ContentType: "application/xml",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, err := event.GetCustomDataRaw()
+	if err != nil || string(raw) != "<build><node>linux</node></build>" {
+		t.Errorf("unexpected custom data %q: %v", raw, err)
+	}
+	data, err = json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	if !strings.Contains(string(data), `"customData":"PGJ1aWxkPjxub2RlPmxpbnV4PC9ub2RlPjwvYnVpbGQ+"`) {
+		t.Errorf("expected base64 custom data in the event, got %s", data)
+	}
 }
 
 func TestCreateTestEvent(t *testing.T) {
@@ -608,3 +628,165 @@ func TestParseCustomDataFromJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateTestEventSubjectContent(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+
+	event, err := factory.CreateTestEvent(
+		"testcase-finished",
+		"test-123",
+		"test-name",
+		"failure",
+		"assertion failed",
+		"https://example.com/test",
+		nil,
+		events.WithEnvironment("staging"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, ok := event.GetSubjectContent().(api.TestCaseRunFinishedSubjectContentV0_2_0)
+	if !ok {
+		t.Fatalf("unexpected subject content type %T", event.GetSubjectContent())
+	}
+	if content.Outcome != "fail" {
+		t.Errorf("expected outcome 'fail', got '%s'", content.Outcome)
+	}
+	if content.Reason != "assertion failed" {
+		t.Errorf("expected reason 'assertion failed', got '%s'", content.Reason)
+	}
+	if content.Environment == nil || content.Environment.Id != "staging" {
+		t.Errorf("expected environment 'staging', got %v", content.Environment)
+	}
+	if content.TestCase == nil || content.TestCase.Name != "test-name" || content.TestCase.Uri != "https://example.com/test" {
+		t.Errorf("expected test case with name and uri, got %v", content.TestCase)
+	}
+}
+
+func TestCreateServiceEventSubjectContent(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+
+	event, err := factory.CreateServiceEvent(
+		"deployed",
+		"service-123",
+		"test-service",
+		"env-123",
+		"",
+		nil,
+		events.WithArtifactID("pkg:oci/test-service@v1"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, ok := event.GetSubjectContent().(api.ServiceDeployedSubjectContentV0_2_0)
+	if !ok {
+		t.Fatalf("unexpected subject content type %T", event.GetSubjectContent())
+	}
+	if content.Environment == nil || content.Environment.Id != "env-123" {
+		t.Errorf("expected environment 'env-123', got %v", content.Environment)
+	}
+	if content.ArtifactId != "pkg:oci/test-service@v1" {
+		t.Errorf("expected artifact 'pkg:oci/test-service@v1', got '%s'", content.ArtifactId)
+	}
+}
+
+func TestCreateBuildEventWithArtifactID(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+	event, err := factory.CreateBuildEvent("finished", "build-123", "test-build", "", "", "", nil,
+		events.WithArtifactID("pkg:oci/app@v1.0.0"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Build finished events need the artifact they produced to be rendered as CloudEvents
+	if _, err := api.AsCloudEvent(event); err != nil {
+		t.Errorf("expected a valid CloudEvent, got %v", err)
+	}
+}
+
+func TestCreateSourceCodeEvents(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+
+	testCases := []struct {
+		name      string
+		create    func() (api.CDEvent, error)
+		eventType string
+		shouldErr bool
+	}{
+		{"change merged", func() (api.CDEvent, error) {
+			return factory.CreateChangeEvent("merged", "42", "org/repo", "", nil)
+		}, "dev.cdevents.change.merged.", false},
+		{"invalid change", func() (api.CDEvent, error) {
+			return factory.CreateChangeEvent("closed", "42", "org/repo", "", nil)
+		}, "", true},
+		{"branch deleted", func() (api.CDEvent, error) {
+			return factory.CreateBranchEvent("deleted", "feature", "org/repo", nil)
+		}, "dev.cdevents.branch.deleted.", false},
+		{"invalid branch", func() (api.CDEvent, error) {
+			return factory.CreateBranchEvent("merged", "feature", "org/repo", nil)
+		}, "", true},
+		{"artifact packaged", func() (api.CDEvent, error) {
+			return factory.CreateArtifactEvent("packaged", "pkg:oci/app@v1", "42", nil)
+		}, "dev.cdevents.artifact.packaged.", false},
+		{"invalid artifact", func() (api.CDEvent, error) {
+			return factory.CreateArtifactEvent("deleted", "pkg:oci/app@v1", "", nil)
+		}, "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := tc.create()
+			if tc.shouldErr {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(event.GetType().String(), tc.eventType) {
+				t.Errorf("expected type %s, got %s", tc.eventType, event.GetType())
+			}
+			if _, err := api.AsCloudEvent(event); err != nil {
+				t.Errorf("expected a valid CloudEvent, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateTicketEvent(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+	ticket := events.Ticket{
+		Summary:    "Crash on start",
+		Creator:    "alice",
+		UpdatedBy:  "bob",
+		Labels:     []string{"bug"},
+		Resolution: "duplicate",
+		URI:        "https://tickets.example.com/7",
+	}
+
+	event, err := factory.CreateTicketEvent("closed", "7", ticket, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, ok := event.GetSubjectContent().(api.TicketClosedSubjectContentV0_1_0)
+	if !ok {
+		t.Fatalf("unexpected subject content type %T", event.GetSubjectContent())
+	}
+	if content.Resolution != "duplicate" || content.UpdatedBy != "bob" || content.Uri != "https://tickets.example.com/7" {
+		t.Errorf("unexpected subject content %+v", content)
+	}
+
+	if _, err := factory.CreateTicketEvent("resolved", "7", ticket, nil); err == nil {
+		t.Error("expected error for an unsupported ticket event type")
+	}
+	factory03, err := events.NewEventFactoryForSpecVersion("test-source", "0.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := factory03.CreateTicketEvent("created", "7", ticket, nil); err == nil {
+		t.Error("expected error for ticket events with spec version 0.3")
+	}
+}
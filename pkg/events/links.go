@@ -0,0 +1,62 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+// DefaultChainIDEnv is the environment variable the CLI reads the chain ID of a delivery flow from
+const DefaultChainIDEnv = "CDEVENTS_CHAIN_ID"
+
+// WithChainID sets the chain ID that correlates the events of a delivery flow
+func WithChainID(chainID string) EventOption {
+	return func(o *eventOptions) {
+		o.chainID = chainID
+	}
+}
+
+// WithPathLink adds a PATH link from the event that directly preceded the event
+func WithPathLink(fromEventID string) EventOption {
+	return func(o *eventOptions) {
+		link := api.NewEmbeddedLinkPath()
+		link.SetFrom(api.EventReference{ContextId: fromEventID})
+		// The schema rejects the null tags the SDK serializes by default
+		link.SetTags(api.Tags{})
+		o.links = append(o.links, link)
+	}
+}
+
+// WithRelationLink adds a RELATION link of the given kind, e.g. caused-by, to another event
+func WithRelationLink(targetEventID, kind string) EventOption {
+	return func(o *eventOptions) {
+		link := api.NewEmbeddedLinkRelation()
+		link.SetTarget(api.EventReference{ContextId: targetEventID})
+		link.SetLinkKind(kind)
+		link.SetTags(api.Tags{})
+		o.links = append(o.links, link)
+	}
+}
+
+// SupportsLinks reports whether the events created by the factory can carry chain IDs and links
+func (ef *EventFactory) SupportsLinks() bool {
+	return ef.spec.links
+}
+
+// applyLinks sets the chain ID and links of the options on an event
+func applyLinks(event api.CDEvent, options *eventOptions) error {
+	if options.chainID == "" && len(options.links) == 0 {
+		return nil
+	}
+	linkedEvent, ok := event.(api.CDEventWriterV04)
+	if !ok {
+		return fmt.Errorf("chain IDs and links require spec version 0.4 or later, got %s", event.GetVersion())
+	}
+	if options.chainID != "" {
+		linkedEvent.SetChainId(options.chainID)
+	}
+	if len(options.links) > 0 {
+		linkedEvent.SetLinks(options.links)
+	}
+	return nil
+}
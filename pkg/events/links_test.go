@@ -0,0 +1,66 @@
+package events_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+func TestEventLinks(t *testing.T) {
+	factory := events.NewEventFactory("https://ci.example.com")
+	event, err := factory.CreateTaskRunEvent("started", "task-1", "build", "pipeline-1", "", "", "", nil,
+		events.WithChainID("chain-123"),
+		events.WithPathLink("event-1"),
+		events.WithRelationLink("event-0", "caused-by"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	reader, ok := event.(api.CDEventReaderV04)
+	if !ok {
+		t.Fatalf("expected a v0.4 event, got %T", event)
+	}
+	if reader.GetChainId() != "chain-123" {
+		t.Errorf("expected chain ID chain-123, got %q", reader.GetChainId())
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	for _, want := range []string{
+		`"chainId":"chain-123"`,
+		`"linkType":"PATH","from":{"contextId":"event-1"}`,
+		`"linkKind":"caused-by"`,
+		`"target":{"contextId":"event-0"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("event should contain %s, got %s", want, data)
+		}
+	}
+
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	if err := validator.ValidateEvent(event); err != nil {
+		t.Errorf("linked event should match its schema: %v", err)
+	}
+
+	// Links were introduced in spec version 0.4
+	v03, err := events.NewEventFactoryForSpecVersion("https://ci.example.com", "0.3")
+	if err != nil {
+		t.Fatalf("failed to create factory: %v", err)
+	}
+	if v03.SupportsLinks() {
+		t.Errorf("v0.3 events should not support links")
+	}
+	if _, err := v03.CreatePipelineRunEvent("started", "pipeline-1", "p", "", "", "", nil, events.WithChainID("chain-123")); err == nil {
+		t.Errorf("expected an error for a v0.3 event with a chain ID")
+	}
+}
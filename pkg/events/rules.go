@@ -0,0 +1,266 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+// FinishedOutcomes lists the outcomes of finished pipeline run and task run events
+var FinishedOutcomes = []string{"success", "failure", "error", "cancel"}
+
+// DefaultClockSkew is how far in the future event timestamps may be before they are reported
+const DefaultClockSkew = 5 * time.Minute
+
+// Violation describes an event that breaks a semantic rule
+type Violation struct {
+	// Rule is the name of the broken rule
+	Rule string `json:"rule"`
+	// Path is the JSON pointer of the offending value within the event
+	Path string `json:"path"`
+	// Message describes the violation
+	Message string `json:"message"`
+}
+
+// String returns the violation as "path: message (rule)"
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s (%s)", path, v.Message, v.Rule)
+}
+
+// Rule checks an event for a mistake the JSON schemas cannot express
+type Rule struct {
+	// Name identifies the rule when enabling or disabling it
+	Name string
+	// Description explains what the rule checks
+	Description string
+	// Subjects lists the event subjects the rule applies to, e.g. pipelinerun, or all subjects when empty
+	Subjects []string
+	// Check returns the violations found in an event map, now is the time the event is checked at
+	Check func(event map[string]interface{}, now time.Time) []Violation
+}
+
+// RuleSet evaluates enabled semantic rules against events
+type RuleSet struct {
+	rules    []Rule
+	disabled map[string]bool
+	now      func() time.Time
+}
+
+// NewRuleSet creates a RuleSet with all built-in rules enabled
+func NewRuleSet() *RuleSet {
+	return &RuleSet{
+		rules:    builtinRules(),
+		disabled: map[string]bool{},
+		now:      time.Now,
+	}
+}
+
+// SetClock sets the clock used by time based rules
+func (rs *RuleSet) SetClock(now func() time.Time) {
+	rs.now = now
+}
+
+// Rules returns all rules of the set, enabled or not
+func (rs *RuleSet) Rules() []Rule {
+	return append([]Rule(nil), rs.rules...)
+}
+
+// Enabled reports whether the named rule is enabled
+func (rs *RuleSet) Enabled(name string) bool {
+	return !rs.disabled[name]
+}
+
+// Disable disables the named rules
+func (rs *RuleSet) Disable(names ...string) error {
+	return rs.setDisabled(names, true)
+}
+
+// Enable enables the named rules
+func (rs *RuleSet) Enable(names ...string) error {
+	return rs.setDisabled(names, false)
+}
+
+func (rs *RuleSet) setDisabled(names []string, disabled bool) error {
+	for _, name := range names {
+		if !rs.hasRule(name) {
+			return fmt.Errorf("unknown rule: %s", name)
+		}
+		rs.disabled[name] = disabled
+	}
+	return nil
+}
+
+func (rs *RuleSet) hasRule(name string) bool {
+	for _, rule := range rs.rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEvent returns the violations of the enabled rules found in an event
+func (rs *RuleSet) CheckEvent(event api.CDEvent) ([]Violation, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	var eventMap map[string]interface{}
+	if err := json.Unmarshal(data, &eventMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return rs.Check(eventMap), nil
+}
+
+// Check returns the violations of the enabled rules found in an event map
+func (rs *RuleSet) Check(eventMap map[string]interface{}) []Violation {
+	subject, _ := eventTypeParts(eventMap)
+	now := rs.now()
+
+	var violations []Violation
+	for _, rule := range rs.rules {
+		if rs.disabled[rule.Name] || !appliesTo(rule, subject) {
+			continue
+		}
+		for _, violation := range rule.Check(eventMap, now) {
+			violation.Rule = rule.Name
+			violations = append(violations, violation)
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+	return violations
+}
+
+// appliesTo reports whether a rule applies to events of a subject
+func appliesTo(rule Rule, subject string) bool {
+	if len(rule.Subjects) == 0 {
+		return true
+	}
+	for _, s := range rule.Subjects {
+		if s == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTypeParts returns the subject and predicate of an event, e.g. pipelinerun and finished
+func eventTypeParts(eventMap map[string]interface{}) (string, string) {
+	context, _ := eventMap["context"].(map[string]interface{})
+	eventType, _ := context["type"].(string)
+	parts := strings.Split(eventType, ".")
+	if len(parts) < 4 {
+		return "", ""
+	}
+	return parts[2], parts[3]
+}
+
+// subjectContent returns the subject content of an event map
+func subjectContent(eventMap map[string]interface{}) map[string]interface{} {
+	subject, _ := eventMap["subject"].(map[string]interface{})
+	content, _ := subject["content"].(map[string]interface{})
+	return content
+}
+
+// builtinRules returns the rules every RuleSet starts with
+func builtinRules() []Rule {
+	return []Rule{
+		{
+			Name:        "finished-outcome",
+			Description: "Finished pipeline run and task run events must use one of the outcomes success, failure, error or cancel",
+			Subjects:    []string{"pipelinerun", "taskrun"},
+			Check: func(event map[string]interface{}, now time.Time) []Violation {
+				if _, predicate := eventTypeParts(event); predicate != "finished" {
+					return nil
+				}
+				outcome, ok := subjectContent(event)["outcome"].(string)
+				if !ok || outcome == "" || contains(FinishedOutcomes, outcome) {
+					return nil
+				}
+				return []Violation{{
+					Path:    "/subject/content/outcome",
+					Message: fmt.Sprintf("outcome %q must be one of %s", outcome, strings.Join(FinishedOutcomes, ", ")),
+				}}
+			},
+		},
+		{
+			Name:        "outcome-errors",
+			Description: "Finished events reporting errors must not have a successful outcome",
+			Subjects:    []string{"pipelinerun", "taskrun", "testcaserun", "testsuiterun"},
+			Check: func(event map[string]interface{}, now time.Time) []Violation {
+				if _, predicate := eventTypeParts(event); predicate != "finished" {
+					return nil
+				}
+				content := subjectContent(event)
+				outcome, _ := content["outcome"].(string)
+				if outcome != "success" && outcome != "pass" {
+					return nil
+				}
+				for _, field := range []string{"errors", "reason"} {
+					if details, _ := content[field].(string); details != "" {
+						return []Violation{{
+							Path:    "/subject/content/" + field,
+							Message: fmt.Sprintf("event reports %s but its outcome is %q", field, outcome),
+						}}
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "taskrun-pipelinerun",
+			Description: "Task run events referencing a pipeline run must carry its ID",
+			Subjects:    []string{"taskrun"},
+			Check: func(event map[string]interface{}, now time.Time) []Violation {
+				pipelineRun, ok := subjectContent(event)["pipelineRun"]
+				if !ok || pipelineRun == nil {
+					return nil
+				}
+				reference, _ := pipelineRun.(map[string]interface{})
+				if id, _ := reference["id"].(string); strings.TrimSpace(id) == "" {
+					return []Violation{{
+						Path:    "/subject/content/pipelineRun/id",
+						Message: "pipeline run reference has an empty ID",
+					}}
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "timestamp-not-in-future",
+			Description: "Event timestamps must not be in the future, allowing for DefaultClockSkew",
+			Check: func(event map[string]interface{}, now time.Time) []Violation {
+				context, _ := event["context"].(map[string]interface{})
+				value, _ := context["timestamp"].(string)
+				timestamp, err := time.Parse(time.RFC3339Nano, value)
+				if err != nil || !timestamp.After(now.Add(DefaultClockSkew)) {
+					return nil
+				}
+				return []Violation{{
+					Path:    "/context/timestamp",
+					Message: fmt.Sprintf("timestamp %s is in the future", value),
+				}}
+			},
+		},
+	}
+}
+
+// contains reports whether a value is in a list
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package events_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+)
+
+func finishedEventMap(eventType string, content map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"context": map[string]interface{}{
+			"type":      eventType,
+			"timestamp": "2024-01-01T00:00:00Z",
+		},
+		"subject": map[string]interface{}{
+			"id":      "subject-123",
+			"content": content,
+		},
+	}
+}
+
+func TestRuleSetBuiltinRules(t *testing.T) {
+	ruleSet := events.NewRuleSet()
+	ruleSet.SetClock(func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) })
+
+	testCases := []struct {
+		name  string
+		event map[string]interface{}
+		rule  string
+		path  string
+	}{
+		{
+			name:  "pipeline finished with unknown outcome",
+			event: finishedEventMap("dev.cdevents.pipelinerun.finished.0.2.0", map[string]interface{}{"outcome": "ok"}),
+			rule:  "finished-outcome",
+			path:  "/subject/content/outcome",
+		},
+		{
+			name:  "test finished with errors but passing",
+			event: finishedEventMap("dev.cdevents.testcaserun.finished.0.2.0", map[string]interface{}{"outcome": "pass", "reason": "assertion failed"}),
+			rule:  "outcome-errors",
+			path:  "/subject/content/reason",
+		},
+		{
+			name:  "task run with empty pipeline run id",
+			event: finishedEventMap("dev.cdevents.taskrun.started.0.2.0", map[string]interface{}{"pipelineRun": map[string]interface{}{"id": ""}}),
+			rule:  "taskrun-pipelinerun",
+			path:  "/subject/content/pipelineRun/id",
+		},
+		{
+			name: "timestamp in the future",
+			event: map[string]interface{}{
+				"context": map[string]interface{}{
+					"type":      "dev.cdevents.build.started.0.2.0",
+					"timestamp": "2024-01-01T01:00:00Z",
+				},
+			},
+			rule: "timestamp-not-in-future",
+			path: "/context/timestamp",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := ruleSet.Check(tc.event)
+			if len(violations) != 1 {
+				t.Fatalf("expected 1 violation, got %v", violations)
+			}
+			if violations[0].Rule != tc.rule || violations[0].Path != tc.path {
+				t.Errorf("expected violation of %s at %s, got %v", tc.rule, tc.path, violations[0])
+			}
+			if !strings.Contains(violations[0].String(), tc.rule) {
+				t.Errorf("violation string should name the rule, got %s", violations[0].String())
+			}
+		})
+	}
+}
+
+func TestRuleSetValidEvents(t *testing.T) {
+	ruleSet := events.NewRuleSet()
+	factory := events.NewEventFactory("test-source")
+
+	event, err := factory.CreatePipelineRunEvent("finished", "pipeline-123", "test-pipeline", "failure", "build failed", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	violations, err := ruleSet.CheckEvent(event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+}
+
+func TestRuleSetDisable(t *testing.T) {
+	ruleSet := events.NewRuleSet()
+	event := finishedEventMap("dev.cdevents.pipelinerun.finished.0.2.0", map[string]interface{}{"outcome": "success", "errors": "flaky step"})
+
+	if violations := ruleSet.Check(event); len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", violations)
+	}
+
+	if err := ruleSet.Disable("outcome-errors"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ruleSet.Enabled("outcome-errors") {
+		t.Errorf("rule should be disabled")
+	}
+	if violations := ruleSet.Check(event); len(violations) != 0 {
+		t.Errorf("disabled rule should not report violations, got %v", violations)
+	}
+
+	if err := ruleSet.Enable("outcome-errors"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if violations := ruleSet.Check(event); len(violations) != 1 {
+		t.Errorf("re-enabled rule should report violations, got %v", violations)
+	}
+
+	if err := ruleSet.Disable("no-such-rule"); err == nil {
+		t.Errorf("expected an error for an unknown rule")
+	}
+	if len(ruleSet.Rules()) == 0 {
+		t.Errorf("rule set should list its rules")
+	}
+}
+
+func TestUnknownOutcomeIsReportedByRule(t *testing.T) {
+	// Outcomes are checked by the finished-outcome rule, so that disabling it allows other outcomes
+	event, err := events.NewEventFactory("test-source").CreatePipelineRunEvent("finished", "pipeline-123", "test-pipeline", "done", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	ruleSet := events.NewRuleSet()
+	if violations, err := ruleSet.CheckEvent(event); err != nil || len(violations) != 1 {
+		t.Fatalf("expected the unknown outcome to be reported, got %v, %v", violations, err)
+	}
+	if err := ruleSet.Disable("finished-outcome"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if violations, err := ruleSet.CheckEvent(event); err != nil || len(violations) != 0 {
+		t.Errorf("disabled rule should not report the outcome, got %v, %v", violations, err)
+	}
+}
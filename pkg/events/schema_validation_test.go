@@ -2,11 +2,13 @@ package events_test
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/brunseba/cdevents-tools/pkg/events"
-	"github.com/xeipuuv/gojsonschema"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/cdevents/sdk-go/pkg/api"
 )
 
 // TestValidatePipelineRunStartedEvent validates a PipelineRunStarted event against its JSON schema
@@ -77,20 +79,53 @@ func TestValidateTaskRunFinishedEvent(t *testing.T) {
 
 // TestValidateTestCaseRunStartedEvent validates a TestCaseRunStarted event against its JSON schema
 func TestValidateTestCaseRunStartedEvent(t *testing.T) {
-	// Skip this test for now as it requires environment object which isn't supported in current factory
-	t.Skip("Test case events require environment object which isn't supported in current factory")
+	factory := events.NewEventFactory("https://test-source.example.com")
+	event, err := factory.CreateTestEvent("testcase-started", "test-123", "test-case", "", "", "https://example.com/test", nil, events.WithEnvironment("staging"))
+	if err != nil {
+		t.Fatalf("Failed to create test case run started event: %v", err)
+	}
+
+	validateEventAgainstSchema(t, event, "testcaserunstarted.json")
 }
 
 // TestValidateTestCaseRunFinishedEvent validates a TestCaseRunFinished event against its JSON schema
 func TestValidateTestCaseRunFinishedEvent(t *testing.T) {
-	// Skip this test for now as it requires environment object which isn't supported in current factory
-	t.Skip("Test case events require environment object which isn't supported in current factory")
+	factory := events.NewEventFactory("https://test-source.example.com")
+	event, err := factory.CreateTestEvent("testcase-finished", "test-123", "test-case", "success", "", "https://example.com/test", nil, events.WithEnvironment("staging"))
+	if err != nil {
+		t.Fatalf("Failed to create test case run finished event: %v", err)
+	}
+
+	validateEventAgainstSchema(t, event, "testcaserunfinished.json")
 }
 
 // TestValidateServiceDeployedEvent validates a ServiceDeployed event against its JSON schema
 func TestValidateServiceDeployedEvent(t *testing.T) {
-	// Skip this test for now as it requires environment object which isn't supported in current factory
-	t.Skip("Service events require environment object which isn't supported in current factory")
+	factory := events.NewEventFactory("https://test-source.example.com")
+	event, err := factory.CreateServiceEvent("deployed", "service-123", "test-service", "prod", "", nil, events.WithArtifactID("pkg:oci/my-service@sha256%3A0b31b1c02ff458ad9b7b81cbdf8f028bd54699fa151f221d1e8de6817db93427"))
+	if err != nil {
+		t.Fatalf("Failed to create service deployed event: %v", err)
+	}
+
+	validateEventAgainstSchema(t, event, "servicedeployed.json")
+}
+
+// TestValidateTestEventWithoutEnvironment verifies missing required fields are reported as JSON pointers
+func TestValidateTestEventWithoutEnvironment(t *testing.T) {
+	factory := events.NewEventFactory("https://test-source.example.com")
+	event, err := factory.CreateTestEvent("testcase-queued", "test-123", "test-case", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create test case run queued event: %v", err)
+	}
+
+	err = validateEvent(t, event)
+	var validationErr *validation.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "/subject/content/environment") {
+		t.Errorf("validation error should point to the environment, got: %v", err)
+	}
 }
 
 // TestValidateEventWithCustomData validates an event with custom data against its JSON schema
@@ -121,35 +156,27 @@ func TestValidateEventWithCustomData(t *testing.T) {
 }
 
 // validateEventAgainstSchema is a helper function that validates an event against its JSON schema
-func validateEventAgainstSchema(t *testing.T, event interface{}, schemaFile string) {
+func validateEventAgainstSchema(t *testing.T, event api.CDEvent, schemaFile string) {
 	t.Helper()
 
-	// Marshal the event to JSON
-	eventBytes, err := json.Marshal(event)
-	if err != nil {
-		t.Fatalf("Failed to marshal event to JSON: %v", err)
+	if err := validateEvent(t, event); err != nil {
+		t.Errorf("Event does not match schema %s: %v", schemaFile, err)
+
+		// Print the event JSON for debugging
+		eventBytes, _ := json.Marshal(event)
+		t.Logf("Event JSON: %s", string(eventBytes))
 	}
+}
 
-	// Load the schema from CDEvents spec repository
-	schemaURL := fmt.Sprintf("https://raw.githubusercontent.com/cdevents/spec/v0.4.1/schemas/%s", schemaFile)
-	schemaLoader := gojsonschema.NewReferenceLoader(schemaURL)
-	documentLoader := gojsonschema.NewBytesLoader(eventBytes)
+// validateEvent validates an event with the embedded schemas
+func validateEvent(t *testing.T, event api.CDEvent) error {
+	t.Helper()
 
-	// Validate the event against the schema
-	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	validator, err := validation.Default()
 	if err != nil {
-		t.Fatalf("Schema validation error: %v", err)
-	}
-
-	if !result.Valid() {
-		t.Errorf("Event does not match schema %s:", schemaFile)
-		for _, desc := range result.Errors() {
-			t.Errorf("- %s", desc)
-		}
-		
-		// Print the event JSON for debugging
-		t.Logf("Event JSON: %s", string(eventBytes))
+		t.Fatalf("Failed to load schemas: %v", err)
 	}
+	return validator.ValidateEvent(event)
 }
 
 // TestAllSchemaFilesExist verifies that all expected schema files exist
@@ -178,10 +205,21 @@ func TestAllSchemaFilesExist(t *testing.T) {
 		"serviceupgraded.json",
 	}
 
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("Failed to load schemas: %v", err)
+	}
+
+	// pipelinerunstarted.json holds the schema of dev.cdevents.pipelinerun.started.<version>
+	available := map[string]bool{}
+	for _, eventType := range validator.EventTypes() {
+		if parts := strings.Split(eventType, "."); len(parts) > 3 {
+			available[parts[2]+parts[3]+".json"] = true
+		}
+	}
 	for _, schema := range expectedSchemas {
-		schemaURL := fmt.Sprintf("https://raw.githubusercontent.com/cdevents/spec/v0.4.1/schemas/%s", schema)
-		if _, err := gojsonschema.NewReferenceLoader(schemaURL).LoadJSON(); err != nil {
-			t.Errorf("Schema file %s not found or invalid: %v", schema, err)
+		if !available[schema] {
+			t.Errorf("Schema file %s not found", schema)
 		}
 	}
 }
@@ -193,13 +231,13 @@ func TestValidateMultipleEventTypes(t *testing.T) {
 	testCases := []struct {
 		name       string
 		eventType  string
-		createFunc func() (interface{}, error)
+		createFunc func() (api.CDEvent, error)
 		schemaFile string
 	}{
 		{
 			name:      "PipelineRunQueued",
 			eventType: "pipeline-queued",
-			createFunc: func() (interface{}, error) {
+			createFunc: func() (api.CDEvent, error) {
 				return factory.CreatePipelineRunEvent("queued", "pipeline-123", "test-pipeline", "", "", "", nil)
 			},
 			schemaFile: "pipelinerunqueued.json",
@@ -207,28 +245,75 @@ func TestValidateMultipleEventTypes(t *testing.T) {
 		{
 			name:      "BuildQueued",
 			eventType: "build-queued",
-			createFunc: func() (interface{}, error) {
+			createFunc: func() (api.CDEvent, error) {
 				return factory.CreateBuildEvent("queued", "build-123", "test-build", "", "", "", nil)
 			},
 			schemaFile: "buildqueued.json",
 		},
-		// Skip test case events for now as they require environment object
-		// {
-		// 	name:      "TestCaseRunQueued",
-		// 	eventType: "testcase-queued",
-		// 	createFunc: func() (interface{}, error) {
-		// 		return factory.CreateTestEvent("testcase-queued", "test-123", "test-case-name", "", "", "", nil)
-		// 	},
-		// 	schemaFile: "testcaserunqueued.json",
-		// },
-		// {
-		// 	name:      "TestCaseRunSkipped",
-		// 	eventType: "testcase-skipped",
-		// 	createFunc: func() (interface{}, error) {
-		// 		return factory.CreateTestEvent("testcase-skipped", "test-123", "test-case-name", "", "", "", nil)
-		// 	},
-		// 	schemaFile: "testcaserunskipped.json",
-		// },
+		{
+			name:      "TestCaseRunQueued",
+			eventType: "testcase-queued",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateTestEvent("testcase-queued", "test-123", "test-case-name", "", "", "", nil, events.WithEnvironment("staging"))
+			},
+			schemaFile: "testcaserunqueued.json",
+		},
+		{
+			name:      "TestCaseRunSkipped",
+			eventType: "testcase-skipped",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateTestEvent("testcase-skipped", "test-123", "test-case-name", "", "", "", nil)
+			},
+			schemaFile: "testcaserunskipped.json",
+		},
+		{
+			name:      "TestSuiteRunQueued",
+			eventType: "testsuite-queued",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateTestEvent("testsuite-queued", "suite-123", "test-suite-name", "", "", "https://example.com/suite", nil, events.WithEnvironment("staging"))
+			},
+			schemaFile: "testsuiterunqueued.json",
+		},
+		{
+			name:      "TestSuiteRunStarted",
+			eventType: "testsuite-started",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateTestEvent("testsuite-started", "suite-123", "test-suite-name", "", "", "", nil, events.WithEnvironment("staging"))
+			},
+			schemaFile: "testsuiterunstarted.json",
+		},
+		{
+			name:      "TestSuiteRunFinished",
+			eventType: "testsuite-finished",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateTestEvent("testsuite-finished", "suite-123", "test-suite-name", "failure", "2 tests failed", "", nil, events.WithEnvironment("staging"))
+			},
+			schemaFile: "testsuiterunfinished.json",
+		},
+		{
+			name:      "TestOutputPublished",
+			eventType: "testoutput-published",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateTestEvent("testoutput-published", "output-123", "test-report", "", "", "https://example.com/report.xml", nil, events.WithTestOutput("report", "application/xml"))
+			},
+			schemaFile: "testoutputpublished.json",
+		},
+		{
+			name:      "ServicePublished",
+			eventType: "service-published",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateServiceEvent("published", "service-123", "test-service", "prod", "", nil)
+			},
+			schemaFile: "servicepublished.json",
+		},
+		{
+			name:      "TaskRunFinished",
+			eventType: "task-finished",
+			createFunc: func() (api.CDEvent, error) {
+				return factory.CreateTaskRunEvent("finished", "task-123", "test-task", "pipeline-456", "success", "", "", nil)
+			},
+			schemaFile: "taskrunfinished.json",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	cdeventsv03 "github.com/cdevents/sdk-go/pkg/api/v03"
+	cdeventsv04 "github.com/cdevents/sdk-go/pkg/api/v04"
+	cdeventsv05 "github.com/cdevents/sdk-go/pkg/api/v05"
+)
+
+// DefaultSpecVersion is the CDEvents spec version used when none is selected
+var DefaultSpecVersion = cdeventsv04.SpecVersion
+
+// specPackage is the SDK API package implementing a CDEvents spec version
+type specPackage struct {
+	version string
+	// versionField is the context field holding the spec version, specversion from spec version 0.5
+	versionField string
+	// types maps event types without version, e.g. dev.cdevents.pipelinerun.queued, to versioned types
+	types    map[string]string
+	newEvent func(eventType, specVersion string) (api.CDEvent, error)
+	// links reports whether events of the spec version carry chain IDs and links
+	links bool
+	// newCustomEvent creates custom dev.cdeventsx events, nil when the spec version has none
+	newCustomEvent func() (customEvent, error)
+	// testOutcome maps generic outcomes to the test outcomes of the spec version
+	testOutcome func(string) string
+}
+
+// customEvent is a custom dev.cdeventsx event, whose type and subject content are set by the producer
+type customEvent interface {
+	api.CDEvent
+	api.CustomCDEventWriter
+}
+
+// specPackages lists the spec versions implemented by the bundled SDK, oldest first
+var specPackages = []specPackage{
+	{cdeventsv03.SpecVersion, "version", versionedTypes(cdeventsv03.CDEventsByUnversionedTypes), cdeventsv03.NewCDEvent, false, nil, testOutcome},
+	{cdeventsv04.SpecVersion, "version", versionedTypes(cdeventsv04.CDEventsByUnversionedTypes), cdeventsv04.NewCDEvent, true, newCustomEventV04, testOutcome},
+	{cdeventsv05.SpecVersion, "specversion", versionedTypes(cdeventsv05.CDEventsByUnversionedTypes), cdeventsv05.NewCDEvent, true, newCustomEventV05, testOutcomeV05},
+}
+
+// newCustomEventV04 creates a custom event of spec version 0.4
+func newCustomEventV04() (customEvent, error) {
+	event, err := cdeventsv04.NewCustomTypeEvent()
+	if err != nil {
+		return nil, err
+	}
+	return &customTypeEventV04{event}, nil
+}
+
+// customTypeEventV04 is a custom event of spec version 0.4 reporting its own type.
+// The SDK reports the same undefined type for all custom events, which would end up in CloudEvents.
+type customTypeEventV04 struct {
+	*cdeventsv04.CustomTypeEvent
+}
+
+// GetType returns the type set on the event
+func (e *customTypeEventV04) GetType() api.CDEventType {
+	return e.Context.Type
+}
+
+// newCustomEventV05 creates a custom event of spec version 0.5
+func newCustomEventV05() (customEvent, error) {
+	event, err := cdeventsv05.NewCustomTypeEvent()
+	if err != nil {
+		return nil, err
+	}
+	return &customTypeEventV05{event}, nil
+}
+
+// customTypeEventV05 is a custom event of spec version 0.5 reporting its own type, see customTypeEventV04
+type customTypeEventV05 struct {
+	*cdeventsv05.CustomTypeEvent
+}
+
+// GetType returns the type set on the event
+func (e *customTypeEventV05) GetType() api.CDEventType {
+	return e.Context.Type
+}
+
+// ContextVersion returns the spec version of a decoded event context.
+// Spec version 0.5 renamed the version field of the context to specversion.
+func ContextVersion(context map[string]interface{}) string {
+	if version, ok := context["specversion"].(string); ok {
+		return version
+	}
+	version, _ := context["version"].(string)
+	return version
+}
+
+// versionedTypes maps the unversioned event types of an SDK API package to their versioned types
+func versionedTypes[E api.CDEventReader](byUnversionedType map[string]E) map[string]string {
+	types := make(map[string]string, len(byUnversionedType))
+	for unversioned, event := range byUnversionedType {
+		types[unversioned] = event.GetType().String()
+	}
+	return types
+}
+
+// SpecVersions returns the supported CDEvents spec versions, oldest first
+func SpecVersions() []string {
+	versions := make([]string, 0, len(specPackages))
+	for _, pkg := range specPackages {
+		versions = append(versions, pkg.version)
+	}
+	return versions
+}
+
+// ResolveSpecVersion returns the supported spec version matching a version such as 0.3, v0.4 or 0.5.0.
+// Versions are matched on major and minor version, an empty version selects DefaultSpecVersion.
+func ResolveSpecVersion(version string) (string, error) {
+	pkg, err := lookupSpecPackage(version)
+	if err != nil {
+		return "", err
+	}
+	return pkg.version, nil
+}
+
+// lookupSpecPackage returns the SDK API package implementing a spec version
+func lookupSpecPackage(version string) (specPackage, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		version = DefaultSpecVersion
+	}
+	for _, pkg := range specPackages {
+		if majorMinor(pkg.version) == majorMinor(version) {
+			return pkg, nil
+		}
+	}
+	return specPackage{}, fmt.Errorf("unsupported spec version %q: the bundled CDEvents SDK implements %s", version, strings.Join(SpecVersions(), ", "))
+}
+
+// majorMinor returns the major and minor part of a version, e.g. 0.4 for 0.4.1
+func majorMinor(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// eventType returns the versioned type of an event in the spec version, e.g. dev.cdevents.pipelinerun.queued.0.2.0
+func (p specPackage) eventType(subject, predicate string) (string, error) {
+	eventType, ok := p.types[fmt.Sprintf("dev.cdevents.%s.%s", subject, predicate)]
+	if !ok {
+		return "", fmt.Errorf("%s %s events are not defined in spec version %s", subject, predicate, p.version)
+	}
+	return eventType, nil
+}
+
+// create creates an empty event of the spec version
+func (p specPackage) create(subject, predicate string) (api.CDEvent, error) {
+	eventType, err := p.eventType(subject, predicate)
+	if err != nil {
+		return nil, err
+	}
+	return p.newEvent(eventType, p.version)
+}
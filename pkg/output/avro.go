@@ -0,0 +1,219 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/linkedin/goavro/v2"
+)
+
+// AvroContentType is the content type of CDEvents encoded with AvroSchema
+const AvroContentType = "avro/binary"
+
+// AvroSchema is the Avro schema used to encode CDEvents, suitable for registration in a schema registry.
+// It is a single envelope for all event types and spec versions, not a schema per event type: fields
+// varying per event type, such as the subject content, are generic JSON values, so they are not typed
+// by the schema and the schema does not change with the event types.
+//
+//go:embed schemas/cdevent.avsc
+var AvroSchema string
+
+// avroJSONValue is the full name of the JsonValue record of AvroSchema
+const avroJSONValue = "dev.cdevents.JsonValue"
+
+// avroCodec encodes and decodes events following AvroSchema
+var avroCodec = func() *goavro.Codec {
+	codec, err := goavro.NewCodec(AvroSchema)
+	if err != nil {
+		panic(fmt.Sprintf("invalid Avro schema: %v", err))
+	}
+	return codec
+}()
+
+// formatAvro formats the event as Avro binary data
+func formatAvro(event api.CDEvent) (string, error) {
+	data, err := EncodeAvro(event)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// EncodeAvro encodes the event as Avro binary data following AvroSchema
+func EncodeAvro(event api.CDEvent) ([]byte, error) {
+	eventMap, err := EventToMap(event)
+	if err != nil {
+		return nil, err
+	}
+	context, _ := eventMap["context"].(map[string]interface{})
+	subject, _ := eventMap["subject"].(map[string]interface{})
+	content, _ := subject["content"].(map[string]interface{})
+
+	native := map[string]interface{}{
+		"context": map[string]interface{}{
+			"version":     avroOptionalString(context, "version"),
+			"specversion": avroOptionalString(context, "specversion"),
+			"id":          context["id"],
+			"source":      context["source"],
+			"type":        context["type"],
+			"timestamp":   context["timestamp"],
+			"chainId":     avroOptionalString(context, "chainId"),
+			"schemaUri":   avroOptionalString(context, "schemaUri"),
+			"links":       nil,
+		},
+		"subject": map[string]interface{}{
+			"id":      subject["id"],
+			"source":  avroOptionalString(subject, "source"),
+			"type":    avroOptionalString(subject, "type"),
+			"content": avroJSONMap(content),
+		},
+		"customData":            nil,
+		"customDataContentType": avroOptionalString(eventMap, "customDataContentType"),
+	}
+	if links, ok := context["links"].([]interface{}); ok {
+		native["context"].(map[string]interface{})["links"] = goavro.Union("array", avroJSONArray(links))
+	}
+	if customData, ok := eventMap["customData"]; ok {
+		native["customData"] = goavro.Union(avroJSONValue, avroJSON(customData))
+	}
+
+	data, err := avroCodec.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event as Avro: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalAvro decodes Avro binary data following AvroSchema into an event map, as produced by the JSON format
+func UnmarshalAvro(data []byte) (map[string]interface{}, error) {
+	native, rest, err := avroCodec.NativeFromBinary(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Avro event: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("failed to decode Avro event: %d trailing bytes", len(rest))
+	}
+	record, _ := native.(map[string]interface{})
+	nativeContext, _ := record["context"].(map[string]interface{})
+	nativeSubject, _ := record["subject"].(map[string]interface{})
+
+	context := map[string]interface{}{}
+	for _, field := range []string{"id", "source", "type", "timestamp"} {
+		context[field] = nativeContext[field]
+	}
+	for _, field := range []string{"version", "specversion", "chainId", "schemaUri"} {
+		setAvroOptional(context, field, nativeContext[field])
+	}
+	setAvroOptional(context, "links", nativeContext["links"])
+
+	subject := map[string]interface{}{"id": nativeSubject["id"]}
+	setAvroOptional(subject, "source", nativeSubject["source"])
+	setAvroOptional(subject, "type", nativeSubject["type"])
+	content, _ := nativeSubject["content"].(map[string]interface{})
+	subject["content"] = fromAvroJSONMap(content)
+
+	eventMap := map[string]interface{}{
+		"context": context,
+		"subject": subject,
+	}
+	setAvroOptional(eventMap, "customData", record["customData"])
+	setAvroOptional(eventMap, "customDataContentType", record["customDataContentType"])
+	return eventMap, nil
+}
+
+// avroOptionalString returns the Avro union value of an optional string field
+func avroOptionalString(m map[string]interface{}, key string) interface{} {
+	if value, ok := m[key].(string); ok {
+		return goavro.Union("string", value)
+	}
+	return nil
+}
+
+// avroJSON converts a decoded JSON value to the native form of a JsonValue record
+func avroJSON(value interface{}) map[string]interface{} {
+	var union interface{}
+	switch v := value.(type) {
+	case bool:
+		union = goavro.Union("boolean", v)
+	case float64:
+		union = goavro.Union("double", v)
+	case string:
+		union = goavro.Union("string", v)
+	case []interface{}:
+		union = goavro.Union("array", avroJSONArray(v))
+	case map[string]interface{}:
+		union = goavro.Union("map", avroJSONMap(v))
+	}
+	return map[string]interface{}{"value": union}
+}
+
+func avroJSONArray(items []interface{}) []interface{} {
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = avroJSON(item)
+	}
+	return values
+}
+
+func avroJSONMap(m map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{}, len(m))
+	for key, item := range m {
+		values[key] = avroJSON(item)
+	}
+	return values
+}
+
+// setAvroOptional sets a field decoded from an optional Avro union, leaving it out when it is null
+func setAvroOptional(m map[string]interface{}, key string, native interface{}) {
+	union, ok := native.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for branch, value := range union {
+		switch branch {
+		case "array":
+			items, _ := value.([]interface{})
+			m[key] = fromAvroJSONArray(items)
+		case avroJSONValue:
+			m[key] = fromAvroJSON(value)
+		default:
+			m[key] = value
+		}
+	}
+}
+
+// fromAvroJSON converts the native form of a JsonValue record to its JSON value
+func fromAvroJSON(native interface{}) interface{} {
+	record, _ := native.(map[string]interface{})
+	union, _ := record["value"].(map[string]interface{})
+	for branch, value := range union {
+		switch branch {
+		case "array":
+			items, _ := value.([]interface{})
+			return fromAvroJSONArray(items)
+		case "map":
+			m, _ := value.(map[string]interface{})
+			return fromAvroJSONMap(m)
+		default:
+			return value
+		}
+	}
+	return nil
+}
+
+func fromAvroJSONArray(items []interface{}) []interface{} {
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = fromAvroJSON(item)
+	}
+	return values
+}
+
+func fromAvroJSONMap(m map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{}, len(m))
+	for key, item := range m {
+		values[key] = fromAvroJSON(item)
+	}
+	return values
+}
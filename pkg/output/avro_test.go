@@ -0,0 +1,119 @@
+package output_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	cdeventsv04 "github.com/cdevents/sdk-go/pkg/api/v04"
+)
+
+func TestAvroRoundTrip(t *testing.T) {
+	event := newWireTestEvent(t)
+	event.SetChainId("chain-123")
+	if err := event.SetCustomData("application/json", map[string]interface{}{
+		"nested": map[string]interface{}{
+			"list":    []string{"a", "b"},
+			"number":  42,
+			"enabled": true,
+			"empty":   nil,
+		},
+	}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
+	}
+
+	data, err := output.EncodeAvro(event)
+	if err != nil {
+		t.Fatalf("failed to encode Avro: %v", err)
+	}
+
+	decoded, err := output.UnmarshalAvro(data)
+	if err != nil {
+		t.Fatalf("failed to decode Avro: %v", err)
+	}
+
+	formatted, err := output.FormatOutput(event, "json")
+	if err != nil {
+		t.Fatalf("failed to format JSON: %v", err)
+	}
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(formatted), &expected); err != nil {
+		t.Fatalf("failed to unmarshal JSON: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Avro round trip mismatch:\nexpected: %v\ngot:      %v", expected, decoded)
+	}
+}
+
+func TestAvroRoundTripAsCDEvent(t *testing.T) {
+	event := newWireTestEvent(t)
+
+	formatted, err := output.FormatOutput(event, "avro")
+	if err != nil {
+		t.Fatalf("failed to format Avro output: %v", err)
+	}
+	decoded, err := output.UnmarshalAvro([]byte(formatted))
+	if err != nil {
+		t.Fatalf("failed to decode Avro output: %v", err)
+	}
+
+	raw, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("failed to marshal decoded event: %v", err)
+	}
+	parsed, err := cdeventsv04.NewFromJsonBytes(raw)
+	if err != nil {
+		t.Fatalf("decoded event should be a valid CDEvent: %v", err)
+	}
+	if parsed.GetId() != "test-id" || parsed.GetSubjectId() != "pipeline-123" {
+		t.Errorf("unexpected decoded event: %s/%s", parsed.GetId(), parsed.GetSubjectId())
+	}
+}
+
+func TestUnmarshalAvroInvalid(t *testing.T) {
+	if _, err := output.UnmarshalAvro([]byte{0x02, 'a'}); err == nil {
+		t.Error("expected error for truncated Avro data")
+	}
+}
+
+func TestAvroSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(output.AvroSchema), &schema); err != nil {
+		t.Fatalf("Avro schema should be valid JSON: %v", err)
+	}
+	if schema["name"] != "CDEvent" || schema["namespace"] != "dev.cdevents" {
+		t.Errorf("unexpected Avro schema name: %v.%v", schema["namespace"], schema["name"])
+	}
+}
+
+func TestAvroRoundTripSpecVersion05(t *testing.T) {
+	factory, err := events.NewEventFactoryForSpecVersion("test-source", "0.5")
+	if err != nil {
+		t.Fatalf("failed to create factory: %v", err)
+	}
+	event, err := factory.CreatePipelineRunEvent("started", "pipeline-123", "test-pipeline", "", "", "https://ci.example.com/pipelines/123", nil,
+		events.WithPathLink("previous-event"))
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	data, err := output.EncodeAvro(event)
+	if err != nil {
+		t.Fatalf("failed to encode Avro: %v", err)
+	}
+	decoded, err := output.UnmarshalAvro(data)
+	if err != nil {
+		t.Fatalf("failed to decode Avro: %v", err)
+	}
+
+	expected, err := output.EventToMap(event)
+	if err != nil {
+		t.Fatalf("failed to convert event: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Avro round trip mismatch:\nexpected: %v\ngot:      %v", expected, decoded)
+	}
+}
@@ -0,0 +1,146 @@
+package output_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshalCanonicalJSONNumbers(t *testing.T) {
+	// Sample values from RFC 8785, section 3.2.2.3
+	testCases := map[string]string{
+		"333333333.33333329":            "333333333.3333333",
+		"1E30":                          "1e+30",
+		"4.50":                          "4.5",
+		"2e-3":                          "0.002",
+		"0.000000000000000000000000001": "1e-27",
+		"-0":                            "0",
+		"100":                           "100",
+		"1e21":                          "1e+21",
+		"123456789012345680000":         "123456789012345680000",
+		"0.0000001":                     "1e-7",
+		"-1.5":                          "-1.5",
+	}
+
+	for input, expected := range testCases {
+		var v interface{}
+		if err := json.Unmarshal([]byte(input), &v); err != nil {
+			t.Fatalf("failed to parse %s: %v", input, err)
+		}
+		data, err := output.MarshalCanonicalJSON(v)
+		if err != nil {
+			t.Fatalf("failed to canonicalize %s: %v", input, err)
+		}
+		if string(data) != expected {
+			t.Errorf("canonical form of %s: expected %s, got %s", input, expected, data)
+		}
+	}
+}
+
+func TestMarshalCanonicalJSONSorting(t *testing.T) {
+	// Sample from RFC 8785, section 3.2.3
+	input := `{
+		"€": "Euro Sign",
+		"\r": "Carriage Return",
+		"דּ": "Hebrew Letter Dalet With Dagesh",
+		"1": "One",
+		"😀": "Emoji: Grinning Face",
+		"\u0080": "Control",
+		"ö": "Latin Small Letter O With Diaeresis"
+	}`
+	var v interface{}
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+
+	data, err := output.MarshalCanonicalJSON(v)
+	if err != nil {
+		t.Fatalf("failed to canonicalize: %v", err)
+	}
+
+	expectedOrder := []string{"Carriage Return", "One", "Control", "Latin Small", "Euro Sign", "Emoji", "Hebrew"}
+	last := -1
+	for _, value := range expectedOrder {
+		index := strings.Index(string(data), value)
+		if index <= last {
+			t.Errorf("expected %q after previous member in %s", value, data)
+		}
+		last = index
+	}
+	if !strings.HasPrefix(string(data), `{"\r":"Carriage Return","1":"One"`) {
+		t.Errorf("unexpected canonical output: %s", data)
+	}
+}
+
+func TestMarshalCanonicalJSONStrings(t *testing.T) {
+	data, err := output.MarshalCanonicalJSON(map[string]interface{}{
+		"html":    "<a href='x'>&</a>",
+		"control": "\u0001\t",
+		"unicode": "€",
+		"list":    []interface{}{1, true, nil, "x"},
+	})
+	if err != nil {
+		t.Fatalf("failed to canonicalize: %v", err)
+	}
+
+	expected := `{"control":"\u0001\t","html":"<a href='x'>&</a>","list":[1,true,null,"x"],"unicode":"€"}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestFormatCanonicalJSONIsStable(t *testing.T) {
+	event := newWireTestEvent(t)
+	if err := event.SetCustomData("application/json", map[string]interface{}{"b": 2, "a": 1.0}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
+	}
+
+	first, err := output.FormatOutput(event, "canonical-json")
+	if err != nil {
+		t.Fatalf("failed to format canonical JSON: %v", err)
+	}
+	second, err := output.FormatOutput(event, "canonical-json")
+	if err != nil {
+		t.Fatalf("failed to format canonical JSON: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("canonical JSON should be stable:\n%s\n%s", first, second)
+	}
+	if !strings.Contains(first, `"customData":{"a":1,"b":2}`) {
+		t.Errorf("canonical JSON should contain sorted custom data, got %s", first)
+	}
+	if strings.ContainsAny(first, "\n ") {
+		t.Errorf("canonical JSON should not contain insignificant whitespace, got %s", first)
+	}
+}
+
+func TestFormatYAMLMatchesJSON(t *testing.T) {
+	event := newWireTestEvent(t)
+
+	jsonOutput, err := output.FormatOutput(event, "json")
+	if err != nil {
+		t.Fatalf("failed to format JSON: %v", err)
+	}
+	yamlOutput, err := output.FormatOutput(event, "yaml")
+	if err != nil {
+		t.Fatalf("failed to format YAML: %v", err)
+	}
+
+	var fromJSON, fromYAML map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonOutput), &fromJSON); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if err := yaml.Unmarshal([]byte(yamlOutput), &fromYAML); err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	jsonCanonical, _ := output.MarshalCanonicalJSON(fromJSON)
+	yamlCanonical, _ := output.MarshalCanonicalJSON(fromYAML)
+	if string(jsonCanonical) != string(yamlCanonical) {
+		t.Errorf("YAML and JSON outputs should describe the same event:\n%s\n%s", jsonCanonical, yamlCanonical)
+	}
+}
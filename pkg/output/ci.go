@@ -0,0 +1,63 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/google/uuid"
+)
+
+// ciAttribute is a key attribute of an event exposed to later CI steps
+type ciAttribute struct {
+	name  string
+	value string
+}
+
+// ciAttributes returns the key attributes of the event in a stable order
+func ciAttributes(event api.CDEvent) []ciAttribute {
+	return []ciAttribute{
+		{name: "CDEVENT_ID", value: event.GetId()},
+		{name: "CDEVENT_TYPE", value: event.GetType().String()},
+		{name: "CDEVENT_SUBJECT_ID", value: event.GetSubjectId()},
+		{name: "CDEVENT_TIMESTAMP", value: event.GetTimestamp().Format(time.RFC3339Nano)},
+	}
+}
+
+// formatGitHubOutput formats the key attributes as GITHUB_OUTPUT entries.
+// Values are written in the multiline name<<delimiter form with a random delimiter,
+// so values containing line breaks can't inject other outputs.
+func formatGitHubOutput(event api.CDEvent) (string, error) {
+	delimiter := "ghadelimiter_" + uuid.NewString()
+	var sb strings.Builder
+	for _, attr := range ciAttributes(event) {
+		if strings.Contains(attr.value, delimiter) {
+			return "", fmt.Errorf("value of %s contains the output delimiter", attr.name)
+		}
+		fmt.Fprintf(&sb, "%s<<%s\n%s\n%s\n", strings.ToLower(attr.name), delimiter, attr.value, delimiter)
+	}
+	return sb.String(), nil
+}
+
+// formatDotenv formats the key attributes as a GitLab dotenv report.
+// The dotenv format has no multiline values, so values with line breaks are rejected.
+func formatDotenv(event api.CDEvent) (string, error) {
+	var sb strings.Builder
+	for _, attr := range ciAttributes(event) {
+		if strings.ContainsAny(attr.value, "\r\n") {
+			return "", fmt.Errorf("value of %s contains a line break, which a dotenv report can't hold", attr.name)
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", attr.name, attr.value)
+	}
+	return sb.String(), nil
+}
+
+// formatShellExports formats the key attributes as shell export statements
+func formatShellExports(event api.CDEvent) (string, error) {
+	var sb strings.Builder
+	for _, attr := range ciAttributes(event) {
+		fmt.Fprintf(&sb, "export %s=%s\n", attr.name, shellQuote(attr.value))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,118 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/output"
+)
+
+func TestFormatCIOutputs(t *testing.T) {
+	event := newWireTestEvent(t)
+	event.SetTimestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "gitlab-dotenv",
+			expected: "CDEVENT_ID=test-id\n" +
+				"CDEVENT_TYPE=dev.cdevents.pipelinerun.queued.0.2.0\n" +
+				"CDEVENT_SUBJECT_ID=pipeline-123\n" +
+				"CDEVENT_TIMESTAMP=2024-01-02T03:04:05Z\n",
+		},
+		{
+			format: "shell",
+			expected: "export CDEVENT_ID='test-id'\n" +
+				"export CDEVENT_TYPE='dev.cdevents.pipelinerun.queued.0.2.0'\n" +
+				"export CDEVENT_SUBJECT_ID='pipeline-123'\n" +
+				"export CDEVENT_TIMESTAMP='2024-01-02T03:04:05Z'\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			formatted, err := output.FormatOutput(event, tc.format)
+			if err != nil {
+				t.Fatalf("failed to format %s output: %v", tc.format, err)
+			}
+			if formatted != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestFormatGitHubOutput(t *testing.T) {
+	event := newWireTestEvent(t)
+	event.SetTimestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	event.SetSubjectId("pipeline-123\ncdevent_type=injected")
+
+	formatted, err := output.FormatOutput(event, "github")
+	if err != nil {
+		t.Fatalf("failed to format github output: %v", err)
+	}
+	outputs := parseGitHubOutput(t, formatted)
+	expected := map[string]string{
+		"cdevent_id":         "test-id",
+		"cdevent_type":       "dev.cdevents.pipelinerun.queued.0.2.0",
+		"cdevent_subject_id": "pipeline-123\ncdevent_type=injected",
+		"cdevent_timestamp":  "2024-01-02T03:04:05Z",
+	}
+	if len(outputs) != len(expected) {
+		t.Errorf("expected %d outputs, got %v", len(expected), outputs)
+	}
+	for name, value := range expected {
+		if outputs[name] != value {
+			t.Errorf("expected %s=%q, got %q", name, value, outputs[name])
+		}
+	}
+}
+
+// parseGitHubOutput parses GITHUB_OUTPUT entries the way the runner does
+func parseGitHubOutput(t *testing.T, text string) map[string]string {
+	t.Helper()
+	outputs := map[string]string{}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		name, delimiter, ok := strings.Cut(lines[i], "<<")
+		if !ok {
+			name, value, _ := strings.Cut(lines[i], "=")
+			outputs[name] = value
+			continue
+		}
+		var value []string
+		for i++; i < len(lines) && lines[i] != delimiter; i++ {
+			value = append(value, lines[i])
+		}
+		if i == len(lines) {
+			t.Fatalf("output %s is not terminated by its delimiter", name)
+		}
+		outputs[name] = strings.Join(value, "\n")
+	}
+	return outputs
+}
+
+func TestFormatDotenvRejectsLineBreaks(t *testing.T) {
+	event := newWireTestEvent(t)
+	event.SetSubjectId("pipeline-123\nCDEVENT_TYPE=injected")
+
+	if _, err := output.FormatOutput(event, "gitlab-dotenv"); err == nil || !strings.Contains(err.Error(), "CDEVENT_SUBJECT_ID") {
+		t.Errorf("expected a line break error, got %v", err)
+	}
+}
+
+func TestFormatShellExportsQuoting(t *testing.T) {
+	event := newWireTestEvent(t)
+	event.SetSubjectId("it's $HOME")
+
+	formatted, err := output.FormatOutput(event, "shell")
+	if err != nil {
+		t.Fatalf("failed to format shell output: %v", err)
+	}
+	if !strings.Contains(formatted, `export CDEVENT_SUBJECT_ID='it'\''s $HOME'`) {
+		t.Errorf("shell output should quote values, got:\n%s", formatted)
+	}
+}
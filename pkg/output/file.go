@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// IsBinaryFormat reports whether the output format produces binary data
+func IsBinaryFormat(format string) bool {
+	return format == "protobuf" || format == "avro"
+}
+
+// WriteFile writes data to the named file, creating parent directories as needed.
+// Without appendMode the data is written to a temporary file that is renamed over
+// the target, so readers never observe a partially written file. With appendMode
+// the data is appended with a single write call.
+func WriteFile(filename string, data []byte, appendMode bool) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	if appendMode {
+		return appendFile(filename, data)
+	}
+	return writeFileAtomic(filename, data)
+}
+
+// appendFile appends data to the named file, creating it if needed
+func appendFile(filename string, data []byte) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", filename, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over the named file
+func writeFileAtomic(filename string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", filename, err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmpName, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", tmpName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmpName, err)
+	}
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", tmpName, err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", tmpName, filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/output"
+)
+
+func TestWriteFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "nested", "dir", "event.json")
+
+	if err := output.WriteFile(filename, []byte("first\n"), false); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := output.WriteFile(filename, []byte("second\n"), false); err != nil {
+		t.Fatalf("failed to overwrite file: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "second\n" {
+		t.Errorf("expected file to be overwritten, got %q", data)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAppend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "events.ndjson")
+
+	for _, line := range []string{"one\n", "two\n"} {
+		if err := output.WriteFile(filename, []byte(line), true); err != nil {
+			t.Fatalf("failed to append to file: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "one\ntwo\n" {
+		t.Errorf("expected appended content, got %q", data)
+	}
+}
+
+func TestIsBinaryFormat(t *testing.T) {
+	for format, expected := range map[string]bool{"json": false, "yaml": false, "protobuf": true, "avro": true} {
+		if output.IsBinaryFormat(format) != expected {
+			t.Errorf("IsBinaryFormat(%q) should be %v", format, expected)
+		}
+	}
+}
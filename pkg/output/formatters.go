@@ -8,27 +8,34 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// CustomData represents custom data that can be added to events
-// This follows the CDEvents spec: https://github.com/cdevents/spec/blob/v0.4.1/spec.md#cdevents-custom-data
-type CustomData struct {
-	Data interface{} `json:"customData,omitempty"`
-	ContentType string `json:"customDataContentType,omitempty"`
-}
-
-// FormatOutput formats the CDEvent based on the specified format
+// FormatOutput formats the CDEvent based on the specified format.
+// Custom data is part of the event, as set by events.EventFactory or SetCustomData.
 func FormatOutput(event api.CDEvent, format string) (string, error) {
-	return FormatOutputWithCustomData(event, nil, format)
-}
-
-// FormatOutputWithCustomData formats the CDEvent with custom data based on the specified format
-func FormatOutputWithCustomData(event api.CDEvent, customData *CustomData, format string) (string, error) {
 	switch format {
 	case "json":
-		return formatJSONWithCustomData(event, customData)
+		return formatJSON(event)
+	case "canonical-json":
+		return formatCanonicalJSON(event)
 	case "yaml":
-		return formatYAMLWithCustomData(event, customData)
+		return formatYAML(event)
 	case "cloudevent":
-		return formatCloudEventWithCustomData(event, customData)
+		return formatCloudEvent(event)
+	case "http":
+		return FormatHTTPRequest(event, HTTPRenderOptions{})
+	case "http-structured":
+		return FormatHTTPRequest(event, HTTPRenderOptions{Structured: true})
+	case "curl":
+		return FormatCurl(event, HTTPRenderOptions{})
+	case "protobuf":
+		return formatProtobuf(event)
+	case "avro":
+		return formatAvro(event)
+	case "github":
+		return formatGitHubOutput(event)
+	case "gitlab-dotenv":
+		return formatDotenv(event)
+	case "shell":
+		return formatShellExports(event)
 	default:
 		return "", fmt.Errorf("unsupported output format: %s", format)
 	}
@@ -36,123 +43,55 @@ func FormatOutputWithCustomData(event api.CDEvent, customData *CustomData, forma
 
 // formatJSON formats the event as JSON
 func formatJSON(event api.CDEvent) (string, error) {
-	return formatJSONWithCustomData(event, nil)
-}
-
-// formatJSONWithCustomData formats the event as JSON with custom data
-func formatJSONWithCustomData(event api.CDEvent, customData *CustomData) (string, error) {
-	// Marshal the event to get its JSON representation
-	eventData, err := json.Marshal(event)
+	eventMap, err := EventToMap(event)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal event: %w", err)
-	}
-
-	// Parse the event JSON to a map
-	var eventMap map[string]interface{}
-	if err := json.Unmarshal(eventData, &eventMap); err != nil {
-		return "", fmt.Errorf("failed to unmarshal event: %w", err)
+		return "", err
 	}
 
-	// Add custom data according to CDEvents spec at the root level
-	if customData != nil {
-		if customData.Data != nil {
-			eventMap["customData"] = customData.Data
-		}
-		if customData.ContentType != "" {
-			eventMap["customDataContentType"] = customData.ContentType
-		}
-	}
-
-	// Marshal back to JSON with custom data
 	data, err := json.MarshalIndent(eventMap, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal event with custom data to JSON: %w", err)
+		return "", fmt.Errorf("failed to marshal event to JSON: %w", err)
 	}
 	return string(data), nil
 }
 
-// formatYAML formats the event as YAML
-func formatYAML(event api.CDEvent) (string, error) {
-	return formatYAMLWithCustomData(event, nil)
-}
-
-// formatYAMLWithCustomData formats the event as YAML with custom data
-func formatYAMLWithCustomData(event api.CDEvent, customData *CustomData) (string, error) {
-	if customData == nil {
-		data, err := yaml.Marshal(event)
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal event to YAML: %w", err)
-		}
-		return string(data), nil
-	}
-
-	// Similar to JSON, but for YAML
+// EventToMap converts the event to a generic map, with custom data at the root level as defined by the spec.
+// It is the representation the formatters and policies work on.
+func EventToMap(event api.CDEvent) (map[string]interface{}, error) {
 	eventData, err := json.Marshal(event)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal event: %w", err)
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
 	}
 
 	var eventMap map[string]interface{}
 	if err := json.Unmarshal(eventData, &eventMap); err != nil {
-		return "", fmt.Errorf("failed to unmarshal event: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
 	}
+	return eventMap, nil
+}
 
-	// Add custom data according to CDEvents spec at the root level
-	if customData.Data != nil {
-		eventMap["customData"] = customData.Data
-	}
-	if customData.ContentType != "" {
-		eventMap["customDataContentType"] = customData.ContentType
+// formatYAML formats the event as YAML
+func formatYAML(event api.CDEvent) (string, error) {
+	// Go through the JSON representation so YAML uses the same field names and ordering
+	eventMap, err := EventToMap(event)
+	if err != nil {
+		return "", err
 	}
 
 	data, err := yaml.Marshal(eventMap)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal event with custom data to YAML: %w", err)
+		return "", fmt.Errorf("failed to marshal event to YAML: %w", err)
 	}
 	return string(data), nil
 }
 
 // formatCloudEvent formats the event as CloudEvent JSON
 func formatCloudEvent(event api.CDEvent) (string, error) {
-	return formatCloudEventWithCustomData(event, nil)
-}
-
-// formatCloudEventWithCustomData formats the event as CloudEvent JSON with custom data
-func formatCloudEventWithCustomData(event api.CDEvent, customData *CustomData) (string, error) {
 	ce, err := api.AsCloudEvent(event)
 	if err != nil {
 		return "", fmt.Errorf("failed to convert to CloudEvent: %w", err)
 	}
 
-	if customData != nil {
-		// Add custom data to the CloudEvent data field
-		ceData, err := json.Marshal(ce)
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal CloudEvent: %w", err)
-		}
-
-		var ceMap map[string]interface{}
-		if err := json.Unmarshal(ceData, &ceMap); err != nil {
-			return "", fmt.Errorf("failed to unmarshal CloudEvent: %w", err)
-		}
-
-		// Add custom data to the CloudEvent data according to CDEvents spec
-		if data, ok := ceMap["data"].(map[string]interface{}); ok {
-			if customData.Data != nil {
-				data["customData"] = customData.Data
-			}
-			if customData.ContentType != "" {
-				data["customDataContentType"] = customData.ContentType
-			}
-		}
-
-		data, err := json.MarshalIndent(ceMap, "", "  ")
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal CloudEvent with custom data to JSON: %w", err)
-		}
-		return string(data), nil
-	}
-	
 	data, err := json.MarshalIndent(ce, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal CloudEvent to JSON: %w", err)
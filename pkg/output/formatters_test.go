@@ -21,14 +21,13 @@ func TestFormatJSONWithCustomData(t *testing.T) {
 	event.SetSubjectId("pipeline-123")
 	event.SetSubjectPipelineName("test-pipeline")
 
-	customData := output.CustomData{
-		Data: map[string]interface{}{
-			"mydata": "value123",
-		},
-		ContentType: "application/json",
+	if err := event.SetCustomData("application/json", map[string]interface{}{
+		"mydata": "value123",
+	}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
 	}
 
-	formatted, err := output.FormatOutputWithCustomData(event, &customData, "json")
+	formatted, err := output.FormatOutput(event, "json")
 	if err != nil {
 		t.Fatalf("failed to format output with custom data: %v", err)
 	}
@@ -57,18 +56,17 @@ func TestFormatYAMLWithCustomDataError(t *testing.T) {
 	event.SetSubjectPipelineName("test-pipeline")
 
 	// Test with custom data that has complex nested structures
-	customData := output.CustomData{
-		Data: map[string]interface{}{
-			"complex": map[string]interface{}{
-				"nested": []string{"array", "of", "values"},
-				"number": 42,
-				"boolean": true,
-			},
+	if err := event.SetCustomData("application/json", map[string]interface{}{
+		"complex": map[string]interface{}{
+			"nested": []string{"array", "of", "values"},
+			"number": 42,
+			"boolean": true,
 		},
-		ContentType: "application/json",
+	}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
 	}
 
-	formatted, err := output.FormatOutputWithCustomData(event, &customData, "yaml")
+	formatted, err := output.FormatOutput(event, "yaml")
 	if err != nil {
 		t.Fatalf("failed to format YAML with complex custom data: %v", err)
 	}
@@ -95,7 +93,7 @@ func TestFormatCloudEventWithoutCustomData(t *testing.T) {
 	event.SetSubjectPipelineName("test-pipeline")
 
 	// Test CloudEvent formatting without custom data (different code path)
-	formatted, err := output.FormatOutputWithCustomData(event, nil, "cloudevent")
+	formatted, err := output.FormatOutput(event, "cloudevent")
 	if err != nil {
 		t.Fatalf("failed to format CloudEvent without custom data: %v", err)
 	}
@@ -189,7 +187,7 @@ func TestFormatYAMLWithoutCustomData(t *testing.T) {
 	event.SetSubjectPipelineName("test-pipeline")
 
 	// Test YAML formatting without custom data (different code path)
-	formatted, err := output.FormatOutputWithCustomData(event, nil, "yaml")
+	formatted, err := output.FormatOutput(event, "yaml")
 	if err != nil {
 		t.Fatalf("failed to format YAML without custom data: %v", err)
 	}
@@ -217,14 +215,13 @@ func TestFormatYAMLWithCustomData(t *testing.T) {
 	event.SetSubjectId("pipeline-123")
 	event.SetSubjectPipelineName("test-pipeline")
 
-	customData := output.CustomData{
-		Data: map[string]interface{}{
-			"mydata": "value123",
-		},
-		ContentType: "application/json",
+	if err := event.SetCustomData("application/json", map[string]interface{}{
+		"mydata": "value123",
+	}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
 	}
 
-	formatted, err := output.FormatOutputWithCustomData(event, &customData, "yaml")
+	formatted, err := output.FormatOutput(event, "yaml")
 	if err != nil {
 		t.Fatalf("failed to format output with custom data: %v", err)
 	}
@@ -254,14 +251,13 @@ func TestFormatCloudEventWithCustomData(t *testing.T) {
 	event.SetSubjectId("pipeline-123")
 	event.SetSubjectPipelineName("test-pipeline")
 
-	customData := output.CustomData{
-		Data: map[string]interface{}{
-			"mydata": "value123",
-		},
-		ContentType: "application/json",
+	if err := event.SetCustomData("application/json", map[string]interface{}{
+		"mydata": "value123",
+	}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
 	}
 
-	formatted, err := output.FormatOutputWithCustomData(event, &customData, "cloudevent")
+	formatted, err := output.FormatOutput(event, "cloudevent")
 	if err != nil {
 		t.Fatalf("failed to format output with custom data: %v", err)
 	}
@@ -296,7 +292,7 @@ func TestFormatOutputWithoutCustomData(t *testing.T) {
 	formats := []string{"json", "yaml", "cloudevent"}
 	for _, format := range formats {
 		t.Run(format, func(t *testing.T) {
-			formatted, err := output.FormatOutputWithCustomData(event, nil, format)
+			formatted, err := output.FormatOutput(event, format)
 			if err != nil {
 				t.Fatalf("failed to format output without custom data: %v", err)
 			}
@@ -343,7 +339,7 @@ func TestFormatOutputUnsupportedFormat(t *testing.T) {
 		t.Fatalf("failed to create test event: %v", err)
 	}
 
-	_, err = output.FormatOutputWithCustomData(event, nil, "unsupported")
+	_, err = output.FormatOutput(event, "unsupported")
 	if err == nil {
 		t.Fatalf("expected error for unsupported format")
 	}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// DefaultHTTPTarget is the target URL used when rendering HTTP requests without an explicit target
+const DefaultHTTPTarget = "http://localhost:8080/events"
+
+// Encodings of events in HTTP requests, see HTTPRenderOptions
+const (
+	// EncodingBinary encodes events as CloudEvents, with the CDEvent as JSON body
+	EncodingBinary = "binary"
+	// EncodingProtobuf encodes events as CloudEvents in the CloudEvents protobuf format
+	EncodingProtobuf = "protobuf"
+	// EncodingAvro encodes events as Avro binary data following AvroSchema
+	EncodingAvro = "avro"
+)
+
+// HTTPRenderOptions configures how an event is rendered as an HTTP request
+type HTTPRenderOptions struct {
+	// Target is the URL the request is sent to
+	Target string
+	// Headers are additional HTTP headers added to the request
+	Headers map[string]string
+	// Structured selects the structured content mode instead of the binary one
+	Structured bool
+	// Encoding is the encoding of the event, EncodingBinary when empty
+	Encoding string
+}
+
+// FormatHTTPRequest renders the HTTP request that HTTPTransport would send for the event
+func FormatHTTPRequest(event api.CDEvent, options HTTPRenderOptions) (string, error) {
+	req, body, err := buildHTTPRequest(event, options)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	requestURI := req.URL.RequestURI()
+	fmt.Fprintf(&sb, "%s %s HTTP/1.1\n", req.Method, requestURI)
+	fmt.Fprintf(&sb, "Host: %s\n", req.URL.Host)
+	for _, name := range sortedHeaderNames(req.Header) {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+	fmt.Fprintf(&sb, "Content-Length: %d\n", len(body))
+	sb.WriteString("\n")
+	sb.Write(body)
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+// FormatCurl renders a curl command that sends the event like HTTPTransport would
+func FormatCurl(event api.CDEvent, options HTTPRenderOptions) (string, error) {
+	req, body, err := buildHTTPRequest(event, options)
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{fmt.Sprintf("curl -X %s %s", req.Method, shellQuote(req.URL.String()))}
+	for _, name := range sortedHeaderNames(req.Header) {
+		for _, value := range req.Header[name] {
+			lines = append(lines, fmt.Sprintf("  -H %s", shellQuote(name+": "+value)))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("  --data-binary %s", shellQuote(string(body))))
+	return strings.Join(lines, " \\\n") + "\n", nil
+}
+
+// NewHTTPRequest builds the POST request sending the event to the target of the options.
+// HTTPTransport sends this request, and the http and curl formats render it.
+func NewHTTPRequest(ctx context.Context, event api.CDEvent, options HTTPRenderOptions) (*http.Request, error) {
+	target := cmp.Or(options.Target, DefaultHTTPTarget)
+	switch encoding := cmp.Or(options.Encoding, EncodingBinary); encoding {
+	case EncodingBinary:
+		ce, err := api.AsCloudEvent(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert to CloudEvent: %w", err)
+		}
+		req, err := newPostRequest(ctx, target, nil, options.Headers)
+		if err != nil {
+			return nil, err
+		}
+		writeCtx := binding.WithForceBinary(ctx)
+		if options.Structured {
+			writeCtx = binding.WithForceStructured(ctx)
+		}
+		if err := cehttp.WriteRequest(writeCtx, binding.ToMessage(ce), req); err != nil {
+			return nil, fmt.Errorf("failed to encode CloudEvent as HTTP request: %w", err)
+		}
+		return req, nil
+	case EncodingProtobuf, EncodingAvro:
+		body, contentType, err := encodeBody(event, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event as %s: %w", encoding, err)
+		}
+		req, err := newPostRequest(ctx, target, bytes.NewReader(body), options.Headers)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q (supported: %s, %s, %s)", encoding, EncodingBinary, EncodingProtobuf, EncodingAvro)
+	}
+}
+
+// newPostRequest creates a POST request with the additional headers
+func newPostRequest(ctx context.Context, target string, body io.Reader, headers map[string]string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// encodeBody encodes the event in the protobuf or Avro encoding, returning the body and its content type
+func encodeBody(event api.CDEvent, encoding string) ([]byte, string, error) {
+	if encoding == EncodingProtobuf {
+		body, err := EncodeProtobuf(event)
+		return body, ProtobufContentType, err
+	}
+	body, err := EncodeAvro(event)
+	return body, AvroContentType, err
+}
+
+// buildHTTPRequest builds the request of the event and reads its body for rendering
+func buildHTTPRequest(event api.CDEvent, options HTTPRenderOptions) (*http.Request, []byte, error) {
+	req, err := NewHTTPRequest(context.Background(), event, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read HTTP request body: %w", err)
+		}
+	}
+	return req, body, nil
+}
+
+// sortedHeaderNames returns the header names in a stable order
+func sortedHeaderNames(header http.Header) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// shellQuote quotes a string for safe use in a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
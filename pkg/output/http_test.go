@@ -0,0 +1,113 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	cdeventsv04 "github.com/cdevents/sdk-go/pkg/api/v04"
+)
+
+func newWireTestEvent(t *testing.T) *cdeventsv04.PipelineRunQueuedEvent {
+	t.Helper()
+	event, err := cdeventsv04.NewPipelineRunQueuedEvent()
+	if err != nil {
+		t.Fatalf("failed to create test event: %v", err)
+	}
+	event.SetId("test-id")
+	event.SetSource("test-source")
+	event.SetSubjectId("pipeline-123")
+	event.SetSubjectPipelineName("test-pipeline")
+	return event
+}
+
+func TestFormatHTTPRequestBinary(t *testing.T) {
+	event := newWireTestEvent(t)
+
+	formatted, err := output.FormatHTTPRequest(event, output.HTTPRenderOptions{
+		Target:  "http://example.com/events",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatalf("failed to format HTTP request: %v", err)
+	}
+
+	expected := []string{
+		"POST /events HTTP/1.1\n",
+		"Host: example.com\n",
+		"Authorization: Bearer token\n",
+		"Ce-Id: test-id\n",
+		"Ce-Source: test-source\n",
+		"Ce-Specversion: 1.0\n",
+		"Ce-Subject: pipeline-123\n",
+		"Ce-Type: dev.cdevents.pipelinerun.queued.0.2.0\n",
+		"Content-Type: application/json\n",
+		`"pipelineName":"test-pipeline"`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("HTTP request should contain %q, got:\n%s", want, formatted)
+		}
+	}
+}
+
+func TestFormatHTTPRequestStructured(t *testing.T) {
+	event := newWireTestEvent(t)
+	if err := event.SetCustomData("application/json", map[string]interface{}{"key": "value"}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
+	}
+
+	formatted, err := output.FormatHTTPRequest(event, output.HTTPRenderOptions{Structured: true})
+	if err != nil {
+		t.Fatalf("failed to format HTTP request: %v", err)
+	}
+
+	if !strings.Contains(formatted, "Host: localhost:8080\n") {
+		t.Errorf("HTTP request should use the default target, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, "Content-Type: application/cloudevents+json\n") {
+		t.Errorf("structured HTTP request should use the CloudEvents content type, got:\n%s", formatted)
+	}
+	if strings.Contains(formatted, "Ce-Id:") {
+		t.Errorf("structured HTTP request should not contain ce-* headers, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, `"customData":{"key":"value"}`) {
+		t.Errorf("structured HTTP request should contain custom data, got:\n%s", formatted)
+	}
+}
+
+func TestFormatCurl(t *testing.T) {
+	event := newWireTestEvent(t)
+	event.SetSubjectPipelineName("it's a pipeline")
+
+	formatted, err := output.FormatCurl(event, output.HTTPRenderOptions{Target: "https://example.com/events"})
+	if err != nil {
+		t.Fatalf("failed to format curl command: %v", err)
+	}
+
+	if !strings.HasPrefix(formatted, "curl -X POST 'https://example.com/events' \\\n") {
+		t.Errorf("curl command should start with the target, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, "  -H 'Ce-Id: test-id' \\\n") {
+		t.Errorf("curl command should contain ce-id header, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, `it'\''s a pipeline`) {
+		t.Errorf("curl command should escape single quotes, got:\n%s", formatted)
+	}
+}
+
+func TestFormatOutputWireFormats(t *testing.T) {
+	event := newWireTestEvent(t)
+
+	for _, format := range []string{"http", "http-structured", "curl"} {
+		t.Run(format, func(t *testing.T) {
+			formatted, err := output.FormatOutput(event, format)
+			if err != nil {
+				t.Fatalf("failed to format output as %s: %v", format, err)
+			}
+			if formatted == "" {
+				t.Errorf("formatted %s output should not be empty", format)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/cdevents/sdk-go/pkg/api"
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+)
+
+// ProtobufContentType is the content type of CloudEvents in the protobuf event format
+const ProtobufContentType = protobuf.ApplicationCloudEventsProtobuf
+
+// formatProtobuf formats the event as a CloudEvent in the protobuf format
+func formatProtobuf(event api.CDEvent) (string, error) {
+	data, err := EncodeProtobuf(event)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// EncodeProtobuf encodes the event as a CloudEvent in the CloudEvents protobuf format,
+// with the CDEvent as JSON data
+func EncodeProtobuf(event api.CDEvent) ([]byte, error) {
+	ce, err := api.AsCloudEvent(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to CloudEvent: %w", err)
+	}
+	data, err := protobuf.Protobuf.Marshal(ce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CloudEvent as protobuf: %w", err)
+	}
+	return data, nil
+}
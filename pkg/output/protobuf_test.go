@@ -0,0 +1,69 @@
+package output_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	event := newWireTestEvent(t)
+	if err := event.SetCustomData("application/json", map[string]interface{}{"key": "value"}); err != nil {
+		t.Fatalf("failed to set custom data: %v", err)
+	}
+
+	data, err := output.EncodeProtobuf(event)
+	if err != nil {
+		t.Fatalf("failed to encode protobuf: %v", err)
+	}
+
+	decoded := cloudevents.NewEvent()
+	if err := protobuf.Protobuf.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode protobuf: %v", err)
+	}
+
+	if decoded.ID() != "test-id" {
+		t.Errorf("expected id test-id, got %s", decoded.ID())
+	}
+	if decoded.Source() != "test-source" {
+		t.Errorf("expected source test-source, got %s", decoded.Source())
+	}
+	if decoded.Type() != event.GetType().String() {
+		t.Errorf("expected type %s, got %s", event.GetType(), decoded.Type())
+	}
+	if decoded.Subject() != "pipeline-123" {
+		t.Errorf("expected subject pipeline-123, got %s", decoded.Subject())
+	}
+	if decoded.DataContentType() != "application/json" {
+		t.Errorf("expected JSON data content type, got %s", decoded.DataContentType())
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(decoded.Data(), &payload); err != nil {
+		t.Fatalf("failed to unmarshal decoded data: %v", err)
+	}
+	if !reflect.DeepEqual(payload["customData"], map[string]interface{}{"key": "value"}) {
+		t.Errorf("decoded data should contain custom data, got %v", payload["customData"])
+	}
+}
+
+func TestFormatOutputProtobuf(t *testing.T) {
+	event := newWireTestEvent(t)
+
+	formatted, err := output.FormatOutput(event, "protobuf")
+	if err != nil {
+		t.Fatalf("failed to format protobuf output: %v", err)
+	}
+
+	decoded := cloudevents.NewEvent()
+	if err := protobuf.Protobuf.Unmarshal([]byte(formatted), &decoded); err != nil {
+		t.Fatalf("failed to decode protobuf output: %v", err)
+	}
+	if decoded.ID() != event.GetId() {
+		t.Errorf("expected id %s, got %s", event.GetId(), decoded.ID())
+	}
+}
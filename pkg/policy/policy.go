@@ -0,0 +1,284 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is how a policy violation is reported
+type Severity string
+
+const (
+	// SeverityError violations fail the command
+	SeverityError Severity = "error"
+	// SeverityWarning violations are reported but do not fail the command
+	SeverityWarning Severity = "warning"
+)
+
+// Policy is a set of organisation specific conventions events must follow
+type Policy struct {
+	// Rules lists the conventions of the policy
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule checks a single field of the events it applies to
+type Rule struct {
+	// Name identifies the rule in reports
+	Name string `yaml:"name"`
+	// Description explains the convention, it is reported with violations
+	Description string `yaml:"description,omitempty"`
+	// Severity is error or warning, error when empty
+	Severity Severity `yaml:"severity,omitempty"`
+	// When selects the events the rule applies to, all events when empty
+	When Selector `yaml:"when,omitempty"`
+	// Field is the JSON pointer of the checked value, e.g. /context/source
+	Field string `yaml:"field"`
+	// Required requires the field to be present and not empty
+	Required bool `yaml:"required,omitempty"`
+	// Prefix requires a string field to start with the prefix
+	Prefix string `yaml:"prefix,omitempty"`
+	// Pattern requires a string field to match the regular expression
+	Pattern string `yaml:"pattern,omitempty"`
+	// OneOf requires a string field to be one of the values
+	OneOf []string `yaml:"oneOf,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Selector selects events by type
+type Selector struct {
+	// Types lists event type patterns, e.g. dev.cdevents.service.*
+	Types []string `yaml:"types,omitempty"`
+}
+
+// Violation describes an event that breaks a policy rule
+type Violation struct {
+	// Rule is the name of the broken rule
+	Rule string `json:"rule"`
+	// Severity is the severity of the broken rule
+	Severity Severity `json:"severity"`
+	// Path is the JSON pointer of the checked field
+	Path string `json:"path"`
+	// Message describes the violation
+	Message string `json:"message"`
+}
+
+// String returns the violation as "path: message (rule)"
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s (%s)", v.Path, v.Message, v.Rule)
+}
+
+// Load reads and parses a policy file
+func Load(filename string) (*Policy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", filename, err)
+	}
+	return p, nil
+}
+
+// Parse parses and checks a YAML policy
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	names := map[string]bool{}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if err := rule.compile(); err != nil {
+			if rule.Name == "" {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule name: %s", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return &p, nil
+}
+
+// compile checks the rule and compiles its pattern
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !strings.HasPrefix(r.Field, "/") {
+		return fmt.Errorf("field must be a JSON pointer such as /context/source, got %q", r.Field)
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("unsupported severity %q (supported: error, warning)", r.Severity)
+	}
+	for _, pattern := range r.When.Types {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid type pattern %q: %w", pattern, err)
+		}
+	}
+	if r.Pattern != "" {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		r.pattern = pattern
+	}
+	if !r.Required && r.Prefix == "" && r.Pattern == "" && len(r.OneOf) == 0 {
+		return fmt.Errorf("at least one of required, prefix, pattern or oneOf must be set")
+	}
+	return nil
+}
+
+// Evaluate returns the violations found in an event map, as produced by output.EventToMap
+func (p *Policy) Evaluate(eventMap map[string]interface{}) []Violation {
+	context, _ := eventMap["context"].(map[string]interface{})
+	eventType, _ := context["type"].(string)
+
+	var violations []Violation
+	for _, rule := range p.Rules {
+		if !rule.When.matches(eventType) {
+			continue
+		}
+		if message := rule.check(eventMap); message != "" {
+			if rule.Description != "" {
+				message = fmt.Sprintf("%s: %s", rule.Description, message)
+			}
+			violations = append(violations, Violation{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Path:     rule.Field,
+				Message:  message,
+			})
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+	return violations
+}
+
+// matches reports whether the selector selects events of a type
+func (s Selector) matches(eventType string) bool {
+	if len(s.Types) == 0 {
+		return true
+	}
+	for _, pattern := range s.Types {
+		if ok, _ := path.Match(pattern, eventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns why the event breaks the rule, or an empty string
+func (r Rule) check(eventMap map[string]interface{}) string {
+	value, found := Lookup(eventMap, r.Field)
+	if !found || isEmpty(value) {
+		if r.Required {
+			return "field is required"
+		}
+		return ""
+	}
+
+	needsString := r.Prefix != "" || r.pattern != nil || len(r.OneOf) > 0
+	if !needsString {
+		return ""
+	}
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Sprintf("field must be a string, got %T", value)
+	}
+	if r.Prefix != "" && !strings.HasPrefix(s, r.Prefix) {
+		return fmt.Sprintf("%q must start with %q", s, r.Prefix)
+	}
+	if r.pattern != nil && !r.pattern.MatchString(s) {
+		return fmt.Sprintf("%q must match %s", s, r.Pattern)
+	}
+	if len(r.OneOf) > 0 && !contains(r.OneOf, s) {
+		return fmt.Sprintf("%q must be one of %s", s, strings.Join(r.OneOf, ", "))
+	}
+	return ""
+}
+
+// Lookup resolves a JSON pointer within a decoded JSON value
+func Lookup(value interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return value, true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// HasErrors reports whether any violation has the error severity
+func HasErrors(violations []Violation) bool {
+	for _, violation := range violations {
+		if violation.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty reports whether a value is null, an empty string or an empty object or array
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// contains reports whether a value is in a list
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	"github.com/brunseba/cdevents-tools/pkg/policy"
+)
+
+const testPolicy = `
+rules:
+  - name: source-url
+    description: Sources must be URLs under https://ci.example.com
+    field: /context/source
+    prefix: https://ci.example.com/
+  - name: subject-id
+    field: /subject/id
+    pattern: ^[a-z0-9-]+$
+  - name: service-environment
+    severity: warning
+    when:
+      types: ["dev.cdevents.service.*"]
+    field: /subject/content/environment/id
+    required: true
+  - name: team
+    field: /customData/team
+    oneOf: [platform, payments]
+`
+
+func TestEvaluate(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+	factory := events.NewEventFactory("https://ci.example.com/jenkins")
+
+	// Events are evaluated in the map representation of the output formatters
+	compliant, err := factory.CreateServiceEvent("deployed", "my-service", "my-service", "prod", "", &events.CustomData{Data: map[string]interface{}{"team": "platform"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	eventMap, err := output.EventToMap(compliant)
+	if err != nil {
+		t.Fatalf("failed to convert event: %v", err)
+	}
+	if violations := p.Evaluate(eventMap); len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+
+	bad, err := events.NewEventFactory("jenkins").CreateServiceEvent("deployed", "My_Service", "my-service", "", "", &events.CustomData{Data: map[string]interface{}{"team": "unknown"}})
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	eventMap, err = output.EventToMap(bad)
+	if err != nil {
+		t.Fatalf("failed to convert event: %v", err)
+	}
+	violations := p.Evaluate(eventMap)
+	got := map[string]policy.Violation{}
+	for _, violation := range violations {
+		got[violation.Rule] = violation
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 violations, got %v", violations)
+	}
+	if v := got["source-url"]; v.Severity != policy.SeverityError || !strings.Contains(v.Message, "Sources must be URLs") {
+		t.Errorf("unexpected source-url violation: %+v", v)
+	}
+	if v := got["service-environment"]; v.Severity != policy.SeverityWarning || v.Message != "field is required" {
+		t.Errorf("unexpected service-environment violation: %+v", v)
+	}
+	if !policy.HasErrors(violations) {
+		t.Errorf("expected policy errors")
+	}
+
+	// Selectors restrict rules to matching event types
+	pipeline, err := events.NewEventFactory("https://ci.example.com/jenkins").CreatePipelineRunEvent("started", "pipeline-1", "pipeline", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	eventMap, err = output.EventToMap(pipeline)
+	if err != nil {
+		t.Fatalf("failed to convert event: %v", err)
+	}
+	if violations := p.Evaluate(eventMap); len(violations) != 0 {
+		t.Errorf("expected no violations for a pipeline event, got %v", violations)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{"missing name", "rules:\n  - field: /context/source\n    required: true\n", "name is required"},
+		{"invalid field", "rules:\n  - name: r\n    field: context.source\n    required: true\n", "JSON pointer"},
+		{"no check", "rules:\n  - name: r\n    field: /context/source\n", "at least one of"},
+		{"invalid pattern", "rules:\n  - name: r\n    field: /subject/id\n    pattern: '['\n", "invalid pattern"},
+		{"invalid severity", "rules:\n  - name: r\n    field: /subject/id\n    required: true\n    severity: fatal\n", "unsupported severity"},
+		{"unknown key", "rules:\n  - name: r\n    field: /subject/id\n    regex: x\n", "regex"},
+		{"duplicate name", "rules:\n  - {name: r, field: /subject/id, required: true}\n  - {name: r, field: /context/id, required: true}\n", "duplicate rule name"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := policy.Parse([]byte(tc.policy))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(filename, []byte(testPolicy), 0o644); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	p, err := policy.Load(filename)
+	if err != nil {
+		t.Fatalf("failed to load policy: %v", err)
+	}
+	if len(p.Rules) != 4 || p.Rules[0].Severity != policy.SeverityError {
+		t.Errorf("unexpected policy: %+v", p.Rules)
+	}
+
+	if _, err := policy.Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("expected an error for a missing policy file")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	value := map[string]interface{}{
+		"a/b":   "slash",
+		"list":  []interface{}{"first", "second"},
+		"inner": map[string]interface{}{"key": "value"},
+	}
+	testCases := []struct {
+		pointer string
+		want    interface{}
+		found   bool
+	}{
+		{"/inner/key", "value", true},
+		{"/a~1b", "slash", true},
+		{"/list/1", "second", true},
+		{"/list/2", nil, false},
+		{"/inner/missing", nil, false},
+	}
+	for _, tc := range testCases {
+		got, found := policy.Lookup(value, tc.pointer)
+		if found != tc.found || (found && got != tc.want) {
+			t.Errorf("Lookup(%q) = %v, %v, want %v, %v", tc.pointer, got, found, tc.want, tc.found)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/cdevents/sdk-go/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Template describes an event in YAML, so teams can share vetted event shapes instead of long flag lists
+type Template struct {
+	// Type is the event type as <subject>.<predicate>, e.g. service.deployed or test.testcase-finished,
+	// or a custom dev.cdeventsx event type
+	Type string `yaml:"type"`
+	// Source is the event source, the factory default source when empty
+	Source string `yaml:"source,omitempty"`
+	// Subject holds the subject fields of the event
+	Subject Subject `yaml:"subject"`
+	// CustomData is set as the custom data of the event
+	CustomData interface{} `yaml:"customData,omitempty"`
+	// CustomDataContentType is the content type of CustomData, application/json when empty
+	CustomDataContentType string `yaml:"customDataContentType,omitempty"`
+	// ChainID correlates the events of a delivery flow
+	ChainID string `yaml:"chainId,omitempty"`
+	// Links links the event to the event that preceded it
+	Links Links `yaml:"links,omitempty"`
+	// Schema is the subject content schema file of custom events, relative to the template file
+	Schema string `yaml:"schema,omitempty"`
+
+	dir string
+}
+
+// Subject holds the subject fields of a template, fields the event type does not have are ignored
+type Subject struct {
+	ID           string `yaml:"id"`
+	Name         string `yaml:"name,omitempty"`
+	Source       string `yaml:"source,omitempty"`
+	URL          string `yaml:"url,omitempty"`
+	Outcome      string `yaml:"outcome,omitempty"`
+	Errors       string `yaml:"errors,omitempty"`
+	Pipeline     string `yaml:"pipeline,omitempty"`
+	Environment  string `yaml:"environment,omitempty"`
+	ArtifactID   string `yaml:"artifactId,omitempty"`
+	OutputType   string `yaml:"outputType,omitempty"`
+	OutputFormat string `yaml:"outputFormat,omitempty"`
+	// Content is the subject content of custom events
+	Content interface{} `yaml:"content,omitempty"`
+}
+
+// Links links an event to the event that preceded it
+type Links struct {
+	// From is the ID of the preceding event, added as a PATH link, no link is added when it is empty
+	From string `yaml:"from,omitempty"`
+	// Relation adds From as a RELATION link of this kind instead, e.g. caused-by
+	Relation string `yaml:"relation,omitempty"`
+}
+
+// Lookup returns the value of a template variable
+type Lookup func(name string) (string, bool)
+
+// variablePattern matches ${NAME} and ${NAME:-default} variables, $${ escapes a literal ${
+var variablePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Load reads a template file, see Parse
+func Load(filename string, lookup Lookup, overrides []string) (*Template, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+	t, err := Parse(data, lookup, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template file %s: %w", filename, err)
+	}
+	t.dir = filepath.Dir(filename)
+	return t, nil
+}
+
+// Parse expands the variables of a YAML template, sets the key=value overrides with dotted keys
+// such as subject.id and decodes the template. Variables are expanded in the scalar values of the
+// parsed template, so their values can't change its structure.
+func Parse(data []byte, lookup Lookup, overrides []string) (*Template, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	missing := map[string]bool{}
+	expandNode(&root, lookup, missing)
+	if err := undefinedError(missing); err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := root.Decode(&doc); err != nil && root.Kind != 0 {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set %q: expected key=value", override)
+		}
+		if err := set(doc, strings.TrimSpace(key), value); err != nil {
+			return nil, err
+		}
+	}
+
+	// Decode the overridden document into the template, rejecting unknown fields
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template: %w", err)
+	}
+	var t Template
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&t); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	if t.Type == "" {
+		return nil, fmt.Errorf("type is required")
+	}
+	if t.Subject.ID == "" {
+		return nil, fmt.Errorf("subject.id is required")
+	}
+	return &t, nil
+}
+
+// expandNode expands the variables of the scalar values of a YAML node, mapping keys are left as they are.
+// Plain scalars are resolved again once expanded, so ${COUNT} may still expand to a number.
+func expandNode(node *yaml.Node, lookup Lookup, missing map[string]bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		expanded := expand(node.Value, lookup, missing)
+		if expanded == node.Value {
+			return
+		}
+		node.Value = expanded
+		if node.Style&^yaml.FlowStyle == 0 {
+			node.Tag = ""
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			expandNode(node.Content[i], lookup, missing)
+		}
+	default:
+		for _, child := range node.Content {
+			expandNode(child, lookup, missing)
+		}
+	}
+}
+
+// Expand replaces the ${NAME} and ${NAME:-default} variables of a template.
+// Variables without value or default are reported together.
+func Expand(text string, lookup Lookup) (string, error) {
+	missing := map[string]bool{}
+	expanded := expand(text, lookup, missing)
+	if err := undefinedError(missing); err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+// expand replaces the variables of a text, adding variables without value or default to missing
+func expand(text string, lookup Lookup, missing map[string]bool) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		groups := variablePattern.FindStringSubmatch(match)
+		if lookup != nil {
+			if value, ok := lookup(groups[1]); ok && value != "" {
+				return value
+			}
+		}
+		if groups[2] != "" {
+			return groups[3]
+		}
+		missing[groups[1]] = true
+		return ""
+	})
+}
+
+// undefinedError reports the missing variables of a template, nil when there are none
+func undefinedError(missing map[string]bool) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("undefined template variables: %s", strings.Join(names, ", "))
+}
+
+// set sets a string value in a decoded YAML document, creating the objects of a dotted key
+func set(doc map[string]interface{}, key, value string) error {
+	keys := strings.Split(key, ".")
+	object := doc
+	for i, k := range keys {
+		if k == "" {
+			return fmt.Errorf("invalid --set key %q: empty key", key)
+		}
+		if i == len(keys)-1 {
+			object[k] = value
+			return nil
+		}
+		next, found := object[k]
+		if !found || next == nil {
+			next = map[string]interface{}{}
+			object[k] = next
+		}
+		nested, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid --set key %q: %s is not an object", key, strings.Join(keys[:i+1], "."))
+		}
+		object = nested
+	}
+	return nil
+}
+
+// Create creates the event described by the template. Options are applied after those of the template,
+// so they override it.
+func (t *Template) Create(factory *events.EventFactory, opts ...events.EventOption) (api.CDEvent, error) {
+	customData, err := t.customData()
+	if err != nil {
+		return nil, err
+	}
+
+	var templateOpts []events.EventOption
+	if t.Source != "" {
+		templateOpts = append(templateOpts, events.WithSource(t.Source))
+	}
+	if t.Subject.Source != "" {
+		templateOpts = append(templateOpts, events.WithSubjectSource(t.Subject.Source))
+	}
+	if t.ChainID != "" {
+		templateOpts = append(templateOpts, events.WithChainID(t.ChainID))
+	}
+	// No link is added when links.from expands to nothing, e.g. for the first event of a flow
+	switch {
+	case t.Links.From == "":
+	case t.Links.Relation != "":
+		templateOpts = append(templateOpts, events.WithRelationLink(t.Links.From, t.Links.Relation))
+	default:
+		templateOpts = append(templateOpts, events.WithPathLink(t.Links.From))
+	}
+	opts = append(templateOpts, opts...)
+
+	s := t.Subject
+	if strings.HasPrefix(t.Type, api.CustomEventTypeRoot+".") {
+		var schema *events.CustomSchema
+		if t.Schema != "" {
+			filename := t.Schema
+			if !filepath.IsAbs(filename) {
+				filename = filepath.Join(t.dir, filename)
+			}
+			if schema, err = events.LoadCustomSchema(filename); err != nil {
+				return nil, err
+			}
+		}
+		return factory.CreateCustomEvent(t.Type, s.ID, s.Content, schema, customData, opts...)
+	}
+
+	kind, eventType, ok := strings.Cut(t.Type, ".")
+	if !ok {
+		return nil, fmt.Errorf("invalid type %q: expected <subject>.<predicate>, e.g. service.deployed", t.Type)
+	}
+	switch kind {
+	case "pipeline":
+		return factory.CreatePipelineRunEvent(eventType, s.ID, s.Name, s.Outcome, s.Errors, s.URL, customData, opts...)
+	case "task":
+		return factory.CreateTaskRunEvent(eventType, s.ID, s.Name, s.Pipeline, s.Outcome, s.Errors, s.URL, customData, opts...)
+	case "build":
+		return factory.CreateBuildEvent(eventType, s.ID, s.Name, s.Outcome, s.Errors, s.URL, customData,
+			append(opts, events.WithArtifactID(s.ArtifactID))...)
+	case "service":
+		return factory.CreateServiceEvent(eventType, s.ID, s.Name, s.Environment, s.URL, customData,
+			append(opts, events.WithArtifactID(s.ArtifactID))...)
+	case "test":
+		return factory.CreateTestEvent(eventType, s.ID, s.Name, s.Outcome, s.Errors, s.URL, customData,
+			append(opts, events.WithEnvironment(s.Environment), events.WithTestOutput(s.OutputType, s.OutputFormat))...)
+	default:
+		return nil, fmt.Errorf("unsupported event subject %q (supported: pipeline, task, build, service, test, or a %s type)", kind, api.CustomEventTypeRoot)
+	}
+}
+
+// customData returns the custom data of the template, nil when it has none
+func (t *Template) customData() (*events.CustomData, error) {
+	if t.CustomData == nil {
+		return nil, nil
+	}
+	if t.CustomDataContentType != "" && t.CustomDataContentType != events.JSONContentType {
+		data, ok := t.CustomData.(string)
+		if !ok {
+			return nil, fmt.Errorf("customData must be a string for content type %s", t.CustomDataContentType)
+		}
+		return events.ParseCustomData([]byte(data), t.CustomDataContentType)
+	}
+	data, err := yaml.Marshal(t.CustomData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal custom data: %w", err)
+	}
+	return events.ParseCustomDataFromYAML(string(data))
+}
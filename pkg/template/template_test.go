@@ -0,0 +1,157 @@
+package template_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/template"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+func lookup(vars map[string]string) template.Lookup {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func TestExpand(t *testing.T) {
+	expanded, err := template.Expand("env: ${ENV}\nteam: ${TEAM:-platform}\nliteral: $${ENV}\n", lookup(map[string]string{"ENV": "prod"}))
+	if err != nil {
+		t.Fatalf("failed to expand template: %v", err)
+	}
+	if expanded != "env: prod\nteam: platform\nliteral: ${ENV}\n" {
+		t.Errorf("unexpected expansion: %q", expanded)
+	}
+
+	_, err = template.Expand("${B} ${A} ${B} ${C:-}", lookup(nil))
+	if err == nil || !strings.Contains(err.Error(), "undefined template variables: A, B") {
+		t.Errorf("expected the undefined variables to be reported together, got %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	data := []byte("type: service.deployed\nsubject:\n  id: ${SERVICE}\n  environment: staging\n")
+	tmpl, err := template.Parse(data, lookup(map[string]string{"SERVICE": "checkout"}), []string{"subject.environment=prod", "links.from=event-1"})
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	if tmpl.Subject.ID != "checkout" || tmpl.Subject.Environment != "prod" || tmpl.Links.From != "event-1" {
+		t.Errorf("unexpected template: %+v", tmpl)
+	}
+
+	// Values can't change the structure of the template
+	data = []byte("type: service.deployed\nsubject:\n  id: ${SERVICE}\n  name: \"${NAME}\"\n  environment: ${ENV}\ncustomData:\n  replicas: ${REPLICAS}\n")
+	tmpl, err = template.Parse(data, lookup(map[string]string{"SERVICE": "a: b", "NAME": "#1 build", "ENV": "prod\nurl: https://evil.example.com", "REPLICAS": "3"}), nil)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	if tmpl.Subject.ID != "a: b" || tmpl.Subject.Name != "#1 build" || tmpl.Subject.Environment != "prod\nurl: https://evil.example.com" || tmpl.Subject.URL != "" {
+		t.Errorf("expected variables to expand into values only, got %+v", tmpl.Subject)
+	}
+	if !reflect.DeepEqual(tmpl.CustomData, map[string]interface{}{"replicas": 3}) {
+		t.Errorf("expected plain values to be resolved once expanded, got %#v", tmpl.CustomData)
+	}
+
+	testCases := []struct {
+		name      string
+		data      string
+		overrides []string
+		want      string
+	}{
+		{"missing type", "subject:\n  id: a\n", nil, "type is required"},
+		{"missing subject ID", "type: service.deployed\n", nil, "subject.id is required"},
+		{"unknown field", "type: service.deployed\nsubject:\n  id: a\n  colour: red\n", nil, "colour"},
+		{"invalid override", "type: service.deployed\nsubject:\n  id: a\n", []string{"subject.id"}, "expected key=value"},
+		{"override of a scalar", "type: service.deployed\nsubject:\n  id: a\n", []string{"type.name=b"}, "type is not an object"},
+		{"invalid YAML", "type: [service", nil, "failed to parse template"},
+		{"undefined variables", "type: ${TYPE}\nsubject:\n  id: ${ID}\n", nil, "undefined template variables: ID, TYPE"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := template.Parse([]byte(tc.data), lookup(nil), tc.overrides)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	factory := events.NewEventFactory("https://ci.example.com")
+	data := []byte(`type: service.deployed
+source: https://deployer.example.com
+subject:
+  id: checkout
+  environment: prod
+  artifactId: pkg:oci/checkout@sha256:abc
+customData:
+  team: platform
+chainId: chain-1
+links:
+  from: event-1
+  relation: caused-by
+`)
+	tmpl, err := template.Parse(data, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	event, err := tmpl.Create(factory, events.WithEventID("event-2"))
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	if event.GetId() != "event-2" || event.GetSource() != "https://deployer.example.com" || event.GetSubjectId() != "checkout" {
+		t.Errorf("unexpected context: %s %s %s", event.GetId(), event.GetSource(), event.GetSubjectId())
+	}
+	if customData, err := event.GetCustomData(); err != nil || !reflect.DeepEqual(customData, map[string]interface{}{"team": "platform"}) {
+		t.Errorf("unexpected custom data: %v, %v", customData, err)
+	}
+	v04, ok := event.(api.CDEventReaderV04)
+	if !ok || v04.GetChainId() != "chain-1" || len(v04.GetLinks()) != 1 {
+		t.Errorf("expected the chain ID and link of the template")
+	}
+
+	tmpl, err = template.Parse([]byte("type: artifact.published\nsubject:\n  id: a\n"), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	if _, err := tmpl.Create(factory); err == nil || !strings.Contains(err.Error(), "unsupported event subject") {
+		t.Errorf("expected an unsupported subject error, got %v", err)
+	}
+}
+
+func TestLoadCustomEvent(t *testing.T) {
+	dir := t.TempDir()
+	schema := `{"$id": "https://schemas.example.com/template-test/flag.json", "type": "object", "required": ["enabled"]}`
+	if err := os.WriteFile(filepath.Join(dir, "flag.json"), []byte(schema), 0o644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	filename := filepath.Join(dir, "flag.yaml")
+	data := "type: dev.cdeventsx.flags-flag.flipped.0.1.0\nschema: flag.json\nsubject:\n  id: checkout-v2\n  content:\n    enabled: ${ENABLED}\n"
+	if err := os.WriteFile(filename, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	tmpl, err := template.Load(filename, lookup(map[string]string{"ENABLED": "true"}), nil)
+	if err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+	event, err := tmpl.Create(events.NewEventFactory("https://ci.example.com"))
+	if err != nil {
+		t.Fatalf("failed to create custom event: %v", err)
+	}
+	if v04, ok := event.(api.CDEventReaderV04); !ok || v04.GetSchemaUri() != "https://schemas.example.com/template-test/flag.json" {
+		t.Errorf("expected the schema relative to the template to be set")
+	}
+	if content, _ := event.GetSubjectContent().(map[string]interface{}); content["enabled"] != true {
+		t.Errorf("unexpected subject content: %v", event.GetSubjectContent())
+	}
+
+	if _, err := template.Load(filepath.Join(dir, "missing.yaml"), nil, nil); err == nil {
+		t.Errorf("expected an error for a missing template file")
+	}
+}
@@ -0,0 +1,226 @@
+package testresults
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxMessageLines bounds the output lines kept as message of failed tests and packages
+const maxMessageLines = 20
+
+// goTestEvent is an event of a go test -json stream, see go doc test2json
+type goTestEvent struct {
+	Time        time.Time
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     float64
+	Output      string
+	ImportPath  string
+	FailedBuild string
+}
+
+// goTestPackage is the state of a package of a go test -json stream
+type goTestPackage struct {
+	suite    *Suite
+	firstAt  time.Time
+	lastAt   time.Time
+	started  bool
+	running  map[string]*Case
+	output   map[string][]string
+	finished bool
+}
+
+// ReadGoTest reads a go test -json stream, passing each package to the handler as a suite and each test,
+// including subtests, as a case while the stream is read. As go test only reports skips once a test ran,
+// cases are started when they finish, with the time they started, so that skipped cases are finished
+// without being started as by Replay. Packages without tests that ran are left out, unless they failed,
+// e.g. to build. Lines that are not JSON, such as build output, are skipped.
+func ReadGoTest(r io.Reader, h Handler) error {
+	packages := map[string]*goTestPackage{}
+	var order []string
+	// buildOutput holds the compiler output of packages, by import path
+	buildOutput := map[string][]string{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("failed to parse go test event: %w", err)
+		}
+		if event.Action == "build-output" && !strings.HasPrefix(event.Output, "# ") {
+			buildOutput[event.ImportPath] = appendOutput(buildOutput[event.ImportPath], event.Output, false)
+		}
+		if event.Package == "" {
+			continue
+		}
+		pkg, ok := packages[event.Package]
+		if !ok {
+			pkg = &goTestPackage{
+				suite:   &Suite{ID: event.Package, Name: event.Package},
+				firstAt: event.Time,
+				running: map[string]*Case{},
+				output:  map[string][]string{},
+			}
+			packages[event.Package] = pkg
+			order = append(order, event.Package)
+		}
+		if event.FailedBuild != "" {
+			pkg.output[""] = append(buildOutput[event.FailedBuild], pkg.output[""]...)
+		}
+		if err := pkg.handle(event, h); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read go test stream: %w", err)
+	}
+
+	// Finish the packages of an interrupted stream
+	for _, name := range order {
+		if pkg := packages[name]; pkg.started && !pkg.finished {
+			pkg.suite.Error = "test stream ended before the package finished"
+			if err := pkg.finish(pkg.lastAt, h); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// handle passes the runs started or finished by an event of the package to the handler
+func (p *goTestPackage) handle(event goTestEvent, h Handler) error {
+	if !event.Time.IsZero() {
+		p.lastAt = event.Time
+	}
+	switch event.Action {
+	case "output":
+		p.output[event.Test] = appendOutput(p.output[event.Test], event.Output, event.Test == "")
+	case "run":
+		if err := p.start(event.Time, h); err != nil {
+			return err
+		}
+		p.running[event.Test] = &Case{ID: p.suite.ID + "." + event.Test, Name: event.Test, Started: event.Time}
+	case "pass", "fail", "skip":
+		if event.Test == "" {
+			return p.packageFinished(event, h)
+		}
+		testCase, ok := p.running[event.Test]
+		if !ok {
+			return nil
+		}
+		delete(p.running, event.Test)
+		testCase.Duration = time.Duration(event.Elapsed * float64(time.Second))
+		testCase.Outcome = map[string]string{"pass": OutcomePass, "fail": OutcomeFail, "skip": OutcomeSkip}[event.Action]
+		if event.Action != "pass" {
+			testCase.Message = strings.Join(p.output[event.Test], "\n")
+		}
+		delete(p.output, event.Test)
+		return p.caseFinished(testCase, h)
+	}
+	return nil
+}
+
+// packageFinished finishes a package, which is only reported without tests when it failed
+func (p *goTestPackage) packageFinished(event goTestEvent, h Handler) error {
+	if event.Action == "fail" && p.suite.Outcome() == OutcomePass {
+		p.suite.Error = strings.Join(p.output[""], "\n")
+		if p.suite.Error == "" {
+			p.suite.Error = "package failed"
+		}
+	}
+	if !p.started && p.suite.Error == "" {
+		p.finished = true
+		return nil
+	}
+	if err := p.start(p.firstAt, h); err != nil {
+		return err
+	}
+	p.suite.Duration = time.Duration(event.Elapsed * float64(time.Second))
+	return p.finish(event.Time, h)
+}
+
+// start passes the package to the handler the first time one of its runs starts
+func (p *goTestPackage) start(at time.Time, h Handler) error {
+	if p.started {
+		return nil
+	}
+	p.started = true
+	p.suite.Started = p.firstAt
+	if p.firstAt.IsZero() {
+		p.suite.Started = at
+	}
+	return h.SuiteStarted(p.suite)
+}
+
+// finish finishes the tests still running as errored, e.g. after a panic or a timeout, then the package
+func (p *goTestPackage) finish(at time.Time, h Handler) error {
+	p.finished = true
+	names := make([]string, 0, len(p.running))
+	for name := range p.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		testCase := p.running[name]
+		testCase.Outcome = OutcomeError
+		testCase.Message = "test did not finish"
+		if output := p.output[name]; len(output) > 0 {
+			testCase.Message += ": " + strings.Join(output, "\n")
+		}
+		if !at.IsZero() {
+			testCase.Duration = at.Sub(testCase.Started)
+		}
+		if err := p.caseFinished(testCase, h); err != nil {
+			return err
+		}
+	}
+	p.running = map[string]*Case{}
+	if p.suite.Duration == 0 && !at.IsZero() {
+		p.suite.Duration = at.Sub(p.suite.Started)
+	}
+	return h.SuiteFinished(p.suite)
+}
+
+// caseFinished passes a finished case to the handler, started first unless it was skipped
+func (p *goTestPackage) caseFinished(testCase *Case, h Handler) error {
+	if testCase.Outcome != OutcomeSkip {
+		if err := h.CaseStarted(p.suite, *testCase); err != nil {
+			return err
+		}
+	}
+	p.suite.Cases = append(p.suite.Cases, *testCase)
+	return h.CaseFinished(p.suite, *testCase)
+}
+
+// appendOutput appends an output line of a test or package, leaving out the status lines of go test
+func appendOutput(lines []string, output string, pkg bool) []string {
+	line := strings.TrimSpace(output)
+	prefixes := []string{"=== ", "--- "}
+	if pkg {
+		prefixes = append(prefixes, "PASS", "FAIL", "ok ", "? ", "coverage:")
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(line, prefix) {
+			return lines
+		}
+	}
+	if line == "" {
+		return lines
+	}
+	lines = append(lines, line)
+	if len(lines) > maxMessageLines {
+		lines = lines[len(lines)-maxMessageLines:]
+	}
+	return lines
+}
@@ -0,0 +1,71 @@
+package testresults_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/testresults"
+)
+
+const goTestStream = `{"Time":"2024-05-01T12:00:00Z","Action":"start","Package":"example.com/a"}
+{"Time":"2024-05-01T12:00:01Z","Action":"run","Package":"example.com/a","Test":"TestPass"}
+{"Time":"2024-05-01T12:00:02Z","Action":"pass","Package":"example.com/a","Test":"TestPass","Elapsed":1}
+{"Time":"2024-05-01T12:00:02Z","Action":"run","Package":"example.com/a","Test":"TestFail"}
+{"Time":"2024-05-01T12:00:02Z","Action":"output","Package":"example.com/a","Test":"TestFail","Output":"=== RUN   TestFail\n"}
+{"Time":"2024-05-01T12:00:02Z","Action":"output","Package":"example.com/a","Test":"TestFail","Output":"    a_test.go:6: expected 1, got 2\n"}
+{"Time":"2024-05-01T12:00:02Z","Action":"output","Package":"example.com/a","Test":"TestFail","Output":"--- FAIL: TestFail (0.50s)\n"}
+{"Time":"2024-05-01T12:00:03Z","Action":"fail","Package":"example.com/a","Test":"TestFail","Elapsed":0.5}
+{"Time":"2024-05-01T12:00:03Z","Action":"run","Package":"example.com/a","Test":"TestSkip"}
+{"Time":"2024-05-01T12:00:03Z","Action":"output","Package":"example.com/a","Test":"TestSkip","Output":"    a_test.go:7: needs docker\n"}
+{"Time":"2024-05-01T12:00:03Z","Action":"skip","Package":"example.com/a","Test":"TestSkip","Elapsed":0}
+{"Time":"2024-05-01T12:00:03Z","Action":"output","Package":"example.com/a","Output":"FAIL\n"}
+{"Time":"2024-05-01T12:00:04Z","Action":"fail","Package":"example.com/a","Elapsed":4}
+{"ImportPath":"example.com/b [example.com/b.test]","Action":"build-output","Output":"# example.com/b [example.com/b.test]\n"}
+{"ImportPath":"example.com/b [example.com/b.test]","Action":"build-output","Output":"b/b_test.go:5:33: undefined: helper\n"}
+{"ImportPath":"example.com/b [example.com/b.test]","Action":"build-fail"}
+{"Time":"2024-05-01T12:00:05Z","Action":"start","Package":"example.com/b"}
+{"Time":"2024-05-01T12:00:05Z","Action":"fail","Package":"example.com/b","Elapsed":0,"FailedBuild":"example.com/b [example.com/b.test]"}
+FAIL	example.com/b [build failed]
+{"Time":"2024-05-01T12:00:05Z","Action":"start","Package":"example.com/c"}
+{"Time":"2024-05-01T12:00:05Z","Action":"skip","Package":"example.com/c","Elapsed":0}
+{"Time":"2024-05-01T12:00:06Z","Action":"start","Package":"example.com/d"}
+{"Time":"2024-05-01T12:00:07Z","Action":"run","Package":"example.com/d","Test":"TestHang"}
+`
+
+func TestReadGoTest(t *testing.T) {
+	r := &recorder{}
+	if err := testresults.ReadGoTest(strings.NewReader(goTestStream), r); err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	want := []string{
+		"suite started example.com/a",
+		"case started example.com/a.TestPass", "case pass example.com/a.TestPass",
+		"case started example.com/a.TestFail", "case fail example.com/a.TestFail",
+		// Skipped cases are finished without being started
+		"case skip example.com/a.TestSkip",
+		"suite fail example.com/a",
+		"suite started example.com/b", "suite error example.com/b",
+		"suite started example.com/d", "case started example.com/d.TestHang", "case error example.com/d.TestHang", "suite error example.com/d",
+	}
+	if got := strings.Join(r.runs, "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("unexpected runs:\n got %s\nwant %s", got, strings.Join(want, "\n"))
+	}
+
+	if failed := r.cases["example.com/a.TestFail"]; failed.Message != "a_test.go:6: expected 1, got 2" || failed.Duration != 500*time.Millisecond {
+		t.Errorf("unexpected failed case: %+v", failed)
+	}
+	if skipped := r.cases["example.com/a.TestSkip"]; skipped.Message != "a_test.go:7: needs docker" {
+		t.Errorf("unexpected skipped case: %+v", skipped)
+	}
+	a := r.suites[0]
+	if !a.Started.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) || a.Duration != 4*time.Second || a.Error != "" {
+		t.Errorf("unexpected package: %+v", a)
+	}
+	if b := r.suites[1]; b.Error != "b/b_test.go:5:33: undefined: helper" {
+		t.Errorf("expected the build output as error, got %q", b.Error)
+	}
+	if d := r.suites[2]; !strings.Contains(d.Error, "ended before the package finished") || d.Duration != time.Second {
+		t.Errorf("expected the interrupted package to error, got %+v", d)
+	}
+}
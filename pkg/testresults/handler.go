@@ -0,0 +1,32 @@
+package testresults
+
+// Handler receives the suite and case runs of a test stream as they start and finish.
+// Suites passed to CaseFinished and SuiteFinished hold the cases that finished so far.
+type Handler interface {
+	SuiteStarted(suite *Suite) error
+	CaseStarted(suite *Suite, testCase Case) error
+	CaseFinished(suite *Suite, testCase Case) error
+	SuiteFinished(suite *Suite) error
+}
+
+// Replay passes a complete suite to a handler, skipped cases are finished without being started
+func Replay(suite *Suite, h Handler) error {
+	cases := suite.Cases
+	run := *suite
+	run.Cases = nil
+	if err := h.SuiteStarted(&run); err != nil {
+		return err
+	}
+	for _, testCase := range cases {
+		if testCase.Outcome != OutcomeSkip {
+			if err := h.CaseStarted(&run, testCase); err != nil {
+				return err
+			}
+		}
+		run.Cases = append(run.Cases, testCase)
+		if err := h.CaseFinished(&run, testCase); err != nil {
+			return err
+		}
+	}
+	return h.SuiteFinished(&run)
+}
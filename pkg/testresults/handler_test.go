@@ -0,0 +1,57 @@
+package testresults_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/testresults"
+)
+
+// recorder records the runs passed to a handler, one line per run
+type recorder struct {
+	runs   []string
+	suites []testresults.Suite
+	cases  map[string]testresults.Case
+}
+
+func (r *recorder) SuiteStarted(suite *testresults.Suite) error {
+	r.runs = append(r.runs, "suite started "+suite.ID)
+	return nil
+}
+
+func (r *recorder) CaseStarted(suite *testresults.Suite, testCase testresults.Case) error {
+	r.runs = append(r.runs, "case started "+testCase.ID)
+	return nil
+}
+
+func (r *recorder) CaseFinished(suite *testresults.Suite, testCase testresults.Case) error {
+	r.runs = append(r.runs, fmt.Sprintf("case %s %s", testCase.Outcome, testCase.ID))
+	if r.cases == nil {
+		r.cases = map[string]testresults.Case{}
+	}
+	r.cases[testCase.ID] = testCase
+	return nil
+}
+
+func (r *recorder) SuiteFinished(suite *testresults.Suite) error {
+	r.runs = append(r.runs, fmt.Sprintf("suite %s %s", suite.Outcome(), suite.ID))
+	r.suites = append(r.suites, *suite)
+	return nil
+}
+
+func TestReplay(t *testing.T) {
+	suite := testresults.Suite{ID: "s", Started: time.Now(), Cases: []testresults.Case{
+		{ID: "a", Outcome: testresults.OutcomePass},
+		{ID: "b", Outcome: testresults.OutcomeSkip},
+	}}
+	r := &recorder{}
+	if err := testresults.Replay(&suite, r); err != nil {
+		t.Fatal(err)
+	}
+	want := "suite started s|case started a|case pass a|case skip b|suite pass s"
+	if got := strings.Join(r.runs, "|"); got != want {
+		t.Errorf("unexpected runs:\n got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package testresults
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// junitSuite is a testsuites or testsuite element of a JUnit report, suites may be nested
+type junitSuite struct {
+	XMLName   xml.Name
+	ID        string       `xml:"id,attr"`
+	Name      string       `xml:"name,attr"`
+	Timestamp string       `xml:"timestamp,attr"`
+	Time      string       `xml:"time,attr"`
+	Cases     []junitCase  `xml:"testcase"`
+	Suites    []junitSuite `xml:"testsuite"`
+}
+
+type junitCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Timestamp string         `xml:"timestamp,attr"`
+	Time      string         `xml:"time,attr"`
+	Failures  []junitMessage `xml:"failure"`
+	Errors    []junitMessage `xml:"error"`
+	Skipped   *junitMessage  `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// text returns the message attribute, or the element text when there is none
+func (m junitMessage) text() string {
+	if m.Message != "" {
+		return m.Message
+	}
+	return strings.TrimSpace(m.Text)
+}
+
+// ParseJUnit reads the test suites of a JUnit XML report, whose root is a testsuites or a testsuite element.
+// Nested suites are returned as suites of their own. Cases without timestamp are assumed to run one after
+// another from the start of their suite; suites without timestamp have a zero start, see Suite.EndAt.
+func ParseJUnit(r io.Reader) ([]Suite, error) {
+	var root junitSuite
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse JUnit report: %w", err)
+	}
+	switch root.XMLName.Local {
+	case "testsuites":
+		root.Cases = nil
+	case "testsuite":
+		root = junitSuite{Suites: []junitSuite{root}}
+	default:
+		return nil, fmt.Errorf("failed to parse JUnit report: unexpected root element %q", root.XMLName.Local)
+	}
+
+	var suites []Suite
+	var collect func(parent junitSuite) error
+	collect = func(parent junitSuite) error {
+		for _, s := range parent.Suites {
+			if len(s.Cases) > 0 || len(s.Suites) == 0 {
+				suite, err := junitToSuite(s)
+				if err != nil {
+					return err
+				}
+				suites = append(suites, suite)
+			}
+			if err := collect(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := collect(root); err != nil {
+		return nil, err
+	}
+	return suites, nil
+}
+
+// junitToSuite converts a testsuite element and its cases
+func junitToSuite(s junitSuite) (Suite, error) {
+	suite := Suite{ID: s.Name, Name: s.Name}
+	if suite.ID == "" {
+		suite.ID = s.ID
+	}
+	var err error
+	if suite.Started, err = parseJUnitTimestamp(s.Timestamp); err != nil {
+		return suite, fmt.Errorf("invalid timestamp of test suite %s: %w", suite.Name, err)
+	}
+
+	var total time.Duration
+	for _, c := range s.Cases {
+		testCase := Case{ID: c.Name, Name: c.Name, Outcome: OutcomePass}
+		if c.ClassName != "" {
+			testCase.ID = c.ClassName + "." + c.Name
+		}
+		if testCase.Started, err = parseJUnitTimestamp(c.Timestamp); err != nil {
+			return suite, fmt.Errorf("invalid timestamp of test case %s: %w", testCase.ID, err)
+		}
+		if testCase.Duration, err = parseJUnitTime(c.Time); err != nil {
+			return suite, fmt.Errorf("invalid time of test case %s: %w", testCase.ID, err)
+		}
+		switch {
+		case len(c.Errors) > 0:
+			testCase.Outcome, testCase.Message = OutcomeError, c.Errors[0].text()
+		case len(c.Failures) > 0:
+			testCase.Outcome, testCase.Message = OutcomeFail, c.Failures[0].text()
+		case c.Skipped != nil:
+			testCase.Outcome, testCase.Message = OutcomeSkip, c.Skipped.text()
+		}
+		total += testCase.Duration
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if s.Time == "" {
+		suite.Duration = total
+	} else if suite.Duration, err = parseJUnitTime(s.Time); err != nil {
+		return suite, fmt.Errorf("invalid time of test suite %s: %w", suite.Name, err)
+	}
+	if !suite.Started.IsZero() {
+		suite.layoutCases()
+	}
+	return suite, nil
+}
+
+// parseJUnitTimestamp parses ISO 8601 timestamps, those without time zone are in local time
+func parseJUnitTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05", value, time.Local)
+}
+
+// parseJUnitTime parses durations in seconds, such as 1.5 or 1,234.5
+func parseJUnitTime(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("%q is not a number of seconds", value)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,81 @@
+package testresults_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/testresults"
+)
+
+const junitReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="com.acme.CartTest" timestamp="2024-05-01T12:00:00Z" time="4">
+    <testcase name="addsItem" classname="com.acme.CartTest" time="1.25"/>
+    <testcase name="removesItem" classname="com.acme.CartTest" time="2.25">
+      <failure message="expected 0 items but was 1" type="AssertionError">at CartTest.java:42</failure>
+    </testcase>
+    <testcase name="checksOut" classname="com.acme.CartTest"><skipped message="payment sandbox down"/></testcase>
+  </testsuite>
+  <testsuite name="integration">
+    <testsuite name="db" time="1,500.5">
+      <testcase name="migrates" time="0.5"><error>connection refused</error></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+func TestParseJUnit(t *testing.T) {
+	suites, err := testresults.ParseJUnit(strings.NewReader(junitReport))
+	if err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+	if len(suites) != 2 || suites[0].Name != "com.acme.CartTest" || suites[1].Name != "db" {
+		t.Fatalf("expected the suites with cases, got %+v", suites)
+	}
+
+	cart := suites[0]
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if !cart.Started.Equal(start) || cart.Duration != 4*time.Second || !cart.Finished().Equal(start.Add(4*time.Second)) {
+		t.Errorf("unexpected suite times: %v %v", cart.Started, cart.Duration)
+	}
+	removes := cart.Cases[1]
+	if removes.ID != "com.acme.CartTest.removesItem" || removes.Outcome != testresults.OutcomeFail || removes.Message != "expected 0 items but was 1" {
+		t.Errorf("unexpected failed case: %+v", removes)
+	}
+	if !removes.Started.Equal(start.Add(1250 * time.Millisecond)) {
+		t.Errorf("expected cases to run one after another, got %v", removes.Started)
+	}
+	if checksOut := cart.Cases[2]; checksOut.Outcome != testresults.OutcomeSkip || checksOut.Message != "payment sandbox down" {
+		t.Errorf("unexpected skipped case: %+v", checksOut)
+	}
+	if cart.Outcome() != testresults.OutcomeFail || cart.Summary() != "3 tests, 1 failed, 1 skipped" {
+		t.Errorf("unexpected suite outcome: %s %q", cart.Outcome(), cart.Summary())
+	}
+
+	db := suites[1]
+	if db.Outcome() != testresults.OutcomeError || db.Cases[0].Message != "connection refused" {
+		t.Errorf("expected the errored case, got %+v", db.Cases[0])
+	}
+	if db.Duration != 1500500*time.Millisecond || !db.Started.IsZero() {
+		t.Errorf("unexpected suite times: %v %v", db.Started, db.Duration)
+	}
+	end := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	db.EndAt(end)
+	if !db.Finished().Equal(end) || !db.Cases[0].Started.Equal(db.Started) {
+		t.Errorf("expected the suite to end at %v, got %v", end, db.Finished())
+	}
+}
+
+func TestParseJUnitErrors(t *testing.T) {
+	testCases := map[string]string{
+		"not xml":      "results",
+		"root element": `<report></report>`,
+		"time":         `<testsuite name="s"><testcase name="c" time="fast"/></testsuite>`,
+		"timestamp":    `<testsuite name="s" timestamp="yesterday"/>`,
+	}
+	for name, report := range testCases {
+		if _, err := testresults.ParseJUnit(strings.NewReader(report)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package testresults
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	tapPlan   = regexp.MustCompile(`^1\.\.(\d+)`)
+	tapResult = regexp.MustCompile(`^(not )?ok\b\s*(\d*)\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(\S+)\s*(.*))?$`)
+)
+
+// tapStream is the state of a TAP stream
+type tapStream struct {
+	suite   *Suite
+	h       Handler
+	planned int
+	last    time.Time
+	// pending is a failed case waiting for the YAML block that may follow it
+	pending *Case
+	yaml    []string
+	inYAML  bool
+}
+
+// ReadTAP reads a TAP stream, passing it to the handler as a suite and each test point as a case while the
+// stream is read. TAP has no timings: cases are timed from the previous test point to the line reporting them.
+// Skipped tests and failed TODO tests are skipped cases, the message of failed tests is read from their YAML
+// block. Subtests and comments are ignored, a bail out or a plan that was not run make the suite error.
+func ReadTAP(r io.Reader, name string, h Handler) error {
+	t := &tapStream{suite: &Suite{ID: name, Name: name}, h: h}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if t.last.IsZero() {
+			t.last = time.Now()
+			t.suite.Started = t.last
+			if err := h.SuiteStarted(t.suite); err != nil {
+				return err
+			}
+		}
+		bailedOut, err := t.line(scanner.Text())
+		if err != nil {
+			return err
+		}
+		if bailedOut {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read TAP stream: %w", err)
+	}
+	if t.last.IsZero() {
+		return fmt.Errorf("failed to read TAP stream: no input")
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+	if t.suite.Error == "" && len(t.suite.Cases) < t.planned {
+		t.suite.Error = fmt.Sprintf("planned %d tests, ran %d", t.planned, len(t.suite.Cases))
+	}
+	t.suite.Duration = time.Since(t.suite.Started)
+	return h.SuiteFinished(t.suite)
+}
+
+// line handles a line of the stream, returning true on a bail out
+func (t *tapStream) line(line string) (bool, error) {
+	if t.inYAML {
+		if strings.TrimSpace(line) == "..." {
+			t.inYAML = false
+			return false, t.flush()
+		}
+		t.yaml = append(t.yaml, line)
+		return false, nil
+	}
+	if t.pending != nil && strings.TrimSpace(line) == "---" && strings.HasPrefix(line, " ") {
+		t.inYAML = true
+		return false, nil
+	}
+	if err := t.flush(); err != nil {
+		return false, err
+	}
+
+	// Indented lines belong to subtests
+	if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+		return false, nil
+	}
+	if strings.HasPrefix(line, "Bail out!") {
+		t.suite.Error = line
+		return true, nil
+	}
+	if match := tapPlan.FindStringSubmatch(line); match != nil {
+		t.planned, _ = strconv.Atoi(match[1])
+		return false, nil
+	}
+	match := tapResult.FindStringSubmatch(line)
+	if match == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	number := match[2]
+	if number == "" {
+		number = strconv.Itoa(len(t.suite.Cases) + 1)
+	}
+	testCase := &Case{ID: t.suite.ID + "." + number, Name: match[3], Started: t.last, Duration: now.Sub(t.last), Outcome: OutcomePass}
+	if testCase.Name == "" {
+		testCase.Name = "test " + number
+	}
+	t.last = now
+	directive, reason := strings.ToUpper(match[4]), match[5]
+	failed := match[1] != ""
+	switch {
+	case strings.HasPrefix(directive, "SKIP"):
+		testCase.Outcome, testCase.Message = OutcomeSkip, reason
+	case directive == "TODO" && failed:
+		testCase.Outcome, testCase.Message = OutcomeSkip, strings.TrimSpace("TODO "+reason)
+	case failed:
+		testCase.Outcome = OutcomeFail
+		t.pending = testCase
+		return false, nil
+	}
+	return false, t.finish(*testCase)
+}
+
+// flush finishes the pending failed case, with the message of its YAML block if any
+func (t *tapStream) flush() error {
+	if t.pending == nil {
+		return nil
+	}
+	testCase := *t.pending
+	if len(t.yaml) > 0 {
+		var diagnostics map[string]interface{}
+		if err := yaml.Unmarshal([]byte(strings.Join(t.yaml, "\n")), &diagnostics); err == nil {
+			if message, ok := diagnostics["message"].(string); ok {
+				testCase.Message = message
+			}
+		}
+	}
+	t.pending, t.yaml, t.inYAML = nil, nil, false
+	return t.finish(testCase)
+}
+
+// finish passes a case to the handler
+func (t *tapStream) finish(testCase Case) error {
+	if testCase.Outcome != OutcomeSkip {
+		if err := t.h.CaseStarted(t.suite, testCase); err != nil {
+			return err
+		}
+	}
+	t.suite.Cases = append(t.suite.Cases, testCase)
+	return t.h.CaseFinished(t.suite, testCase)
+}
@@ -0,0 +1,60 @@
+package testresults_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/testresults"
+)
+
+func TestReadTAP(t *testing.T) {
+	stream := `TAP version 13
+1..6
+ok 1 - adds item
+not ok 2 - removes item
+  ---
+  message: "expected 0 items"
+  severity: fail
+  ...
+ok 3 - checks out # SKIP sandbox down
+not ok 4 - refunds # TODO not written
+# Subtest: nested
+    ok 1 - ignored
+ok 5
+not ok 6 - last
+`
+	r := &recorder{}
+	if err := testresults.ReadTAP(strings.NewReader(stream), "cart", r); err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	want := "suite started cart|case started cart.1|case pass cart.1|case started cart.2|case fail cart.2|case skip cart.3|case skip cart.4|" +
+		"case started cart.5|case pass cart.5|case started cart.6|case fail cart.6|suite fail cart"
+	if got := strings.Join(r.runs, "|"); got != want {
+		t.Fatalf("unexpected runs:\n got %s\nwant %s", got, want)
+	}
+	if c := r.cases["cart.2"]; c.Name != "removes item" || c.Message != "expected 0 items" {
+		t.Errorf("unexpected failed case: %+v", c)
+	}
+	if c := r.cases["cart.3"]; c.Message != "sandbox down" {
+		t.Errorf("unexpected skipped case: %+v", c)
+	}
+	if c := r.cases["cart.4"]; c.Message != "TODO not written" {
+		t.Errorf("unexpected TODO case: %+v", c)
+	}
+	if c := r.cases["cart.5"]; c.Name != "test 5" {
+		t.Errorf("expected a name for the case without description, got %q", c.Name)
+	}
+
+	for name, stream := range map[string]string{
+		"bail out":   "1..3\nok 1\nBail out! database down\nok 2\n",
+		"short plan": "1..3\nok 1\n",
+	} {
+		r := &recorder{}
+		if err := testresults.ReadTAP(strings.NewReader(stream), "s", r); err != nil {
+			t.Fatalf("%s: failed to read stream: %v", name, err)
+		}
+		if suite := r.suites[0]; suite.Outcome() != testresults.OutcomeError || len(suite.Cases) != 1 {
+			t.Errorf("%s: expected the suite to error after one case, got %+v", name, suite)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package testresults
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Outcomes of test cases, the outcomes of failed and errored cases are those of the CDEvents spec
+const (
+	OutcomePass  = "pass"
+	OutcomeFail  = "fail"
+	OutcomeError = "error"
+	OutcomeSkip  = "skip"
+)
+
+// Suite is a test suite run read from a test report
+type Suite struct {
+	ID       string
+	Name     string
+	Started  time.Time
+	Duration time.Duration
+	Cases    []Case
+	// Error is set when the suite failed outside of its cases, e.g. when it failed to build
+	Error string
+}
+
+// Case is a test case run of a suite
+type Case struct {
+	ID       string
+	Name     string
+	Started  time.Time
+	Duration time.Duration
+	Outcome  string
+	// Message is the failure, error or skip message of the case
+	Message string
+}
+
+// Finished returns the time the suite finished
+func (s *Suite) Finished() time.Time {
+	return s.Started.Add(s.Duration)
+}
+
+// Outcome returns error when the suite or a case errored, fail when a case failed and pass otherwise
+func (s *Suite) Outcome() string {
+	if s.Error != "" {
+		return OutcomeError
+	}
+	outcome := OutcomePass
+	for _, c := range s.Cases {
+		switch c.Outcome {
+		case OutcomeError:
+			return OutcomeError
+		case OutcomeFail:
+			outcome = OutcomeFail
+		}
+	}
+	return outcome
+}
+
+// Summary describes the failed, errored and skipped cases and the error of the suite, e.g. "10 tests, 2 failed, 1 skipped"
+func (s *Suite) Summary() string {
+	counts := map[string]int{}
+	for _, c := range s.Cases {
+		counts[c.Outcome]++
+	}
+	var parts []string
+	for _, part := range []struct {
+		outcome, verb string
+	}{{OutcomeFail, "failed"}, {OutcomeError, "errored"}, {OutcomeSkip, "skipped"}} {
+		if counts[part.outcome] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[part.outcome], part.verb))
+		}
+	}
+	summary := ""
+	if len(parts) > 0 {
+		summary = fmt.Sprintf("%d tests, %s", len(s.Cases), strings.Join(parts, ", "))
+	}
+	switch {
+	case s.Error == "":
+		return summary
+	case summary == "":
+		return s.Error
+	default:
+		return summary + ": " + s.Error
+	}
+}
+
+// EndAt sets the start of a suite whose report has no timestamp so that it ends at end
+func (s *Suite) EndAt(end time.Time) {
+	s.Started = end.Add(-s.Duration)
+	s.layoutCases()
+}
+
+// layoutCases sets the start of cases without one, assuming the cases ran one after another
+func (s *Suite) layoutCases() {
+	next := s.Started
+	for i := range s.Cases {
+		if s.Cases[i].Started.IsZero() {
+			s.Cases[i].Started = next
+		}
+		next = s.Cases[i].Started.Add(s.Cases[i].Duration)
+	}
+}
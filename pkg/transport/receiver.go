@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	"github.com/cdevents/sdk-go/pkg/api"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// EventHandler processes a received event, an error rejects the event
+type EventHandler func(ctx context.Context, event api.CDEvent) error
+
+// ErrNotDelivered marks handler errors of events that could not be forwarded.
+// They are answered with 502 Bad Gateway, so producers know to retry.
+var ErrNotDelivered = errors.New("event not delivered")
+
+// Receiver is an HTTP handler accepting binary, structured and batch CloudEvents carrying CDEvents,
+// including structured CloudEvents in the protobuf format and Avro events as sent with EncodingAvro.
+// The handler is called for one event at a time, in the order events are received, unless the receiver
+// was created by NewConcurrentReceiver.
+type Receiver struct {
+	mu         sync.Mutex
+	handler    EventHandler
+	concurrent bool
+}
+
+// NewReceiver creates a receiver passing received events to the handler
+func NewReceiver(handler EventHandler) *Receiver {
+	return &Receiver{handler: handler}
+}
+
+// NewConcurrentReceiver creates a receiver calling the handler for the events of concurrent requests at the
+// same time, so that slow handlers don't hold up other producers. The handler synchronizes itself; the events
+// of a request are still passed in order.
+func NewConcurrentReceiver(handler EventHandler) *Receiver {
+	return &Receiver{handler: handler, concurrent: true}
+}
+
+// ServeHTTP accepts the CloudEvents of a request. It responds 202 Accepted when all events are accepted,
+// and 400 Bad Request listing the rejected events otherwise, or 502 Bad Gateway when events were not delivered.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are accepted", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var ces []cloudevents.Event
+	if cehttp.IsHTTPBatch(req.Header) {
+		batch, err := cehttp.NewEventsFromHTTPRequest(req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid CloudEvents batch: %v", err), http.StatusBadRequest)
+			return
+		}
+		ces = batch
+	} else if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == output.AvroContentType {
+		ce, err := newEventFromAvroRequest(req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid Avro event: %v", err), http.StatusBadRequest)
+			return
+		}
+		ces = []cloudevents.Event{*ce}
+	} else {
+		ce, err := cehttp.NewEventFromHTTPRequest(req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid CloudEvent: %v", err), http.StatusBadRequest)
+			return
+		}
+		ces = []cloudevents.Event{*ce}
+	}
+
+	var rejected []string
+	status := http.StatusBadRequest
+	for _, ce := range ces {
+		if err := r.handle(req.Context(), ce); err != nil {
+			rejected = append(rejected, fmt.Sprintf("%s: %v", ce.ID(), err))
+			if errors.Is(err, ErrNotDelivered) {
+				status = http.StatusBadGateway
+			}
+		}
+	}
+	if len(rejected) > 0 {
+		http.Error(w, fmt.Sprintf("rejected %d of %d events:\n%s", len(rejected), len(ces), strings.Join(rejected, "\n")), status)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// newEventFromAvroRequest wraps the Avro event of a request in a CloudEvent with the Avro data
+func newEventFromAvroRequest(req *http.Request) (*cloudevents.Event, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	eventMap, err := output.UnmarshalAvro(body)
+	if err != nil {
+		return nil, err
+	}
+	context, _ := eventMap["context"].(map[string]interface{})
+	id, _ := context["id"].(string)
+	source, _ := context["source"].(string)
+	eventType, _ := context["type"].(string)
+
+	ce := cloudevents.NewEvent()
+	ce.SetID(id)
+	ce.SetSource(source)
+	ce.SetType(eventType)
+	if err := ce.SetData(output.AvroContentType, body); err != nil {
+		return nil, fmt.Errorf("failed to set CloudEvent data: %w", err)
+	}
+	return &ce, nil
+}
+
+// handle decodes the CDEvent of a CloudEvent and passes it to the handler
+func (r *Receiver) handle(ctx context.Context, ce cloudevents.Event) error {
+	event, err := CDEventFromCloudEvent(ce)
+	if err != nil {
+		return err
+	}
+	return r.Handle(ctx, event)
+}
+
+// Handle passes an event received by other means, e.g. translated from a webhook, to the handler
+func (r *Receiver) Handle(ctx context.Context, event api.CDEvent) error {
+	if !r.concurrent {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+	}
+	return r.handler(ctx, event)
+}
+
+// CDEventFromCloudEvent decodes the CDEvent carried by a CloudEvent as JSON or Avro data.
+// CloudEvents in the protobuf format carry the CDEvent as JSON data.
+func CDEventFromCloudEvent(ce cloudevents.Event) (api.CDEvent, error) {
+	var eventMap map[string]interface{}
+	switch contentType := ce.DataContentType(); {
+	case contentType == "" || strings.HasPrefix(contentType, "application/json"):
+		if err := json.Unmarshal(ce.Data(), &eventMap); err != nil {
+			return nil, fmt.Errorf("failed to decode CDEvent data: %w", err)
+		}
+	case contentType == output.AvroContentType:
+		decoded, err := output.UnmarshalAvro(ce.Data())
+		if err != nil {
+			return nil, err
+		}
+		eventMap = decoded
+	default:
+		return nil, fmt.Errorf("unsupported data content type %s: CDEvents are carried as application/json or %s", contentType, output.AvroContentType)
+	}
+	event, err := events.DecodeEvent(eventMap)
+	if err != nil {
+		return nil, err
+	}
+	if eventType := event.GetType().String(); ce.Type() != eventType {
+		return nil, fmt.Errorf("CloudEvent type %s does not match CDEvent type %s", ce.Type(), eventType)
+	}
+	return event, nil
+}
@@ -0,0 +1,185 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/output"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+func TestReceiver(t *testing.T) {
+	var received []api.CDEvent
+	receiver := transport.NewReceiver(func(ctx context.Context, event api.CDEvent) error {
+		if event.GetSubjectId() == "rejected" {
+			return fmt.Errorf("subject rejected")
+		}
+		received = append(received, event)
+		return nil
+	})
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	factory := events.NewEventFactory("test-source")
+	newEvent := func(subjectID string) api.CDEvent {
+		event, err := factory.CreatePipelineRunEvent("started", subjectID, "p", "", "", "https://ci.example.com", nil)
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		return event
+	}
+	cloudEventJSON := func(event api.CDEvent) string {
+		ce, err := api.AsCloudEvent(event)
+		if err != nil {
+			t.Fatalf("failed to create CloudEvent: %v", err)
+		}
+		data, err := json.Marshal(ce)
+		if err != nil {
+			t.Fatalf("failed to marshal CloudEvent: %v", err)
+		}
+		return string(data)
+	}
+	post := func(contentType, body string) (int, string) {
+		resp, err := http.Post(server.URL, contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to post: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	// Binary mode, as sent by the HTTP transport
+	httpTransport, err := transport.NewHTTPTransport(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create HTTP transport: %v", err)
+	}
+	if err := httpTransport.Send(context.Background(), newEvent("binary")); err != nil {
+		t.Fatalf("failed to send binary event: %v", err)
+	}
+
+	// Protobuf and Avro, as sent with the protobuf and avro encodings
+	for _, encoding := range []string{transport.EncodingProtobuf, transport.EncodingAvro} {
+		encodedTransport, err := transport.NewHTTPTransport(server.URL, transport.WithEncoding(encoding))
+		if err != nil {
+			t.Fatalf("failed to create HTTP transport: %v", err)
+		}
+		if err := encodedTransport.Send(context.Background(), newEvent(encoding)); err != nil {
+			t.Fatalf("failed to send %s event: %v", encoding, err)
+		}
+	}
+	if status, body := post(output.AvroContentType, "not avro"); status != http.StatusBadRequest || !strings.Contains(body, "invalid Avro event") {
+		t.Errorf("expected invalid Avro to be rejected, got %d: %s", status, body)
+	}
+
+	if status, body := post("application/cloudevents+json", cloudEventJSON(newEvent("structured"))); status != http.StatusAccepted {
+		t.Errorf("expected the structured event to be accepted, got %d: %s", status, body)
+	}
+	batch := "[" + cloudEventJSON(newEvent("batch-1")) + "," + cloudEventJSON(newEvent("rejected")) + "," + cloudEventJSON(newEvent("batch-2")) + "]"
+	status, body := post("application/cloudevents-batch+json", batch)
+	if status != http.StatusBadRequest || !strings.Contains(body, "rejected 1 of 3 events") || !strings.Contains(body, "subject rejected") {
+		t.Errorf("expected the rejected batch event to be reported, got %d: %s", status, body)
+	}
+
+	var subjects []string
+	for _, event := range received {
+		subjects = append(subjects, event.GetSubjectId())
+	}
+	if got := strings.Join(subjects, ","); got != "binary,protobuf,avro,structured,batch-1,batch-2" {
+		t.Errorf("unexpected received events: %s", got)
+	}
+
+	if status, _ := post("application/cloudevents+json", `{"specversion": "1.0", "id": "1", "source": "s", "type": "t", "data": {"context": {}}}`); status != http.StatusBadRequest {
+		t.Errorf("expected a CloudEvent without CDEvent to be rejected, got %d", status)
+	}
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected GET to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestReceiverNotDelivered(t *testing.T) {
+	receiver := transport.NewReceiver(func(ctx context.Context, event api.CDEvent) error {
+		return fmt.Errorf("%w to https://events.example.com: connection refused", transport.ErrNotDelivered)
+	})
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	event, err := events.NewEventFactory("test-source").CreatePipelineRunEvent("started", "pipeline-1", "p", "", "", "https://ci.example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	ce, err := api.AsCloudEvent(event)
+	if err != nil {
+		t.Fatalf("failed to create CloudEvent: %v", err)
+	}
+	body, _ := json.Marshal(ce)
+	resp, err := http.Post(server.URL, "application/cloudevents+json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("failed to post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected undelivered events to be answered with 502, got %d", resp.StatusCode)
+	}
+}
+
+func TestConcurrentReceiver(t *testing.T) {
+	// Each handler waits for the other one, which only returns when both run at the same time
+	arrived := make(chan struct{}, 2)
+	receiver := transport.NewConcurrentReceiver(func(ctx context.Context, event api.CDEvent) error {
+		arrived <- struct{}{}
+		deadline := time.After(5 * time.Second)
+		for len(arrived) < 2 {
+			select {
+			case <-deadline:
+				return fmt.Errorf("event %s was handled alone", event.GetSubjectId())
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		return nil
+	})
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	factory := events.NewEventFactory("test-source")
+	statuses := make(chan int, 2)
+	for _, subjectID := range []string{"pipeline-1", "pipeline-2"} {
+		event, err := factory.CreatePipelineRunEvent("started", subjectID, "p", "", "", "https://ci.example.com", nil)
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		ce, err := api.AsCloudEvent(event)
+		if err != nil {
+			t.Fatalf("failed to create CloudEvent: %v", err)
+		}
+		body, _ := json.Marshal(ce)
+		go func() {
+			resp, err := http.Post(server.URL, "application/cloudevents+json", strings.NewReader(string(body)))
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if status := <-statuses; status != http.StatusAccepted {
+			t.Errorf("expected events of concurrent requests to be handled together, got %d", status)
+		}
+	}
+}
@@ -2,9 +2,15 @@ package transport
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
 	"strings"
+	"sync"
 
+	"github.com/brunseba/cdevents-tools/pkg/output"
 	"github.com/cdevents/sdk-go/pkg/api"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 )
@@ -14,27 +20,40 @@ type Transport interface {
 	Send(ctx context.Context, event api.CDEvent) error
 }
 
+// Encodings of events sent by HTTPTransport, see WithEncoding
+const (
+	// EncodingBinary sends events as binary CloudEvents, with the CDEvent as JSON body
+	EncodingBinary = output.EncodingBinary
+	// EncodingProtobuf sends events as CloudEvents in the CloudEvents protobuf format
+	EncodingProtobuf = output.EncodingProtobuf
+	// EncodingAvro sends events as Avro binary data following output.AvroSchema
+	EncodingAvro = output.EncodingAvro
+)
+
 // HTTPTransport sends events via HTTP
 type HTTPTransport struct {
-	client cloudevents.Client
-	target string
+	client   *http.Client
+	target   string
+	encoding string
+	headers  map[string]string
 }
 
 // NewHTTPTransport creates a new HTTP transport
 func NewHTTPTransport(target string, options ...HTTPOption) (*HTTPTransport, error) {
-	client, err := cloudevents.NewClientHTTP()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
-	}
-
 	transport := &HTTPTransport{
-		client: client,
-		target: target,
+		client:   http.DefaultClient,
+		target:   target,
+		encoding: EncodingBinary,
 	}
 
 	for _, option := range options {
 		option(transport)
 	}
+	switch transport.encoding {
+	case EncodingBinary, EncodingProtobuf, EncodingAvro:
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q (supported: %s, %s, %s)", transport.encoding, EncodingBinary, EncodingProtobuf, EncodingAvro)
+	}
 
 	return transport, nil
 }
@@ -45,31 +64,52 @@ type HTTPOption func(*HTTPTransport)
 // WithHTTPHeaders adds custom headers to HTTP requests
 func WithHTTPHeaders(headers map[string]string) HTTPOption {
 	return func(t *HTTPTransport) {
-		// Logic to configure headers goes here if needed
+		if t.headers == nil {
+			t.headers = map[string]string{}
+		}
+		for name, value := range headers {
+			t.headers[name] = value
+		}
+	}
+}
+
+// WithEncoding sets the encoding of sent events, EncodingBinary when empty
+func WithEncoding(encoding string) HTTPOption {
+	return func(t *HTTPTransport) {
+		if encoding != "" {
+			t.encoding = encoding
+		}
 	}
 }
 
-// Send sends an event via HTTP
+// Send sends an event via HTTP, with the request rendered by the http and curl formats
 func (t *HTTPTransport) Send(ctx context.Context, event api.CDEvent) error {
-	ce, err := api.AsCloudEvent(event)
+	req, err := output.NewHTTPRequest(ctx, event, output.HTTPRenderOptions{
+		Target:   t.target,
+		Headers:  t.headers,
+		Encoding: t.encoding,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to convert to CloudEvent: %w", err)
+		return err
 	}
 
-	ctx = cloudevents.ContextWithTarget(ctx, t.target)
-	ctx = cloudevents.WithEncodingBinary(ctx)
-
-	result := t.client.Send(ctx, *ce)
-	if cloudevents.IsUndelivered(result) {
-		return fmt.Errorf("failed to send event: %w", result)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	// Targets answering with an error status received the event, but did not accept it
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event rejected by %s: %s", t.target, resp.Status)
 	}
-
 	return nil
 }
 
 // ConsoleTransport outputs events to console
 type ConsoleTransport struct {
 	format string
+	mu     sync.Mutex
 }
 
 // NewConsoleTransport creates a new console transport
@@ -79,15 +119,22 @@ func NewConsoleTransport(format string) *ConsoleTransport {
 	}
 }
 
-// Send outputs an event to console
+// Send prints an event to stdout in the format of the transport, followed by a blank line
 func (t *ConsoleTransport) Send(ctx context.Context, event api.CDEvent) error {
-	// This would use the output package to format the event
-	// For now, just print a simple message
-	fmt.Printf("Event sent to console: %s\n", event.GetId())
+	formatted, err := output.FormatOutput(event, t.format)
+	if err != nil {
+		return err
+	}
+	// Events sent concurrently, e.g. by relay, are printed one at a time
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := fmt.Fprintf(os.Stdout, "%s\n\n", formatted); err != nil {
+		return fmt.Errorf("failed to write event to console: %w", err)
+	}
 	return nil
 }
 
-// FileTransport writes events to a file
+// FileTransport appends events to a file
 type FileTransport struct {
 	filename string
 	format   string
@@ -101,11 +148,30 @@ func NewFileTransport(filename, format string) *FileTransport {
 	}
 }
 
-// Send writes an event to a file
+// Send appends an event to the file. JSON events are written on a single line, so the file is NDJSON;
+// other text formats are written as formatted, followed by a newline.
 func (t *FileTransport) Send(ctx context.Context, event api.CDEvent) error {
-	// Implementation would write to file
-	fmt.Printf("Event sent to file %s: %s\n", t.filename, event.GetId())
-	return nil
+	var data []byte
+	switch {
+	case t.format == "json":
+		eventMap, err := output.EventToMap(event)
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(eventMap); err != nil {
+			return fmt.Errorf("failed to marshal event to JSON: %w", err)
+		}
+	case output.IsBinaryFormat(t.format):
+		return fmt.Errorf("%s events can't be appended to a file, their records are not delimited", t.format)
+	default:
+		formatted, err := output.FormatOutput(event, t.format)
+		if err != nil {
+			return err
+		}
+		data = []byte(formatted)
+	}
+	// Lines are appended with a single write, so events sent concurrently, e.g. by relay, don't interleave
+	return output.WriteFile(t.filename, append(data, '\n'), true)
 }
 
 // KafkaTransport sends events to Kafka
@@ -134,14 +200,14 @@ func NewTransportFactory() *TransportFactory {
 	return &TransportFactory{}
 }
 
-// CreateTransport creates a transport based on the target URL
-func (f *TransportFactory) CreateTransport(target string) (Transport, error) {
+// CreateTransport creates a transport based on the target URL, options apply to HTTP targets
+func (f *TransportFactory) CreateTransport(target string, options ...HTTPOption) (Transport, error) {
 	if target == "" || target == "console" {
 		return NewConsoleTransport("json"), nil
 	}
 
 	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
-		return NewHTTPTransport(target)
+		return NewHTTPTransport(target, options...)
 	}
 
 	if strings.HasPrefix(target, "file://") {
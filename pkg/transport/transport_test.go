@@ -2,10 +2,22 @@ package transport_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 	"github.com/cdevents/sdk-go/pkg/api"
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/output"
 	"github.com/brunseba/cdevents-tools/pkg/transport"
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
 	cdeventsv04 "github.com/cdevents/sdk-go/pkg/api/v04"
 )
 
@@ -98,7 +110,7 @@ func TestNewMultiTransport(t *testing.T) {
 
 func TestConsoleTransport_Send(t *testing.T) {
 	consoleTransport := transport.NewConsoleTransport("json")
-	
+
 	// Create a test event
 	event, err := cdeventsv04.NewPipelineRunQueuedEvent()
 	if err != nil {
@@ -107,34 +119,79 @@ func TestConsoleTransport_Send(t *testing.T) {
 	event.SetId("test-id")
 	event.SetSource("test-source")
 
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	os.Stdout = w
 	err = consoleTransport.Send(context.Background(), event)
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
 	if err != nil {
 		t.Fatalf("unexpected error sending to console: %v", err)
 	}
+
+	var printedEvent map[string]interface{}
+	if err := json.Unmarshal(printed, &printedEvent); err != nil {
+		t.Fatalf("expected the event printed as JSON, got %q: %v", printed, err)
+	}
+	if eventContext, _ := printedEvent["context"].(map[string]interface{}); eventContext["id"] != "test-id" {
+		t.Errorf("expected the test-id event to be printed, got %s", printed)
+	}
 }
 
 func TestFileTransport_Send(t *testing.T) {
-	fileTransport := transport.NewFileTransport("/tmp/test.json", "json")
-	
-	// Create a test event
-	event, err := cdeventsv04.NewPipelineRunQueuedEvent()
-	if err != nil {
-		t.Fatalf("failed to create test event: %v", err)
+	filename := filepath.Join(t.TempDir(), "events.ndjson")
+	fileTransport := transport.NewFileTransport(filename, "json")
+
+	for _, id := range []string{"event-1", "event-2"} {
+		event, err := cdeventsv04.NewPipelineRunQueuedEvent()
+		if err != nil {
+			t.Fatalf("failed to create test event: %v", err)
+		}
+		event.SetId(id)
+		event.SetSource("test-source")
+		if err := fileTransport.Send(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error sending to file: %v", err)
+		}
 	}
-	event.SetId("test-id")
-	event.SetSource("test-source")
 
-	err = fileTransport.Send(context.Background(), event)
+	data, err := os.ReadFile(filename)
 	if err != nil {
-		t.Fatalf("unexpected error sending to file: %v", err)
+		t.Fatalf("failed to read file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per event, got %q", data)
+	}
+	for i, line := range lines {
+		var eventMap map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &eventMap); err != nil {
+			t.Fatalf("failed to unmarshal line %d: %v", i+1, err)
+		}
+		event, err := events.DecodeEvent(eventMap)
+		if err != nil {
+			t.Fatalf("failed to decode line %d: %v", i+1, err)
+		}
+		if expected := fmt.Sprintf("event-%d", i+1); event.GetId() != expected {
+			t.Errorf("expected %s on line %d, got %s", expected, i+1, event.GetId())
+		}
+	}
+
+	avroFile := transport.NewFileTransport(filepath.Join(t.TempDir(), "events.avro"), "avro")
+	event, _ := cdeventsv04.NewPipelineRunQueuedEvent()
+	if err := avroFile.Send(context.Background(), event); err == nil {
+		t.Error("expected an error appending binary events to a file")
 	}
 }
 
 func TestMultiTransport_Send(t *testing.T) {
 	console := transport.NewConsoleTransport("json")
-	file := transport.NewFileTransport("/tmp/test.json", "json")
+	file := transport.NewFileTransport(filepath.Join(t.TempDir(), "test.ndjson"), "json")
 	multiTransport := transport.NewMultiTransport(console, file)
-	
+
 	// Create a test event
 	event, err := cdeventsv04.NewPipelineRunQueuedEvent()
 	if err != nil {
@@ -175,6 +232,131 @@ func TestHTTPTransport_Send(t *testing.T) {
 	_ = err // Suppress unused variable warning
 }
 
+func TestHTTPTransport_SendRejected(t *testing.T) {
+	// Events answered with an error status were not accepted and must be retried or reported
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	httpTransport, err := transport.NewHTTPTransport(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create HTTP transport: %v", err)
+	}
+	event, err := events.NewEventFactory("test-source").CreatePipelineRunEvent("started", "pipeline-123", "test-pipeline", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	if err := httpTransport.Send(context.Background(), event); err == nil {
+		t.Error("expected an error for an event the target answered with 500")
+	}
+}
+
+func TestHTTPTransport_SendEncoded(t *testing.T) {
+	type request struct {
+		contentType string
+		body        []byte
+	}
+	received := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{r.Header.Get("Content-Type"), body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	event, err := events.NewEventFactory("test-source").CreatePipelineRunEvent("started", "pipeline-123", "test-pipeline", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+
+	protobufTransport, err := transport.NewHTTPTransport(server.URL, transport.WithEncoding(transport.EncodingProtobuf))
+	if err != nil {
+		t.Fatalf("failed to create HTTP transport: %v", err)
+	}
+	if err := protobufTransport.Send(context.Background(), event); err != nil {
+		t.Fatalf("failed to send event: %v", err)
+	}
+	req := <-received
+	if req.contentType != output.ProtobufContentType {
+		t.Errorf("expected content type %s, got %s", output.ProtobufContentType, req.contentType)
+	}
+	if ce := cloudevents.NewEvent(); protobuf.Protobuf.Unmarshal(req.body, &ce) != nil || ce.ID() != event.GetId() {
+		t.Errorf("expected the event as a protobuf CloudEvent, got %v", ce)
+	}
+
+	avroTransport, err := transport.NewHTTPTransport(server.URL, transport.WithEncoding(transport.EncodingAvro))
+	if err != nil {
+		t.Fatalf("failed to create HTTP transport: %v", err)
+	}
+	if err := avroTransport.Send(context.Background(), event); err != nil {
+		t.Fatalf("failed to send event: %v", err)
+	}
+	req = <-received
+	if req.contentType != output.AvroContentType {
+		t.Errorf("expected content type %s, got %s", output.AvroContentType, req.contentType)
+	}
+	eventMap, err := output.UnmarshalAvro(req.body)
+	if err != nil {
+		t.Fatalf("failed to decode Avro body: %v", err)
+	}
+	if eventContext, _ := eventMap["context"].(map[string]interface{}); eventContext["id"] != event.GetId() {
+		t.Errorf("expected the event as Avro, got %v", eventMap)
+	}
+
+	if _, err := transport.NewHTTPTransport(server.URL, transport.WithEncoding("xml")); err == nil {
+		t.Error("expected an error for an unsupported encoding")
+	}
+}
+
+func TestHTTPTransport_SendCustomData(t *testing.T) {
+	// Custom data set by the event factory must arrive at the receiver
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	factory := events.NewEventFactory("test-source")
+	event, err := factory.CreatePipelineRunEvent("started", "pipeline-123", "test-pipeline", "", "", "", &events.CustomData{
+		Data:        map[string]interface{}{"team": "platform", "replicas": 3},
+		ContentType: "application/json",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test event: %v", err)
+	}
+
+	httpTransport, err := transport.NewHTTPTransport(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create HTTP transport: %v", err)
+	}
+	if err := httpTransport.Send(context.Background(), event); err != nil {
+		t.Fatalf("failed to send event: %v", err)
+	}
+
+	var body struct {
+		CustomData            map[string]interface{} `json:"customData"`
+		CustomDataContentType string                 `json:"customDataContentType"`
+	}
+	var data []byte
+	select {
+	case data = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event received")
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("failed to decode received event: %v", err)
+	}
+	if body.CustomData["team"] != "platform" || body.CustomData["replicas"] != float64(3) {
+		t.Errorf("unexpected custom data received: %v", body.CustomData)
+	}
+	if body.CustomDataContentType != "application/json" {
+		t.Errorf("unexpected custom data content type received: %q", body.CustomDataContentType)
+	}
+}
+
 func TestMultiTransport_SendWithErrors(t *testing.T) {
 	// Create a mock failing transport
 	failingTransport := &failingTransport{}
@@ -206,17 +388,39 @@ func (t *failingTransport) Send(ctx context.Context, event api.CDEvent) error {
 }
 
 func TestWithHTTPHeaders(t *testing.T) {
-	// Test the HTTP headers option
-	headers := map[string]string{"Authorization": "Bearer token"}
-	option := transport.WithHTTPHeaders(headers)
+	received := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
 
-	// This should not panic
-	httpTransport, err := transport.NewHTTPTransport("http://example.com", option)
+	headers := map[string]string{"Authorization": "Bearer token"}
+	httpTransport, err := transport.NewHTTPTransport(server.URL, transport.WithHTTPHeaders(headers))
 	if err != nil {
 		t.Fatalf("failed to create HTTP transport with headers: %v", err)
 	}
-	if httpTransport == nil {
-		t.Fatalf("HTTP transport should not be nil")
+	event, err := events.NewEventFactory("test-source").CreatePipelineRunEvent("started", "pipeline-123", "test-pipeline", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	if err := httpTransport.Send(context.Background(), event); err != nil {
+		t.Fatalf("failed to send event: %v", err)
+	}
+
+	header := <-received
+	if got := header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("expected the Authorization header to be sent, got %q", got)
+	}
+	// The http format renders the request the transport sends
+	rendered, err := output.FormatHTTPRequest(event, output.HTTPRenderOptions{Target: server.URL, Headers: headers})
+	if err != nil {
+		t.Fatalf("failed to render HTTP request: %v", err)
+	}
+	for _, name := range []string{"Authorization", "Ce-Id", "Ce-Type", "Content-Type"} {
+		if line := name + ": " + header.Get(name); !strings.Contains(rendered, line) {
+			t.Errorf("expected the rendered request to contain %q, got:\n%s", line, rendered)
+		}
 	}
 }
 
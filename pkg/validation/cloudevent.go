@@ -0,0 +1,135 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	jsonschema "github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// cloudEventSpecVersion is the CloudEvents spec version CDEvents are bound to
+const cloudEventSpecVersion = "1.0"
+
+// isCloudEvent reports whether a decoded document is a structured CloudEvent
+func isCloudEvent(doc map[string]interface{}) bool {
+	_, ok := doc["specversion"]
+	return ok
+}
+
+// checkCloudEvent returns the event type and the violations of a structured CloudEvent carrying a CDEvent.
+// Violations of the CDEvent itself are reported under /data.
+func (v *Validator) checkCloudEvent(doc map[string]interface{}) (string, []FieldError) {
+	var errs []FieldError
+	attribute := func(name string) string {
+		value, ok := doc[name]
+		if !ok {
+			return ""
+		}
+		s, ok := value.(string)
+		if !ok {
+			errs = append(errs, FieldError{Path: "/" + name, Message: fmt.Sprintf("attribute must be a string, got %T", value)})
+		}
+		return s
+	}
+
+	if specVersion := attribute("specversion"); specVersion != cloudEventSpecVersion {
+		errs = append(errs, FieldError{Path: "/specversion", Message: fmt.Sprintf("unsupported CloudEvents spec version %q, want %q", specVersion, cloudEventSpecVersion)})
+	}
+	for _, name := range []string{"id", "source", "type"} {
+		if attribute(name) == "" {
+			errs = append(errs, FieldError{Path: "/" + name, Message: "required attribute is missing or empty"})
+		}
+	}
+	ceTime := attribute("time")
+	if ceTime != "" {
+		if _, err := time.Parse(time.RFC3339Nano, ceTime); err != nil {
+			errs = append(errs, FieldError{Path: "/time", Message: "attribute must be an RFC 3339 timestamp"})
+		}
+	}
+	if contentType := attribute("datacontenttype"); contentType != "" && !isJSONContentType(contentType) {
+		errs = append(errs, FieldError{Path: "/datacontenttype", Message: fmt.Sprintf("CDEvents must be carried as JSON, got %q", contentType)})
+	}
+
+	eventMap, dataErr := cloudEventData(doc)
+	if dataErr != nil {
+		errs = append(errs, *dataErr)
+		sortFieldErrors(errs)
+		return attribute("type"), errs
+	}
+
+	eventType, eventErrs := v.checkEvent(eventMap)
+	for _, eventErr := range eventErrs {
+		errs = append(errs, FieldError{Path: "/data" + eventErr.Path, Message: eventErr.Message})
+	}
+
+	// The CloudEvents binding copies these CDEvent fields into the context attributes
+	context, _ := eventMap["context"].(map[string]interface{})
+	subject, _ := eventMap["subject"].(map[string]interface{})
+	bindings := []struct {
+		attribute string
+		value     interface{}
+		field     string
+	}{
+		{"id", context["id"], "/data/context/id"},
+		{"source", context["source"], "/data/context/source"},
+		{"type", context["type"], "/data/context/type"},
+		{"subject", subject["id"], "/data/subject/id"},
+	}
+	for _, binding := range bindings {
+		value, _ := binding.value.(string)
+		if ceValue, ok := doc[binding.attribute].(string); ok && ceValue != "" && value != "" && ceValue != value {
+			errs = append(errs, FieldError{Path: "/" + binding.attribute, Message: fmt.Sprintf("attribute %q does not match %s %q", ceValue, binding.field, value)})
+		}
+	}
+	if timestamp, _ := context["timestamp"].(string); ceTime != "" && timestamp != "" {
+		ceParsed, ceErr := time.Parse(time.RFC3339Nano, ceTime)
+		parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+		if ceErr == nil && err == nil && !ceParsed.Equal(parsed) {
+			errs = append(errs, FieldError{Path: "/time", Message: fmt.Sprintf("attribute %q does not match /data/context/timestamp %q", ceTime, timestamp)})
+		}
+	}
+
+	sortFieldErrors(errs)
+	if eventType == "" {
+		eventType = attribute("type")
+	}
+	return eventType, errs
+}
+
+// cloudEventData extracts the CDEvent carried by a structured CloudEvent
+func cloudEventData(doc map[string]interface{}) (map[string]interface{}, *FieldError) {
+	if encoded, ok := doc["data_base64"]; ok {
+		s, _ := encoded.(string)
+		raw, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, &FieldError{Path: "/data_base64", Message: "data is not valid base64"}
+		}
+		return decodeEventData(raw, "/data_base64")
+	}
+
+	switch data := doc["data"].(type) {
+	case map[string]interface{}:
+		return data, nil
+	case string:
+		return decodeEventData([]byte(data), "/data")
+	case nil:
+		return nil, &FieldError{Path: "", Message: "CloudEvent carries no CDEvent data"}
+	default:
+		return nil, &FieldError{Path: "/data", Message: fmt.Sprintf("data must be a CDEvent object, got %T", data)}
+	}
+}
+
+// decodeEventData decodes JSON encoded CDEvent data
+func decodeEventData(raw []byte, path string) (map[string]interface{}, *FieldError) {
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, &FieldError{Path: path, Message: fmt.Sprintf("data is not valid JSON: %v", err)}
+	}
+	eventMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &FieldError{Path: path, Message: "data must be a CDEvent object"}
+	}
+	return eventMap, nil
+}
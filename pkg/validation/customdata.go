@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"strings"
+
+	jsonschema "github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// customDataErrors checks that custom data matches its declared content type
+func customDataErrors(eventMap map[string]interface{}) []FieldError {
+	customData, hasData := eventMap["customData"]
+	contentType, _ := eventMap["customDataContentType"].(string)
+	if contentType != "" {
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return []FieldError{{Path: "/customDataContentType", Message: fmt.Sprintf("invalid media type %q", contentType)}}
+		}
+	}
+	if !hasData {
+		return nil
+	}
+
+	switch data := customData.(type) {
+	case map[string]interface{}:
+		if contentType != "" && !isJSONContentType(contentType) {
+			return []FieldError{{Path: "/customData", Message: fmt.Sprintf("object custom data requires a JSON content type, got %q", contentType)}}
+		}
+	case string:
+		raw, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return []FieldError{{Path: "/customData", Message: "string custom data must be base64 encoded"}}
+		}
+		if isJSONContentType(contentType) {
+			if _, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw)); err != nil {
+				return []FieldError{{Path: "/customData", Message: fmt.Sprintf("custom data is not valid JSON: %v", err)}}
+			}
+		}
+	}
+	return nil
+}
+
+// isJSONContentType reports whether a media type denotes JSON
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	jsonschema "github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a single event read from an input
+type Document struct {
+	// Name identifies the document in reports, e.g. events.ndjson#2
+	Name string
+	// Event is the decoded CDEvent or structured CloudEvent
+	Event map[string]interface{}
+}
+
+// CDEvent returns the CDEvent of the document and the JSON pointer prefix of its location,
+// which is /data for structured CloudEvents. It returns nil when a CloudEvent carries no CDEvent.
+func (d Document) CDEvent() (map[string]interface{}, string) {
+	if !isCloudEvent(d.Event) {
+		return d.Event, ""
+	}
+	data, dataErr := cloudEventData(d.Event)
+	if dataErr != nil {
+		return nil, ""
+	}
+	return data, "/data"
+}
+
+// DecodeDocuments splits an input into events. It accepts a JSON object, a JSON array such as
+// a CloudEvents batch, newline delimited or concatenated JSON, and single or multi-document YAML.
+func DecodeDocuments(name string, data []byte) ([]Document, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%s: no events found", name)
+	}
+
+	var values []interface{}
+	var err error
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		values, err = decodeJSONValues(trimmed)
+	} else {
+		values, err = decodeYAMLValues(trimmed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	// A top-level array holds a batch of events
+	if len(values) == 1 {
+		if batch, ok := values[0].([]interface{}); ok {
+			values = batch
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s: no events found", name)
+	}
+
+	documents := make([]Document, 0, len(values))
+	for i, value := range values {
+		docName := name
+		if len(values) > 1 {
+			docName = fmt.Sprintf("%s#%d", name, i+1)
+		}
+		event, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: event must be an object, got %T", docName, value)
+		}
+		documents = append(documents, Document{Name: docName, Event: event})
+	}
+	return documents, nil
+}
+
+// StreamDocuments calls fn with the events of an input as they are decoded, so that newline delimited or
+// concatenated JSON is not read at once. JSON arrays and YAML are decoded whole, as by DecodeDocuments.
+// Documents are named as by DecodeDocuments, which is why fn runs one event behind the decoder.
+// Streaming stops at the first error of fn or of the input.
+func StreamDocuments(name string, r io.Reader, fn func(Document) error) error {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: no events found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if first != '{' {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		documents, err := DecodeDocuments(name, data)
+		if err != nil {
+			return err
+		}
+		for _, document := range documents {
+			if err := fn(document); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	var pending *Document
+	for i := 1; ; i++ {
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// Events read before the invalid one are still handled
+			if pending != nil {
+				if fnErr := fn(*pending); fnErr != nil {
+					return fnErr
+				}
+			}
+			return fmt.Errorf("%s: failed to parse JSON event %d: %w", name, i, err)
+		}
+		docName := fmt.Sprintf("%s#%d", name, i)
+		event, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: event must be an object, got %T", docName, value)
+		}
+		if pending != nil {
+			if err := fn(*pending); err != nil {
+				return err
+			}
+		}
+		pending = &Document{Name: docName, Event: event}
+	}
+	// A single event is named after the input
+	if pending.Name == name+"#1" {
+		pending.Name = name
+	}
+	return fn(*pending)
+}
+
+// peekNonSpace skips leading white space and returns the next byte without consuming it
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			return b, reader.UnreadByte()
+		}
+	}
+}
+
+// decodeJSONValues decodes a stream of JSON values
+func decodeJSONValues(data []byte) ([]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values []interface{}
+	for {
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) {
+				return values, nil
+			}
+			return nil, fmt.Errorf("failed to parse JSON event %d: %w", len(values)+1, err)
+		}
+		values = append(values, value)
+	}
+}
+
+// decodeYAMLValues decodes a stream of YAML documents into JSON compatible values
+func decodeYAMLValues(data []byte) ([]interface{}, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var values []interface{}
+	for {
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) {
+				return values, nil
+			}
+			return nil, fmt.Errorf("failed to parse YAML event %d: %w", len(values)+1, err)
+		}
+		if value == nil {
+			continue
+		}
+
+		// Round-trip through JSON so YAML timestamps and integers match decoded JSON
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert YAML event %d: %w", len(values)+1, err)
+		}
+		normalized, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert YAML event %d: %w", len(values)+1, err)
+		}
+		values = append(values, normalized)
+	}
+}
@@ -0,0 +1,123 @@
+package validation_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+)
+
+func TestDecodeDocuments(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		names []string
+	}{
+		{"json object", `{"context": {}}`, []string{"input"}},
+		{"json array", `[{"context": {}}, {"context": {}}]`, []string{"input#1", "input#2"}},
+		{"ndjson", "{\"context\": {}}\n{\"context\": {}}\n", []string{"input#1", "input#2"}},
+		{"yaml", "context:\n  id: a\n", []string{"input"}},
+		{"multi-document yaml", "context:\n  id: a\n---\ncontext:\n  id: b\n", []string{"input#1", "input#2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			documents, err := validation.DecodeDocuments("input", []byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(documents) != len(tc.names) {
+				t.Fatalf("expected %d documents, got %d", len(tc.names), len(documents))
+			}
+			for i, doc := range documents {
+				if doc.Name != tc.names[i] {
+					t.Errorf("expected document name %s, got %s", tc.names[i], doc.Name)
+				}
+				if _, ok := doc.Event["context"]; !ok {
+					t.Errorf("document %s should have a context", doc.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeDocumentsErrors(t *testing.T) {
+	for _, input := range []string{"", "   ", `{"context": `, `[1, 2]`} {
+		if _, err := validation.DecodeDocuments("input", []byte(input)); err == nil {
+			t.Errorf("expected an error for input %q", input)
+		}
+	}
+}
+
+func TestStreamDocuments(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		names []string
+	}{
+		{"json object", `{"context": {}}`, []string{"input"}},
+		{"ndjson", "{\"context\": {}}\n{\"context\": {}}\n", []string{"input#1", "input#2"}},
+		{"json array", `[{"context": {}}, {"context": {}}]`, []string{"input#1", "input#2"}},
+		{"multi-document yaml", "context:\n  id: a\n---\ncontext:\n  id: b\n", []string{"input#1", "input#2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			err := validation.StreamDocuments("input", strings.NewReader(tc.input), func(doc validation.Document) error {
+				names = append(names, doc.Name)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.names, ",") {
+				t.Errorf("expected documents %v, got %v", tc.names, names)
+			}
+		})
+	}
+}
+
+func TestStreamDocumentsBeforeEndOfInput(t *testing.T) {
+	reader, writer := io.Pipe()
+	streamed := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		done <- validation.StreamDocuments("stdin", reader, func(doc validation.Document) error {
+			streamed <- doc.Name
+			return nil
+		})
+	}()
+
+	// The first event is handled once the second one is read, while the input is still open
+	go writer.Write([]byte("{\"context\": {}}\n{\"context\": {}}\n"))
+	if name := <-streamed; name != "stdin#1" {
+		t.Errorf("expected stdin#1, got %s", name)
+	}
+	writer.Close()
+	if name := <-streamed; name != "stdin#2" {
+		t.Errorf("expected stdin#2, got %s", name)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStreamDocumentsErrors(t *testing.T) {
+	var names []string
+	err := validation.StreamDocuments("input", strings.NewReader("{\"context\": {}}\n{\"context\": "), func(doc validation.Document) error {
+		names = append(names, doc.Name)
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "event 2") {
+		t.Errorf("expected a parse error of event 2, got %v", err)
+	}
+	if strings.Join(names, ",") != "input#1" {
+		t.Errorf("expected the events before the invalid one, got %v", names)
+	}
+
+	if err := validation.StreamDocuments("input", strings.NewReader("  "), func(validation.Document) error { return nil }); err == nil {
+		t.Errorf("expected an error for an empty input")
+	}
+}
@@ -0,0 +1,188 @@
+package validation
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Result is the outcome of validating a single document
+type Result struct {
+	// Name identifies the validated document
+	Name string `json:"name"`
+	// Type is the CDEvent type, when known
+	Type string `json:"type,omitempty"`
+	// ID is the CDEvent ID, when known
+	ID string `json:"id,omitempty"`
+	// Valid reports whether the document passed validation
+	Valid bool `json:"valid"`
+	// Errors lists the violations found in the document
+	Errors []FieldError `json:"errors,omitempty"`
+	// Warnings lists the findings that do not fail validation, such as policy warnings
+	Warnings []FieldError `json:"warnings,omitempty"`
+}
+
+// Check validates a document and returns its result
+func (v *Validator) Check(doc Document) Result {
+	result := Result{Name: doc.Name, Valid: true}
+
+	eventMap, _ := doc.CDEvent()
+	if context, ok := eventMap["context"].(map[string]interface{}); ok {
+		result.Type, _ = context["type"].(string)
+		result.ID, _ = context["id"].(string)
+	}
+
+	err := v.ValidateDocument(doc.Event)
+	if err == nil {
+		return result
+	}
+	result.Valid = false
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		result.Errors = validationErr.Errors
+	} else {
+		result.Errors = []FieldError{{Message: err.Error()}}
+	}
+	return result
+}
+
+// ReportFormats lists the formats supported by WriteReport
+var ReportFormats = []string{"text", "json", "junit"}
+
+// WriteReport writes validation results in the text, json or junit format
+func WriteReport(w io.Writer, format string, results []Result) error {
+	switch format {
+	case "text", "":
+		return writeTextReport(w, results)
+	case "json":
+		return writeJSONReport(w, results)
+	case "junit":
+		return writeJUnitReport(w, results)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// Failed returns the number of results that did not pass validation
+func Failed(results []Result) int {
+	failed := 0
+	for _, result := range results {
+		if !result.Valid {
+			failed++
+		}
+	}
+	return failed
+}
+
+func writeTextReport(w io.Writer, results []Result) error {
+	for _, result := range results {
+		status := "PASS"
+		if !result.Valid {
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("%s %s", status, result.Name)
+		if result.Type != "" {
+			line += fmt.Sprintf(" (%s)", result.Type)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		for _, fieldErr := range result.Errors {
+			if _, err := fmt.Fprintf(w, "  %s\n", fieldErr.String()); err != nil {
+				return err
+			}
+		}
+		for _, warning := range result.Warnings {
+			if _, err := fmt.Fprintf(w, "  warning: %s\n", warning.String()); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d events, %d passed, %d failed\n", len(results), len(results)-Failed(results), Failed(results))
+	return err
+}
+
+func writeJSONReport(w io.Writer, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+	report := struct {
+		Total   int      `json:"total"`
+		Failed  int      `json:"failed"`
+		Results []Result `json:"results"`
+	}{
+		Total:   len(results),
+		Failed:  Failed(results),
+		Results: results,
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// junitTestSuite is the JUnit XML representation of a validation run
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnitReport(w io.Writer, results []Result) error {
+	suite := junitTestSuite{
+		Name:     "cdevents-validate",
+		Tests:    len(results),
+		Failures: Failed(results),
+	}
+	for _, result := range results {
+		testCase := junitTestCase{Name: result.Name, ClassName: result.Type}
+		if testCase.ClassName == "" {
+			testCase.ClassName = "unknown"
+		}
+		if !result.Valid {
+			lines := make([]string, 0, len(result.Errors))
+			for _, fieldErr := range result.Errors {
+				lines = append(lines, fieldErr.String())
+			}
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d validation error(s)", len(result.Errors)),
+				Text:    strings.Join(lines, "\n"),
+			}
+		}
+		if len(result.Warnings) > 0 {
+			lines := make([]string, 0, len(result.Warnings))
+			for _, warning := range result.Warnings {
+				lines = append(lines, "warning: "+warning.String())
+			}
+			testCase.SystemOut = strings.Join(lines, "\n")
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
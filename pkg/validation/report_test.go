@@ -0,0 +1,151 @@
+package validation_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+)
+
+const validCloudEvent = `{
+	"specversion": "1.0",
+	"id": "event-1",
+	"source": "https://example.com/ci",
+	"type": "dev.cdevents.pipelinerun.started.0.2.0",
+	"subject": "pipeline-1",
+	"time": "2024-01-01T00:00:00Z",
+	"datacontenttype": "application/json",
+	"data": {
+		"context": {
+			"version": "0.4.1",
+			"id": "event-1",
+			"source": "https://example.com/ci",
+			"type": "dev.cdevents.pipelinerun.started.0.2.0",
+			"timestamp": "2024-01-01T00:00:00Z"
+		},
+		"subject": {
+			"id": "pipeline-1",
+			"type": "pipelineRun",
+			"content": {"pipelineName": "test-pipeline", "url": "https://example.com"}
+		},
+		"customData": {"key": "value"},
+		"customDataContentType": "application/json"
+	}
+}`
+
+func checkDocuments(t *testing.T, input string) []validation.Result {
+	t.Helper()
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	documents, err := validation.DecodeDocuments("input", []byte(input))
+	if err != nil {
+		t.Fatalf("failed to decode documents: %v", err)
+	}
+	var results []validation.Result
+	for _, doc := range documents {
+		results = append(results, validator.Check(doc))
+	}
+	return results
+}
+
+func TestCheckCloudEvent(t *testing.T) {
+	results := checkDocuments(t, validCloudEvent)
+	if len(results) != 1 || !results[0].Valid {
+		t.Fatalf("valid CloudEvent should pass validation: %+v", results)
+	}
+	if results[0].Type != "dev.cdevents.pipelinerun.started.0.2.0" || results[0].ID != "event-1" {
+		t.Errorf("result should carry the CDEvent type and id, got %+v", results[0])
+	}
+}
+
+func TestCheckCloudEventAttributes(t *testing.T) {
+	input := strings.NewReplacer(
+		`"id": "event-1",
+	"source"`, `"id": "other-id",
+	"source"`,
+		`"specversion": "1.0"`, `"specversion": "0.3"`,
+		`"customDataContentType": "application/json"`, `"customDataContentType": "text/plain"`,
+	).Replace(validCloudEvent)
+
+	results := checkDocuments(t, input)
+	if len(results) != 1 || results[0].Valid {
+		t.Fatalf("invalid CloudEvent should fail validation: %+v", results)
+	}
+	paths := map[string]bool{}
+	for _, fieldErr := range results[0].Errors {
+		paths[fieldErr.Path] = true
+	}
+	for _, path := range []string{"/id", "/specversion", "/data/customData"} {
+		if !paths[path] {
+			t.Errorf("expected an error at %s, got %v", path, results[0].Errors)
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	results := checkDocuments(t, "["+validCloudEvent+`, {"context": {"type": "dev.cdevents.unknown.happened.0.1.0"}}]`)
+	if validation.Failed(results) != 1 {
+		t.Fatalf("expected 1 failed result, got %+v", results)
+	}
+
+	var text bytes.Buffer
+	if err := validation.WriteReport(&text, "text", results); err != nil {
+		t.Fatalf("failed to write text report: %v", err)
+	}
+	for _, want := range []string{"PASS input#1", "FAIL input#2", "/context/type: no schema", "2 events, 1 passed, 1 failed"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text report should contain %q, got:\n%s", want, text.String())
+		}
+	}
+
+	var jsonReport bytes.Buffer
+	if err := validation.WriteReport(&jsonReport, "json", results); err != nil {
+		t.Fatalf("failed to write JSON report: %v", err)
+	}
+	var decoded struct {
+		Total   int                 `json:"total"`
+		Failed  int                 `json:"failed"`
+		Results []validation.Result `json:"results"`
+	}
+	if err := json.Unmarshal(jsonReport.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON report should be valid JSON: %v", err)
+	}
+	if decoded.Total != 2 || decoded.Failed != 1 || decoded.Results[1].Errors[0].Path != "/context/type" {
+		t.Errorf("unexpected JSON report: %s", jsonReport.String())
+	}
+
+	var junit bytes.Buffer
+	if err := validation.WriteReport(&junit, "junit", results); err != nil {
+		t.Fatalf("failed to write JUnit report: %v", err)
+	}
+	for _, want := range []string{`<testsuite name="cdevents-validate" tests="2" failures="1">`, `<failure message="1 validation error(s)">`} {
+		if !strings.Contains(junit.String(), want) {
+			t.Errorf("JUnit report should contain %q, got:\n%s", want, junit.String())
+		}
+	}
+
+	// Warnings are reported without failing the result
+	results[0].Warnings = []validation.FieldError{{Path: "/context/source", Message: "source should be a URL (source-url)"}}
+	text.Reset()
+	junit.Reset()
+	if err := validation.WriteReport(&text, "text", results); err != nil {
+		t.Fatalf("failed to write text report: %v", err)
+	}
+	if !strings.Contains(text.String(), "  warning: /context/source: source should be a URL (source-url)") {
+		t.Errorf("text report should contain the warning, got:\n%s", text.String())
+	}
+	if err := validation.WriteReport(&junit, "junit", results); err != nil {
+		t.Fatalf("failed to write JUnit report: %v", err)
+	}
+	if !strings.Contains(junit.String(), "<system-out>warning: /context/source") {
+		t.Errorf("JUnit report should contain the warning, got:\n%s", junit.String())
+	}
+
+	if err := validation.WriteReport(&text, "invalid", results); err == nil {
+		t.Errorf("expected an error for an unsupported report format")
+	}
+}
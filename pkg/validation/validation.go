@@ -0,0 +1,361 @@
+package validation
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/cdevents/sdk-go/pkg/api"
+	jsonschema "github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// schemaFS holds the CDEvents JSON schemas, one directory per spec version
+//
+//go:embed schemas
+var schemaFS embed.FS
+
+// customTypePrefix is the type prefix of custom events, validated against the custom schema
+const customTypePrefix = "dev.cdeventsx."
+
+// customSchemaURLTemplate is the ID of the custom event schema for a spec version
+const customSchemaURLTemplate = "https://cdevents.dev/%s/schema/custom"
+
+// FieldError describes a single schema violation
+type FieldError struct {
+	// Path is the JSON pointer of the offending value within the event
+	Path string `json:"path"`
+	// Message describes the violation
+	Message string `json:"message"`
+}
+
+// String returns the error as "path: message"
+func (e FieldError) String() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+// ValidationError is returned when an event does not match its schema
+type ValidationError struct {
+	// Type is the event type that was validated
+	Type string
+	// Errors lists the schema violations ordered by path
+	Errors []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		lines = append(lines, "  "+fieldErr.String())
+	}
+	return fmt.Sprintf("event %s does not match its schema:\n%s", e.Type, strings.Join(lines, "\n"))
+}
+
+// Validator validates CDEvents against the embedded JSON schemas of their spec version
+type Validator struct {
+	// schemas holds the compiled event schemas by spec version and event type
+	schemas map[string]map[string]*jsonschema.Schema
+	// customSchemas holds the compiled custom event schemas by schema ID
+	customSchemas map[string]*jsonschema.Schema
+}
+
+var (
+	defaultValidator     *Validator
+	defaultValidatorErr  error
+	defaultValidatorOnce sync.Once
+)
+
+// Default returns a shared Validator loaded with the embedded schemas
+func Default() (*Validator, error) {
+	defaultValidatorOnce.Do(func() {
+		defaultValidator, defaultValidatorErr = NewValidator()
+	})
+	return defaultValidator, defaultValidatorErr
+}
+
+// NewValidator creates a Validator loaded with the embedded schemas
+func NewValidator() (*Validator, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+
+	// specVersions holds the spec version of each schema ID, taken from its directory
+	specVersions := map[string]string{}
+	var ids []string
+	err := fs.WalkDir(schemaFS, "schemas", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		data, err := schemaFS.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read schema %s: %w", path, err)
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse schema %s: %w", path, err)
+		}
+		id, _ := doc.(map[string]interface{})["$id"].(string)
+		if id == "" {
+			return fmt.Errorf("schema %s has no $id", path)
+		}
+		if err := compiler.AddResource(id, doc); err != nil {
+			return fmt.Errorf("failed to load schema %s: %w", path, err)
+		}
+		ids = append(ids, id)
+		specVersions[id] = strings.TrimPrefix(strings.Split(path, "/")[1], "v")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Validator{
+		schemas:       map[string]map[string]*jsonschema.Schema{},
+		customSchemas: map[string]*jsonschema.Schema{},
+	}
+	for _, id := range ids {
+		if strings.Contains(id, "/schema/links/") {
+			continue
+		}
+		schema, err := compiler.Compile(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile schema %s: %w", id, err)
+		}
+		if strings.HasSuffix(id, "/schema/custom") {
+			v.customSchemas[id] = schema
+			continue
+		}
+		version := specVersions[id]
+		if v.schemas[version] == nil {
+			v.schemas[version] = map[string]*jsonschema.Schema{}
+		}
+		for _, eventType := range schemaEventTypes(schema) {
+			v.schemas[version][eventType] = schema
+		}
+	}
+	return v, nil
+}
+
+// schemaEventTypes returns the event types accepted by an event schema
+func schemaEventTypes(schema *jsonschema.Schema) []string {
+	context, ok := schema.Properties["context"]
+	if !ok {
+		return nil
+	}
+	typeSchema, ok := context.Properties["type"]
+	if !ok || typeSchema.Enum == nil {
+		return nil
+	}
+	var types []string
+	for _, value := range typeSchema.Enum.Values {
+		if s, ok := value.(string); ok {
+			types = append(types, s)
+		}
+	}
+	return types
+}
+
+// EventTypes returns the event types the validator has schemas for, in any spec version
+func (v *Validator) EventTypes() []string {
+	seen := map[string]bool{}
+	var types []string
+	for _, schemas := range v.schemas {
+		for eventType := range schemas {
+			if !seen[eventType] {
+				seen[eventType] = true
+				types = append(types, eventType)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// SpecVersions returns the spec versions the validator has schemas for
+func (v *Validator) SpecVersions() []string {
+	versions := make([]string, 0, len(v.schemas))
+	for version := range v.schemas {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// ValidateEvent validates an event against the schema of its type
+func (v *Validator) ValidateEvent(event api.CDEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return v.ValidateJSON(data)
+}
+
+// ValidateJSON validates a JSON encoded event against the schema of its type
+func (v *Validator) ValidateJSON(data []byte) error {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse event: %w", err)
+	}
+	eventMap, ok := doc.(map[string]interface{})
+	if !ok {
+		return errors.New("event must be a JSON object")
+	}
+	return v.ValidateMap(eventMap)
+}
+
+// ValidateMap validates a decoded event against the schema of its type
+func (v *Validator) ValidateMap(eventMap map[string]interface{}) error {
+	eventType, errs := v.checkEvent(eventMap)
+	if len(errs) > 0 {
+		return &ValidationError{Type: eventType, Errors: errs}
+	}
+	return nil
+}
+
+// ValidateDocument validates a decoded CDEvent or structured CloudEvent carrying a CDEvent
+func (v *Validator) ValidateDocument(doc map[string]interface{}) error {
+	if !isCloudEvent(doc) {
+		return v.ValidateMap(doc)
+	}
+	eventType, errs := v.checkCloudEvent(doc)
+	if len(errs) > 0 {
+		return &ValidationError{Type: eventType, Errors: errs}
+	}
+	return nil
+}
+
+// checkEvent returns the event type and the schema and custom data violations of a CDEvent
+func (v *Validator) checkEvent(eventMap map[string]interface{}) (string, []FieldError) {
+	context, _ := eventMap["context"].(map[string]interface{})
+	eventType, _ := context["type"].(string)
+	if eventType == "" {
+		return "", []FieldError{{Path: "/context", Message: "missing property 'type'"}}
+	}
+
+	schema, err := v.schemaFor(eventType, context)
+	if err != nil {
+		return eventType, []FieldError{{Path: "/context/type", Message: err.Error()}}
+	}
+
+	var errs []FieldError
+	if err := schema.Validate(eventMap); err != nil {
+		var schemaErr *jsonschema.ValidationError
+		if !errors.As(err, &schemaErr) {
+			return eventType, []FieldError{{Message: fmt.Sprintf("failed to validate event: %v", err)}}
+		}
+		errs = fieldErrors(schemaErr)
+	}
+	errs = append(errs, customDataErrors(eventMap)...)
+	sortFieldErrors(errs)
+	return eventType, errs
+}
+
+// schemaFor returns the schema used to validate events of the given type
+func (v *Validator) schemaFor(eventType string, context map[string]interface{}) (*jsonschema.Schema, error) {
+	if strings.HasPrefix(eventType, customTypePrefix) {
+		version := events.ContextVersion(context)
+		if schema, ok := v.customSchemas[fmt.Sprintf(customSchemaURLTemplate, version)]; ok {
+			return schema, nil
+		}
+		return nil, fmt.Errorf("no custom event schema for spec version %q", version)
+	}
+
+	version := events.ContextVersion(context)
+	if version == "" {
+		// Use any schema of the type so the missing version is reported against it
+		for _, specVersion := range v.SpecVersions() {
+			if schema, ok := v.schemas[specVersion][eventType]; ok {
+				return schema, nil
+			}
+		}
+		return nil, fmt.Errorf("no schema for event type %s", eventType)
+	}
+	schemas, ok := v.schemas[v.specVersion(version)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported spec version %q (supported: %s)", version, strings.Join(v.SpecVersions(), ", "))
+	}
+	if schema, ok := schemas[eventType]; ok {
+		return schema, nil
+	}
+	return nil, fmt.Errorf("no schema for event type %s in spec version %s", eventType, v.specVersion(version))
+}
+
+// specVersion returns the spec version with schemas matching the major and minor part of a version,
+// e.g. 0.4.1 for 0.4.0, or the version itself when there is none
+func (v *Validator) specVersion(version string) string {
+	if _, ok := v.schemas[version]; ok {
+		return version
+	}
+	for specVersion := range v.schemas {
+		if majorMinor(specVersion) == majorMinor(version) {
+			return specVersion
+		}
+	}
+	return version
+}
+
+// majorMinor returns the major and minor part of a version, e.g. 0.4 for 0.4.1
+func majorMinor(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// fieldErrors flattens a schema validation error into its leaf violations
+func fieldErrors(err *jsonschema.ValidationError) []FieldError {
+	printer := message.NewPrinter(language.English)
+	seen := map[FieldError]bool{}
+	var result []FieldError
+
+	var collect func(*jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				collect(cause)
+			}
+			return
+		}
+		fieldErr := FieldError{
+			Path:    jsonPointer(e.InstanceLocation),
+			Message: e.ErrorKind.LocalizedString(printer),
+		}
+		if !seen[fieldErr] {
+			seen[fieldErr] = true
+			result = append(result, fieldErr)
+		}
+	}
+	collect(err)
+	return result
+}
+
+// sortFieldErrors orders violations by path, keeping the order of violations at the same path
+func sortFieldErrors(errs []FieldError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Path < errs[j].Path
+	})
+}
+
+// jsonPointer builds an RFC 6901 JSON pointer from path tokens
+func jsonPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		sb.WriteString("/")
+		sb.WriteString(token)
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package validation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	cdeventsv04 "github.com/cdevents/sdk-go/pkg/api/v04"
+)
+
+func TestDefaultValidator(t *testing.T) {
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+
+	eventTypes := validator.EventTypes()
+	if len(eventTypes) == 0 {
+		t.Fatalf("validator should have event schemas")
+	}
+	found := false
+	for _, eventType := range eventTypes {
+		if eventType == "dev.cdevents.pipelinerun.started.0.2.0" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("validator should have the pipelinerun started schema, got %v", eventTypes)
+	}
+}
+
+func TestValidateEvent(t *testing.T) {
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+
+	event, err := cdeventsv04.NewPipelineRunStartedEvent()
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	event.SetSource("https://example.com/ci")
+	event.SetSubjectId("pipeline-123")
+	event.SetSubjectPipelineName("test-pipeline")
+
+	if err := validator.ValidateEvent(event); err != nil {
+		t.Errorf("valid event should pass validation: %v", err)
+	}
+}
+
+func TestValidateJSONReportsJSONPointers(t *testing.T) {
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+
+	event := `{
+		"context": {
+			"version": "0.4.1",
+			"id": "",
+			"source": "https://example.com/ci",
+			"type": "dev.cdevents.service.deployed.0.2.0",
+			"timestamp": "2024-01-01T00:00:00Z"
+		},
+		"subject": {
+			"id": "service-123",
+			"type": "service",
+			"content": {"environment": {"id": "prod"}, "unknown": true}
+		}
+	}`
+
+	err = validator.ValidateJSON([]byte(event))
+	var validationErr *validation.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	paths := map[string]string{}
+	for _, fieldErr := range validationErr.Errors {
+		paths[fieldErr.Path] += fieldErr.Message + "\n"
+	}
+	for _, path := range []string{"/context/id", "/subject/content"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("expected an error at %s, got %v", path, validationErr.Errors)
+		}
+	}
+	if !strings.Contains(paths["/subject/content"], "artifactId") {
+		t.Errorf("expected missing artifactId to be reported, got %q", paths["/subject/content"])
+	}
+	if !strings.Contains(err.Error(), "dev.cdevents.service.deployed.0.2.0") {
+		t.Errorf("error should name the event type, got %v", err)
+	}
+}
+
+func TestValidateJSONUnknownType(t *testing.T) {
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+
+	err = validator.ValidateJSON([]byte(`{"context": {"type": "dev.cdevents.unknown.happened.0.1.0"}}`))
+	if err == nil || !strings.Contains(err.Error(), "no schema") {
+		t.Errorf("expected an unknown type error, got %v", err)
+	}
+
+	err = validator.ValidateJSON([]byte(`{"subject": {}}`))
+	var validationErr *validation.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected a validation error for a missing type, got %v", err)
+	}
+}
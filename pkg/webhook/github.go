@@ -0,0 +1,298 @@
+package webhook
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+// VerifyGitHubSignature checks the X-Hub-Signature-256 header of a payload, the hex HMAC-SHA256 of the payload
+// keyed with the webhook secret
+func VerifyGitHubSignature(secret, signature string, payload []byte) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no webhook secret configured", ErrUnauthorized)
+	}
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return fmt.Errorf("%w: missing X-Hub-Signature-256 signature", ErrUnauthorized)
+	}
+	received, err := hex.DecodeString(digest)
+	if err != nil {
+		return fmt.Errorf("%w: malformed X-Hub-Signature-256 signature", ErrUnauthorized)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(received, mac.Sum(nil)) {
+		return fmt.Errorf("%w: signature does not match the secret", ErrUnauthorized)
+	}
+	return nil
+}
+
+// NewGitHubHandler creates a handler for GitHub webhooks, verifying their signature with secret.
+// Without secret, all webhooks are rejected.
+func NewGitHubHandler(factory *events.EventFactory, secret string, receiver *transport.Receiver) *Handler {
+	verify := func(header http.Header, payload []byte) error {
+		return VerifyGitHubSignature(secret, header.Get("X-Hub-Signature-256"), payload)
+	}
+	return NewHandler(verify, GitHubTranslator(factory), receiver)
+}
+
+// GitHubTranslator returns the translator of GitHub webhook requests, by their X-GitHub-Event header
+func GitHubTranslator(factory *events.EventFactory) Translator {
+	return func(header http.Header, payload []byte) ([]api.CDEvent, error) {
+		return TranslateGitHub(factory, header.Get("X-GitHub-Event"), payload)
+	}
+}
+
+// githubRepository is the repository of a GitHub webhook payload
+type githubRepository struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+// githubUser is a user of a GitHub webhook payload
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// githubPayload holds the fields of the translated GitHub webhook payloads
+type githubPayload struct {
+	Action     string           `json:"action"`
+	Repository githubRepository `json:"repository"`
+	Sender     githubUser       `json:"sender"`
+
+	// push
+	Ref     string `json:"ref"`
+	Created bool   `json:"created"`
+	Deleted bool   `json:"deleted"`
+
+	PullRequest *struct {
+		Number    int       `json:"number"`
+		Title     string    `json:"title"`
+		HTMLURL   string    `json:"html_url"`
+		Merged    bool      `json:"merged"`
+		UpdatedAt time.Time `json:"updated_at"`
+	} `json:"pull_request"`
+
+	WorkflowRun *struct {
+		ID         int64     `json:"id"`
+		Name       string    `json:"name"`
+		HTMLURL    string    `json:"html_url"`
+		Conclusion string    `json:"conclusion"`
+		UpdatedAt  time.Time `json:"updated_at"`
+	} `json:"workflow_run"`
+
+	WorkflowJob *struct {
+		ID          int64     `json:"id"`
+		RunID       int64     `json:"run_id"`
+		Name        string    `json:"name"`
+		HTMLURL     string    `json:"html_url"`
+		Conclusion  string    `json:"conclusion"`
+		StartedAt   time.Time `json:"started_at"`
+		CompletedAt time.Time `json:"completed_at"`
+	} `json:"workflow_job"`
+
+	Release *struct {
+		TagName     string    `json:"tag_name"`
+		PublishedAt time.Time `json:"published_at"`
+	} `json:"release"`
+
+	Deployment *struct {
+		SHA         string    `json:"sha"`
+		Environment string    `json:"environment"`
+		CreatedAt   time.Time `json:"created_at"`
+	} `json:"deployment"`
+
+	DeploymentStatus *struct {
+		State       string    `json:"state"`
+		Environment string    `json:"environment"`
+		CreatedAt   time.Time `json:"created_at"`
+	} `json:"deployment_status"`
+
+	Issue *struct {
+		Number    int          `json:"number"`
+		Title     string       `json:"title"`
+		HTMLURL   string       `json:"html_url"`
+		User      githubUser   `json:"user"`
+		Assignees []githubUser `json:"assignees"`
+		Labels    []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Milestone *struct {
+			Title string `json:"title"`
+		} `json:"milestone"`
+		StateReason string    `json:"state_reason"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	} `json:"issue"`
+}
+
+// TranslateGitHub translates the payload of a GitHub webhook into CDEvents, eventType is its X-GitHub-Event header.
+// Events are sourced from the repository. Event types and actions without CDEvents counterpart, such as ping
+// or pushes of commits, translate into no events.
+func TranslateGitHub(factory *events.EventFactory, eventType string, payload []byte) ([]api.CDEvent, error) {
+	var p githubPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub %s payload: %w", eventType, err)
+	}
+	repository := p.Repository.FullName
+	at := func(timestamp time.Time) []events.EventOption {
+		return eventOptions(p.Repository.HTMLURL, timestamp)
+	}
+
+	var event api.CDEvent
+	var err error
+	switch eventType {
+	case "push":
+		branch, ok := strings.CutPrefix(p.Ref, "refs/heads/")
+		switch {
+		case !ok:
+		case p.Created:
+			event, err = factory.CreateBranchEvent("created", branch, repository, nil, at(time.Time{})...)
+		case p.Deleted:
+			event, err = factory.CreateBranchEvent("deleted", branch, repository, nil, at(time.Time{})...)
+		}
+
+	case "pull_request":
+		pr := p.PullRequest
+		if pr == nil {
+			return nil, fmt.Errorf("GitHub pull_request payload has no pull_request")
+		}
+		predicate := map[string]string{
+			"opened": "created", "reopened": "created", "edited": "updated", "synchronize": "updated",
+			"ready_for_review": "updated", "closed": "abandoned",
+		}[p.Action]
+		if p.Action == "closed" && pr.Merged {
+			predicate = "merged"
+		}
+		if predicate != "" {
+			event, err = factory.CreateChangeEvent(predicate, strconv.Itoa(pr.Number), repository, pr.Title, nil, at(pr.UpdatedAt)...)
+		}
+
+	case "workflow_run":
+		run := p.WorkflowRun
+		if run == nil {
+			return nil, fmt.Errorf("GitHub workflow_run payload has no workflow_run")
+		}
+		predicate := map[string]string{"requested": "queued", "in_progress": "started", "completed": "finished"}[p.Action]
+		if predicate != "" {
+			outcome, errors := githubOutcome(predicate, run.Conclusion)
+			event, err = factory.CreatePipelineRunEvent(predicate, strconv.FormatInt(run.ID, 10), run.Name, outcome, errors, run.HTMLURL, nil, at(run.UpdatedAt)...)
+		}
+
+	case "workflow_job":
+		job := p.WorkflowJob
+		if job == nil {
+			return nil, fmt.Errorf("GitHub workflow_job payload has no workflow_job")
+		}
+		switch p.Action {
+		case "in_progress":
+			event, err = factory.CreateTaskRunEvent("started", strconv.FormatInt(job.ID, 10), job.Name, strconv.FormatInt(job.RunID, 10), "", "", job.HTMLURL, nil, at(job.StartedAt)...)
+		case "completed":
+			outcome, errors := githubOutcome("finished", job.Conclusion)
+			event, err = factory.CreateTaskRunEvent("finished", strconv.FormatInt(job.ID, 10), job.Name, strconv.FormatInt(job.RunID, 10), outcome, errors, job.HTMLURL, nil, at(job.CompletedAt)...)
+		}
+
+	case "release":
+		if p.Release == nil {
+			return nil, fmt.Errorf("GitHub release payload has no release")
+		}
+		if p.Action == "published" {
+			event, err = factory.CreateArtifactEvent("published", githubPURL(repository, p.Release.TagName), "", nil, at(p.Release.PublishedAt)...)
+		}
+
+	case "deployment_status":
+		status, deployment := p.DeploymentStatus, p.Deployment
+		if status == nil || deployment == nil {
+			return nil, fmt.Errorf("GitHub deployment_status payload has no deployment or deployment_status")
+		}
+		if status.State == "success" {
+			environment := cmp.Or(status.Environment, deployment.Environment)
+			event, err = factory.CreateServiceEvent("deployed", p.Repository.Name, p.Repository.Name, environment, "", nil,
+				append(at(status.CreatedAt), events.WithArtifactID(githubPURL(repository, deployment.SHA)))...)
+		}
+
+	case "issues":
+		issue := p.Issue
+		if issue == nil {
+			return nil, fmt.Errorf("GitHub issues payload has no issue")
+		}
+		ticket := events.Ticket{
+			Summary: issue.Title,
+			Creator: issue.User.Login,
+			URI:     issue.HTMLURL,
+		}
+		for _, assignee := range issue.Assignees {
+			ticket.Assignees = append(ticket.Assignees, assignee.Login)
+		}
+		for _, label := range issue.Labels {
+			ticket.Labels = append(ticket.Labels, label.Name)
+		}
+		if issue.Milestone != nil {
+			ticket.Milestone = issue.Milestone.Title
+		}
+		predicate := "updated"
+		switch p.Action {
+		case "opened":
+			predicate = "created"
+		case "closed":
+			predicate = "closed"
+			ticket.Resolution = map[string]string{"not_planned": "withdrawn", "duplicate": "duplicate"}[issue.StateReason]
+			if ticket.Resolution == "" {
+				ticket.Resolution = "completed"
+			}
+		case "deleted", "transferred", "pinned", "unpinned", "locked", "unlocked":
+			predicate = ""
+		}
+		if predicate != "created" {
+			ticket.UpdatedBy = p.Sender.Login
+		}
+		if predicate != "" {
+			event, err = factory.CreateTicketEvent(predicate, strconv.Itoa(issue.Number), ticket, nil, at(issue.UpdatedAt)...)
+		}
+
+	case "deployment":
+		// Deployments are requests to deploy, the service is deployed once their status is success
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate GitHub %s %s: %w", eventType, p.Action, err)
+	}
+	if event == nil {
+		return nil, nil
+	}
+	return []api.CDEvent{event}, nil
+}
+
+// githubOutcome maps the conclusion of finished workflow runs and jobs to an outcome and errors
+func githubOutcome(predicate, conclusion string) (string, string) {
+	if predicate != "finished" {
+		return "", ""
+	}
+	switch conclusion {
+	case "success", "neutral", "skipped":
+		return "success", ""
+	case "cancelled":
+		return "cancel", "cancelled"
+	case "failure", "timed_out", "startup_failure":
+		return "failure", conclusion
+	default:
+		return "error", conclusion
+	}
+}
+
+// githubPURL returns the package URL of a version of a GitHub repository, such as a tag or commit
+func githubPURL(repository, version string) string {
+	return "pkg:github/" + strings.ToLower(repository) + "@" + version
+}
@@ -0,0 +1,355 @@
+package webhook_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/brunseba/cdevents-tools/pkg/webhook"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+const githubRepository = `"repository": {"name": "hello-world", "full_name": "Octo-Org/hello-world", "html_url": "https://github.com/Octo-Org/hello-world"},
+	"sender": {"login": "octocat"}`
+
+func TestTranslateGitHub(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		// want is the subject and predicate of the translated event, empty for no event
+		want    string
+		subject string
+		check   func(t *testing.T, event map[string]interface{})
+	}{
+		{
+			name:      "branch created",
+			eventType: "push",
+			payload:   `{"ref": "refs/heads/feature", "created": true, ` + githubRepository + `}`,
+			want:      "branch.created",
+			subject:   "feature",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if id := subjectField(event, "repository", "id"); id != "Octo-Org/hello-world" {
+					t.Errorf("unexpected repository %v", id)
+				}
+			},
+		},
+		{
+			name:      "commits pushed",
+			eventType: "push",
+			payload:   `{"ref": "refs/heads/main", ` + githubRepository + `}`,
+		},
+		{
+			name:      "tag created",
+			eventType: "push",
+			payload:   `{"ref": "refs/tags/v1.0.0", "created": true, ` + githubRepository + `}`,
+		},
+		{
+			name:      "pull request opened",
+			eventType: "pull_request",
+			payload:   `{"action": "opened", "pull_request": {"number": 42, "title": "Add feature", "updated_at": "2024-05-01T12:00:00Z"}, ` + githubRepository + `}`,
+			want:      "change.created",
+			subject:   "42",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if timestamp := event["context"].(map[string]interface{})["timestamp"]; timestamp != "2024-05-01T12:00:00Z" {
+					t.Errorf("expected the timestamp of the pull request, got %v", timestamp)
+				}
+			},
+		},
+		{
+			name:      "pull request merged",
+			eventType: "pull_request",
+			payload:   `{"action": "closed", "pull_request": {"number": 42, "merged": true}, ` + githubRepository + `}`,
+			want:      "change.merged",
+			subject:   "42",
+		},
+		{
+			name:      "pull request closed",
+			eventType: "pull_request",
+			payload:   `{"action": "closed", "pull_request": {"number": 42}, ` + githubRepository + `}`,
+			want:      "change.abandoned",
+			subject:   "42",
+		},
+		{
+			name:      "pull request labeled",
+			eventType: "pull_request",
+			payload:   `{"action": "labeled", "pull_request": {"number": 42}, ` + githubRepository + `}`,
+		},
+		{
+			name:      "workflow run failed",
+			eventType: "workflow_run",
+			payload:   `{"action": "completed", "workflow_run": {"id": 30433642, "name": "Build", "html_url": "https://github.com/Octo-Org/hello-world/actions/runs/30433642", "conclusion": "failure"}, ` + githubRepository + `}`,
+			want:      "pipelinerun.finished",
+			subject:   "30433642",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if outcome := subjectField(event, "outcome"); outcome != "failure" {
+					t.Errorf("expected the failure outcome, got %v", outcome)
+				}
+			},
+		},
+		{
+			name:      "workflow job started",
+			eventType: "workflow_job",
+			payload:   `{"action": "in_progress", "workflow_job": {"id": 29679449, "run_id": 30433642, "name": "test"}, ` + githubRepository + `}`,
+			want:      "taskrun.started",
+			subject:   "29679449",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if id := subjectField(event, "pipelineRun", "id"); id != "30433642" {
+					t.Errorf("expected the workflow run as pipeline run, got %v", id)
+				}
+			},
+		},
+		{
+			name:      "workflow job queued",
+			eventType: "workflow_job",
+			payload:   `{"action": "queued", "workflow_job": {"id": 29679449, "run_id": 30433642, "name": "test"}, ` + githubRepository + `}`,
+		},
+		{
+			name:      "release published",
+			eventType: "release",
+			payload:   `{"action": "published", "release": {"tag_name": "v1.0.0"}, ` + githubRepository + `}`,
+			want:      "artifact.published",
+			subject:   "pkg:github/octo-org/hello-world@v1.0.0",
+		},
+		{
+			name:      "deployment",
+			eventType: "deployment",
+			payload:   `{"action": "created", "deployment": {"sha": "a1b2c3", "environment": "production"}, ` + githubRepository + `}`,
+		},
+		{
+			name:      "deployment succeeded",
+			eventType: "deployment_status",
+			payload:   `{"action": "created", "deployment_status": {"state": "success"}, "deployment": {"sha": "a1b2c3", "environment": "production"}, ` + githubRepository + `}`,
+			want:      "service.deployed",
+			subject:   "hello-world",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if id := subjectField(event, "environment", "id"); id != "production" {
+					t.Errorf("unexpected environment %v", id)
+				}
+				if artifact := subjectField(event, "artifactId"); artifact != "pkg:github/octo-org/hello-world@a1b2c3" {
+					t.Errorf("unexpected artifact %v", artifact)
+				}
+			},
+		},
+		{
+			name:      "deployment failed",
+			eventType: "deployment_status",
+			payload:   `{"action": "created", "deployment_status": {"state": "failure"}, "deployment": {"sha": "a1b2c3", "environment": "production"}, ` + githubRepository + `}`,
+		},
+		{
+			name:      "issue opened",
+			eventType: "issues",
+			payload:   `{"action": "opened", "issue": {"number": 7, "title": "Crash", "html_url": "https://github.com/Octo-Org/hello-world/issues/7", "user": {"login": "hubot"}, "labels": [{"name": "bug"}]}, ` + githubRepository + `}`,
+			want:      "ticket.created",
+			subject:   "7",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if creator := subjectField(event, "creator"); creator != "hubot" {
+					t.Errorf("unexpected creator %v", creator)
+				}
+			},
+		},
+		{
+			name:      "issue closed as not planned",
+			eventType: "issues",
+			payload:   `{"action": "closed", "issue": {"number": 7, "title": "Crash", "html_url": "https://github.com/Octo-Org/hello-world/issues/7", "state_reason": "not_planned"}, ` + githubRepository + `}`,
+			want:      "ticket.closed",
+			subject:   "7",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if resolution := subjectField(event, "resolution"); resolution != "withdrawn" {
+					t.Errorf("unexpected resolution %v", resolution)
+				}
+				if updatedBy := subjectField(event, "updatedBy"); updatedBy != "octocat" {
+					t.Errorf("expected the sender to update the ticket, got %v", updatedBy)
+				}
+			},
+		},
+		{
+			name:      "ping",
+			eventType: "ping",
+			payload:   `{"zen": "Keep it logically awesome.", "hook_id": 1}`,
+		},
+	}
+
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	factory := events.NewEventFactory("test-source")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translated, err := webhook.TranslateGitHub(factory, tt.eventType, []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("failed to translate: %v", err)
+			}
+			if tt.want == "" {
+				if len(translated) != 0 {
+					t.Fatalf("expected no events, got %s", translated[0].GetType())
+				}
+				return
+			}
+			if len(translated) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(translated))
+			}
+			event := translated[0]
+			if eventType := event.GetType().String(); !strings.HasPrefix(eventType, "dev.cdevents."+tt.want+".") {
+				t.Errorf("expected a %s event, got %s", tt.want, eventType)
+			}
+			if event.GetSubjectId() != tt.subject {
+				t.Errorf("expected subject %s, got %s", tt.subject, event.GetSubjectId())
+			}
+			if event.GetSource() != "https://github.com/Octo-Org/hello-world" {
+				t.Errorf("expected the repository as source, got %s", event.GetSource())
+			}
+			if err := validator.ValidateEvent(event); err != nil {
+				t.Errorf("translated event is invalid: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, eventMap(t, event))
+			}
+		})
+	}
+}
+
+func TestTranslateGitHubErrors(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+	if _, err := webhook.TranslateGitHub(factory, "push", []byte("not json")); err == nil {
+		t.Error("expected an error for a payload that is not JSON")
+	}
+	if _, err := webhook.TranslateGitHub(factory, "pull_request", []byte(`{"action": "opened"}`)); err == nil {
+		t.Error("expected an error for a pull_request payload without pull request")
+	}
+
+	// Ticket events are not defined in spec version 0.3
+	factory, err := events.NewEventFactoryForSpecVersion("test-source", "0.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := webhook.TranslateGitHub(factory, "issues", []byte(`{"action": "opened", "issue": {"number": 7}}`)); err == nil {
+		t.Error("expected an error for issues with spec version 0.3")
+	}
+}
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	payload := []byte(`{"zen": "Design for failure."}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := webhook.VerifyGitHubSignature("secret", signature, payload); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+	for name, signature := range map[string]string{
+		"wrong secret": signature,
+		"missing":      "",
+		"malformed":    "sha256=zz",
+	} {
+		secret := "secret"
+		if name == "wrong secret" {
+			secret = "other"
+		}
+		if err := webhook.VerifyGitHubSignature(secret, signature, payload); !errors.Is(err, webhook.ErrUnauthorized) {
+			t.Errorf("%s: expected ErrUnauthorized, got %v", name, err)
+		}
+	}
+	// Signatures made without secret are not trusted
+	emptyMAC := hmac.New(sha256.New, nil)
+	emptyMAC.Write(payload)
+	if err := webhook.VerifyGitHubSignature("", "sha256="+hex.EncodeToString(emptyMAC.Sum(nil)), payload); !errors.Is(err, webhook.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized without secret, got %v", err)
+	}
+}
+
+func TestGitHubHandler(t *testing.T) {
+	var received []api.CDEvent
+	receiver := transport.NewReceiver(func(_ context.Context, event api.CDEvent) error {
+		if event.GetSubjectId() == "rejected" {
+			return errors.New("subject rejected")
+		}
+		if event.GetSubjectId() == "undelivered" {
+			return transport.ErrNotDelivered
+		}
+		received = append(received, event)
+		return nil
+	})
+	server := httptest.NewServer(webhook.NewGitHubHandler(events.NewEventFactory("test-source"), "secret", receiver))
+	defer server.Close()
+
+	post := func(eventType, payload, secret string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-GitHub-Event", eventType)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to post: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	branch := func(name string) string {
+		return `{"ref": "refs/heads/` + name + `", "created": true, ` + githubRepository + `}`
+	}
+
+	if status := post("push", branch("feature"), "secret"); status != http.StatusAccepted {
+		t.Errorf("expected 202 for a signed payload, got %d", status)
+	}
+	if status := post("ping", `{"zen": "Speak like a human."}`, "secret"); status != http.StatusAccepted {
+		t.Errorf("expected 202 for a payload without events, got %d", status)
+	}
+	if status := post("push", branch("other"), "wrong"); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a payload signed with another secret, got %d", status)
+	}
+	if status := post("push", "not json", "secret"); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a payload that can't be translated, got %d", status)
+	}
+	if status := post("push", branch("rejected"), "secret"); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a rejected event, got %d", status)
+	}
+	if status := post("push", branch("undelivered"), "secret"); status != http.StatusBadGateway {
+		t.Errorf("expected 502 for an event that was not delivered, got %d", status)
+	}
+	if resp, err := http.Get(server.URL); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET requests, got %v, %v", resp, err)
+	}
+	if len(received) != 1 || received[0].GetSubjectId() != "feature" {
+		t.Errorf("expected the feature branch event to be received, got %v", received)
+	}
+}
+
+// eventMap returns the JSON representation of an event
+func eventMap(t *testing.T, event api.CDEvent) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	return m
+}
+
+// subjectField returns a field of the subject content of an event, nested fields by path
+func subjectField(event map[string]interface{}, path ...string) interface{} {
+	var value interface{} = event["subject"].(map[string]interface{})["content"]
+	for _, key := range path {
+		m, _ := value.(map[string]interface{})
+		value = m[key]
+	}
+	return value
+}
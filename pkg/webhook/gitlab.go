@@ -0,0 +1,305 @@
+package webhook
+
+import (
+	"cmp"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+// gitlabNullSHA is the before or after commit of pushes creating or deleting a branch
+const gitlabNullSHA = "0000000000000000000000000000000000000000"
+
+// VerifyGitLabToken checks the X-Gitlab-Token header of a webhook against its secret token
+func VerifyGitLabToken(secret, token string) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no webhook secret token configured", ErrUnauthorized)
+	}
+	if token == "" {
+		return fmt.Errorf("%w: missing X-Gitlab-Token token", ErrUnauthorized)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return fmt.Errorf("%w: token does not match the secret", ErrUnauthorized)
+	}
+	return nil
+}
+
+// NewGitLabHandler creates a handler for GitLab webhooks, verifying their token against secret.
+// Without secret, all webhooks are rejected.
+func NewGitLabHandler(factory *events.EventFactory, secret string, receiver *transport.Receiver) *Handler {
+	verify := func(header http.Header, _ []byte) error {
+		return VerifyGitLabToken(secret, header.Get("X-Gitlab-Token"))
+	}
+	return NewHandler(verify, GitLabTranslator(factory), receiver)
+}
+
+// GitLabTranslator returns the translator of GitLab webhook requests
+func GitLabTranslator(factory *events.EventFactory) Translator {
+	return func(_ http.Header, payload []byte) ([]api.CDEvent, error) {
+		return TranslateGitLab(factory, payload)
+	}
+}
+
+// gitlabTime is a time of a GitLab webhook payload, which are formatted as RFC 3339 or as 2006-01-02 15:04:05 UTC
+// depending on the hook and GitLab version
+type gitlabTime struct {
+	time.Time
+}
+
+func (t *gitlabTime) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported time %q", value)
+}
+
+// gitlabUser is a user of a GitLab webhook payload
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+// gitlabPayload holds the fields of the translated GitLab webhook payloads
+type gitlabPayload struct {
+	ObjectKind string `json:"object_kind"`
+	Project    struct {
+		Name              string `json:"name"`
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+	// Repository is set instead of project by hooks of older GitLab versions
+	Repository struct {
+		Homepage string `json:"homepage"`
+	} `json:"repository"`
+	User gitlabUser `json:"user"`
+
+	// push
+	Ref    string `json:"ref"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+
+	// merge_request, pipeline and issue
+	ObjectAttributes *struct {
+		ID         int64      `json:"id"`
+		IID        int64      `json:"iid"`
+		Title      string     `json:"title"`
+		Name       string     `json:"name"`
+		URL        string     `json:"url"`
+		Action     string     `json:"action"`
+		Status     string     `json:"status"`
+		Type       string     `json:"type"`
+		CreatedAt  gitlabTime `json:"created_at"`
+		UpdatedAt  gitlabTime `json:"updated_at"`
+		FinishedAt gitlabTime `json:"finished_at"`
+	} `json:"object_attributes"`
+	Assignees []gitlabUser `json:"assignees"`
+	Labels    []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+
+	// build, the object kind of job hooks
+	BuildID            int64      `json:"build_id"`
+	BuildName          string     `json:"build_name"`
+	BuildStatus        string     `json:"build_status"`
+	BuildStartedAt     gitlabTime `json:"build_started_at"`
+	BuildFinishedAt    gitlabTime `json:"build_finished_at"`
+	BuildFailureReason string     `json:"build_failure_reason"`
+	PipelineID         int64      `json:"pipeline_id"`
+
+	// deployment
+	Status          string     `json:"status"`
+	StatusChangedAt gitlabTime `json:"status_changed_at"`
+	Environment     string     `json:"environment"`
+	ShortSHA        string     `json:"short_sha"`
+	CommitURL       string     `json:"commit_url"`
+
+	// release
+	Action     string     `json:"action"`
+	Tag        string     `json:"tag"`
+	ReleasedAt gitlabTime `json:"released_at"`
+}
+
+// TranslateGitLab translates the payload of a GitLab webhook into CDEvents, by its object_kind. Events are sourced
+// from the project. Hooks and actions without CDEvents counterpart, such as notes or pushes of commits,
+// translate into no events.
+func TranslateGitLab(factory *events.EventFactory, payload []byte) ([]api.CDEvent, error) {
+	var p gitlabPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab payload: %w", err)
+	}
+	if p.ObjectKind == "" {
+		return nil, fmt.Errorf("failed to parse GitLab payload: no object_kind")
+	}
+	repository := p.Project.PathWithNamespace
+	webURL := cmp.Or(p.Project.WebURL, p.Repository.Homepage)
+	at := func(timestamp gitlabTime) []events.EventOption {
+		return eventOptions(webURL, timestamp.Time)
+	}
+	attributes := p.ObjectAttributes
+	if attributes == nil && (p.ObjectKind == "merge_request" || p.ObjectKind == "pipeline" || p.ObjectKind == "issue") {
+		return nil, fmt.Errorf("GitLab %s payload has no object_attributes", p.ObjectKind)
+	}
+
+	var event api.CDEvent
+	var err error
+	switch p.ObjectKind {
+	case "push":
+		branch, ok := strings.CutPrefix(p.Ref, "refs/heads/")
+		switch {
+		case !ok:
+		case p.Before == gitlabNullSHA:
+			event, err = factory.CreateBranchEvent("created", branch, repository, nil, at(gitlabTime{})...)
+		case p.After == gitlabNullSHA:
+			event, err = factory.CreateBranchEvent("deleted", branch, repository, nil, at(gitlabTime{})...)
+		}
+
+	case "merge_request":
+		predicate := map[string]string{
+			"open": "created", "reopen": "created", "update": "updated", "approved": "reviewed",
+			"merge": "merged", "close": "abandoned",
+		}[attributes.Action]
+		if predicate != "" {
+			event, err = factory.CreateChangeEvent(predicate, strconv.FormatInt(attributes.IID, 10), repository, attributes.Title, nil, at(attributes.UpdatedAt)...)
+		}
+
+	case "pipeline":
+		predicate := gitlabPredicate(attributes.Status)
+		if attributes.Status == "pending" {
+			predicate = "queued"
+		}
+		if predicate != "" {
+			outcome, errors := gitlabOutcome(predicate, attributes.Status, "")
+			// Pipelines have no start time, started events happen when they are received
+			var timestamp gitlabTime
+			switch predicate {
+			case "queued":
+				timestamp = attributes.CreatedAt
+			case "finished":
+				timestamp = attributes.FinishedAt
+			}
+			name := cmp.Or(attributes.Name, repository)
+			event, err = factory.CreatePipelineRunEvent(predicate, strconv.FormatInt(attributes.ID, 10), name, outcome, errors, attributes.URL, nil, at(timestamp)...)
+		}
+
+	case "build":
+		predicate := gitlabPredicate(p.BuildStatus)
+		if predicate != "" {
+			outcome, errors := gitlabOutcome(predicate, p.BuildStatus, p.BuildFailureReason)
+			timestamp := p.BuildStartedAt
+			if predicate == "finished" {
+				timestamp = p.BuildFinishedAt
+			}
+			url := ""
+			if webURL != "" {
+				url = fmt.Sprintf("%s/-/jobs/%d", webURL, p.BuildID)
+			}
+			event, err = factory.CreateTaskRunEvent(predicate, strconv.FormatInt(p.BuildID, 10), p.BuildName, strconv.FormatInt(p.PipelineID, 10), outcome, errors, url, nil, at(timestamp)...)
+		}
+
+	case "deployment":
+		if p.Status == "success" {
+			// Services are identified by the path of their project, the name is for display
+			event, err = factory.CreateServiceEvent("deployed", path.Base(repository), p.Project.Name, p.Environment, "", nil,
+				append(at(p.StatusChangedAt), events.WithArtifactID(gitlabPURL(repository, gitlabCommitSHA(p.CommitURL, p.ShortSHA))))...)
+		}
+
+	case "release":
+		if p.Action == "create" {
+			event, err = factory.CreateArtifactEvent("published", gitlabPURL(repository, p.Tag), "", nil, at(p.ReleasedAt)...)
+		}
+
+	case "issue":
+		ticket := events.Ticket{
+			Summary:    attributes.Title,
+			TicketType: strings.ToLower(attributes.Type),
+			URI:        attributes.URL,
+		}
+		for _, assignee := range p.Assignees {
+			ticket.Assignees = append(ticket.Assignees, assignee.Username)
+		}
+		for _, label := range p.Labels {
+			ticket.Labels = append(ticket.Labels, label.Title)
+		}
+		predicate := map[string]string{"open": "created", "reopen": "updated", "update": "updated", "close": "closed"}[attributes.Action]
+		if predicate == "created" {
+			ticket.Creator = p.User.Username
+		} else {
+			ticket.UpdatedBy = p.User.Username
+		}
+		if predicate == "closed" {
+			// GitLab issues are closed without a reason
+			ticket.Resolution = "completed"
+		}
+		if predicate != "" {
+			event, err = factory.CreateTicketEvent(predicate, strconv.FormatInt(attributes.IID, 10), ticket, nil, at(attributes.UpdatedAt)...)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate GitLab %s: %w", p.ObjectKind, err)
+	}
+	if event == nil {
+		return nil, nil
+	}
+	return []api.CDEvent{event}, nil
+}
+
+// gitlabPredicate maps the status of pipelines and jobs to the predicate of their started and finished events.
+// Other statuses, such as created or manual, have no predicate.
+func gitlabPredicate(status string) string {
+	switch status {
+	case "running":
+		return "started"
+	case "success", "failed", "canceled", "skipped":
+		return "finished"
+	}
+	return ""
+}
+
+// gitlabOutcome maps the status of finished pipelines and jobs to an outcome and errors, the failure reason of
+// failed jobs when set
+func gitlabOutcome(predicate, status, failureReason string) (string, string) {
+	if predicate != "finished" {
+		return "", ""
+	}
+	switch status {
+	case "success", "skipped":
+		return "success", ""
+	case "canceled":
+		return "cancel", "canceled"
+	default:
+		return "failure", cmp.Or(failureReason, status)
+	}
+}
+
+// gitlabCommitSHA returns the full SHA of a deployed commit, the last segment of its URL. Deployment hooks
+// have no SHA field, the short SHA is only used when the URL is missing.
+func gitlabCommitSHA(commitURL, shortSHA string) string {
+	if sha := path.Base(commitURL); commitURL != "" && strings.HasPrefix(sha, shortSHA) {
+		return sha
+	}
+	return shortSHA
+}
+
+// gitlabPURL returns the package URL of a version of a GitLab project, such as a tag or commit
+func gitlabPURL(repository, version string) string {
+	return "pkg:gitlab/" + strings.ToLower(repository) + "@" + version
+}
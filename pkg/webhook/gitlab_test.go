@@ -0,0 +1,285 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/brunseba/cdevents-tools/pkg/validation"
+	"github.com/brunseba/cdevents-tools/pkg/webhook"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+const gitlabProject = `"project": {"name": "Gitlab Test", "path_with_namespace": "Gitlab-Org/gitlab-test", "web_url": "https://gitlab.example.com/Gitlab-Org/gitlab-test"},
+	"user": {"username": "root"}`
+
+func TestTranslateGitLab(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		// want is the subject and predicate of the translated event, empty for no event
+		want    string
+		subject string
+		check   func(t *testing.T, event map[string]interface{})
+	}{
+		{
+			name:    "branch created",
+			payload: `{"object_kind": "push", "ref": "refs/heads/feature", "before": "0000000000000000000000000000000000000000", "after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", ` + gitlabProject + `}`,
+			want:    "branch.created",
+			subject: "feature",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if id := subjectField(event, "repository", "id"); id != "Gitlab-Org/gitlab-test" {
+					t.Errorf("unexpected repository %v", id)
+				}
+			},
+		},
+		{
+			name:    "branch deleted",
+			payload: `{"object_kind": "push", "ref": "refs/heads/feature", "before": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", "after": "0000000000000000000000000000000000000000", ` + gitlabProject + `}`,
+			want:    "branch.deleted",
+			subject: "feature",
+		},
+		{
+			name:    "commits pushed",
+			payload: `{"object_kind": "push", "ref": "refs/heads/main", "before": "95790bf891e76fee5e1747ab589903a6a1f80f22", "after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", ` + gitlabProject + `}`,
+		},
+		{
+			name:    "tag pushed",
+			payload: `{"object_kind": "tag_push", "ref": "refs/tags/v1.0.0", "before": "0000000000000000000000000000000000000000", ` + gitlabProject + `}`,
+		},
+		{
+			name:    "merge request opened",
+			payload: `{"object_kind": "merge_request", "object_attributes": {"iid": 1, "title": "MS-Viewport", "action": "open", "updated_at": "2013-12-03 17:23:34 UTC"}, ` + gitlabProject + `}`,
+			want:    "change.created",
+			subject: "1",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if timestamp := event["context"].(map[string]interface{})["timestamp"]; timestamp != "2013-12-03T17:23:34Z" {
+					t.Errorf("expected the timestamp of the merge request, got %v", timestamp)
+				}
+			},
+		},
+		{
+			name:    "merge request approved",
+			payload: `{"object_kind": "merge_request", "object_attributes": {"iid": 1, "action": "approved"}, ` + gitlabProject + `}`,
+			want:    "change.reviewed",
+			subject: "1",
+		},
+		{
+			name:    "merge request merged",
+			payload: `{"object_kind": "merge_request", "object_attributes": {"iid": 1, "action": "merge", "updated_at": "2024-05-01T12:00:00Z"}, ` + gitlabProject + `}`,
+			want:    "change.merged",
+			subject: "1",
+		},
+		{
+			name:    "pipeline pending",
+			payload: `{"object_kind": "pipeline", "object_attributes": {"id": 31, "status": "pending", "created_at": "2016-08-12 15:23:28 UTC"}, ` + gitlabProject + `}`,
+			want:    "pipelinerun.queued",
+			subject: "31",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if name := subjectField(event, "pipelineName"); name != "Gitlab-Org/gitlab-test" {
+					t.Errorf("expected the project as pipeline name, got %v", name)
+				}
+			},
+		},
+		{
+			name:    "pipeline canceled",
+			payload: `{"object_kind": "pipeline", "object_attributes": {"id": 31, "name": "Nightly", "status": "canceled", "url": "https://gitlab.example.com/Gitlab-Org/gitlab-test/-/pipelines/31", "finished_at": "2016-08-12 15:26:29 UTC"}, ` + gitlabProject + `}`,
+			want:    "pipelinerun.finished",
+			subject: "31",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if outcome := subjectField(event, "outcome"); outcome != "cancel" {
+					t.Errorf("expected the cancel outcome, got %v", outcome)
+				}
+			},
+		},
+		{
+			name:    "pipeline created",
+			payload: `{"object_kind": "pipeline", "object_attributes": {"id": 31, "status": "created"}, ` + gitlabProject + `}`,
+		},
+		{
+			name:    "job failed",
+			payload: `{"object_kind": "build", "build_id": 1977, "build_name": "test", "build_status": "failed", "build_failure_reason": "script_failure", "build_finished_at": "2021-02-23T02:41:37Z", "pipeline_id": 31, ` + gitlabProject + `}`,
+			want:    "taskrun.finished",
+			subject: "1977",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if outcome, errors := subjectField(event, "outcome"), subjectField(event, "errors"); outcome != "failure" || errors != "script_failure" {
+					t.Errorf("expected the failure outcome and reason, got %v, %v", outcome, errors)
+				}
+				if url := subjectField(event, "url"); url != "https://gitlab.example.com/Gitlab-Org/gitlab-test/-/jobs/1977" {
+					t.Errorf("unexpected job URL %v", url)
+				}
+				if id := subjectField(event, "pipelineRun", "id"); id != "31" {
+					t.Errorf("expected the pipeline as pipeline run, got %v", id)
+				}
+			},
+		},
+		{
+			name:    "job pending",
+			payload: `{"object_kind": "build", "build_id": 1977, "build_name": "test", "build_status": "pending", "pipeline_id": 31, ` + gitlabProject + `}`,
+		},
+		{
+			name:    "deployment succeeded",
+			payload: `{"object_kind": "deployment", "status": "success", "status_changed_at": "2021-04-28 21:50:00 +0200", "environment": "production", "short_sha": "279484c0",
+				"commit_url": "https://gitlab.example.com/gitlab-org/gitlab-test/-/commit/279484c09fbe69ededfced8c1bb6e6d24616b468", ` + gitlabProject + `}`,
+			want:    "service.deployed",
+			subject: "gitlab-test",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if id := subjectField(event, "environment", "id"); id != "production" {
+					t.Errorf("unexpected environment %v", id)
+				}
+				if artifact := subjectField(event, "artifactId"); artifact != "pkg:gitlab/gitlab-org/gitlab-test@279484c09fbe69ededfced8c1bb6e6d24616b468" {
+					t.Errorf("unexpected artifact %v", artifact)
+				}
+			},
+		},
+		{
+			name:    "deployment running",
+			payload: `{"object_kind": "deployment", "status": "running", "environment": "production", "short_sha": "279484c0", ` + gitlabProject + `}`,
+		},
+		{
+			name:    "release created",
+			payload: `{"object_kind": "release", "action": "create", "tag": "v1.1", "released_at": "2020-11-02 12:55:12 UTC", ` + gitlabProject + `}`,
+			want:    "artifact.published",
+			subject: "pkg:gitlab/gitlab-org/gitlab-test@v1.1",
+		},
+		{
+			name:    "issue opened",
+			payload: `{"object_kind": "issue", "object_attributes": {"iid": 23, "title": "New API: create/update/delete file", "type": "Incident", "action": "open", "url": "https://gitlab.example.com/Gitlab-Org/gitlab-test/-/issues/23"}, "labels": [{"title": "API"}], "assignees": [{"username": "user1"}], ` + gitlabProject + `}`,
+			want:    "ticket.created",
+			subject: "23",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if creator, ticketType := subjectField(event, "creator"), subjectField(event, "ticketType"); creator != "root" || ticketType != "incident" {
+					t.Errorf("unexpected creator %v and type %v", creator, ticketType)
+				}
+			},
+		},
+		{
+			name:    "issue closed",
+			payload: `{"object_kind": "issue", "object_attributes": {"iid": 23, "title": "New API", "action": "close", "url": "https://gitlab.example.com/Gitlab-Org/gitlab-test/-/issues/23"}, ` + gitlabProject + `}`,
+			want:    "ticket.closed",
+			subject: "23",
+			check: func(t *testing.T, event map[string]interface{}) {
+				if resolution := subjectField(event, "resolution"); resolution != "completed" {
+					t.Errorf("unexpected resolution %v", resolution)
+				}
+			},
+		},
+		{
+			name:    "note",
+			payload: `{"object_kind": "note", "object_attributes": {"id": 1244, "note": "This MR needs work."}, ` + gitlabProject + `}`,
+		},
+	}
+
+	validator, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to load schemas: %v", err)
+	}
+	factory := events.NewEventFactory("test-source")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translated, err := webhook.TranslateGitLab(factory, []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("failed to translate: %v", err)
+			}
+			if tt.want == "" {
+				if len(translated) != 0 {
+					t.Fatalf("expected no events, got %s", translated[0].GetType())
+				}
+				return
+			}
+			if len(translated) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(translated))
+			}
+			event := translated[0]
+			if eventType := event.GetType().String(); !strings.HasPrefix(eventType, "dev.cdevents."+tt.want+".") {
+				t.Errorf("expected a %s event, got %s", tt.want, eventType)
+			}
+			if event.GetSubjectId() != tt.subject {
+				t.Errorf("expected subject %s, got %s", tt.subject, event.GetSubjectId())
+			}
+			if event.GetSource() != "https://gitlab.example.com/Gitlab-Org/gitlab-test" {
+				t.Errorf("expected the project as source, got %s", event.GetSource())
+			}
+			if err := validator.ValidateEvent(event); err != nil {
+				t.Errorf("translated event is invalid: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, eventMap(t, event))
+			}
+		})
+	}
+}
+
+func TestTranslateGitLabErrors(t *testing.T) {
+	factory := events.NewEventFactory("test-source")
+	for name, payload := range map[string]string{
+		"not json":             "not json",
+		"no object kind":       `{"ref": "refs/heads/main"}`,
+		"no object attributes": `{"object_kind": "merge_request"}`,
+		"invalid time":         `{"object_kind": "release", "action": "create", "released_at": "yesterday"}`,
+	} {
+		if _, err := webhook.TranslateGitLab(factory, []byte(payload)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestVerifyGitLabToken(t *testing.T) {
+	if err := webhook.VerifyGitLabToken("secret", "secret"); err != nil {
+		t.Errorf("expected a valid token, got %v", err)
+	}
+	for _, token := range []string{"", "other"} {
+		if err := webhook.VerifyGitLabToken("secret", token); !errors.Is(err, webhook.ErrUnauthorized) {
+			t.Errorf("token %q: expected ErrUnauthorized, got %v", token, err)
+		}
+	}
+	if err := webhook.VerifyGitLabToken("", ""); !errors.Is(err, webhook.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized without secret token, got %v", err)
+	}
+}
+
+func TestGitLabHandler(t *testing.T) {
+	var received []api.CDEvent
+	receiver := transport.NewReceiver(func(_ context.Context, event api.CDEvent) error {
+		received = append(received, event)
+		return nil
+	})
+	server := httptest.NewServer(webhook.NewGitLabHandler(events.NewEventFactory("test-source"), "secret", receiver))
+	defer server.Close()
+
+	post := func(token string) int {
+		payload := `{"object_kind": "pipeline", "object_attributes": {"id": 31, "status": "running"}, ` + gitlabProject + `}`
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Gitlab-Event", "Pipeline Hook")
+		if token != "" {
+			req.Header.Set("X-Gitlab-Token", token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to post: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := post("secret"); status != http.StatusAccepted {
+		t.Errorf("expected 202 for the secret token, got %d", status)
+	}
+	if status := post("other"); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 for another token, got %d", status)
+	}
+	if status := post(""); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", status)
+	}
+	if len(received) != 1 || !strings.HasPrefix(received[0].GetType().String(), "dev.cdevents.pipelinerun.started.") {
+		t.Errorf("expected the pipeline started event to be received, got %v", received)
+	}
+}
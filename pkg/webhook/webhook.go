@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brunseba/cdevents-tools/pkg/events"
+	"github.com/brunseba/cdevents-tools/pkg/transport"
+	"github.com/cdevents/sdk-go/pkg/api"
+)
+
+// maxPayloadBytes bounds webhook payloads, GitHub caps them at 25 MB
+const maxPayloadBytes = 25 << 20
+
+// ErrUnauthorized is returned for webhook requests whose signature or token does not match the secret
+var ErrUnauthorized = errors.New("webhook request not authorized")
+
+// Verifier checks that a webhook request was sent by the expected producer
+type Verifier func(header http.Header, payload []byte) error
+
+// Translator translates a webhook request into CDEvents, requests without CDEvents counterpart translate into none
+type Translator func(header http.Header, payload []byte) ([]api.CDEvent, error)
+
+// Handler is an HTTP handler verifying webhook requests and passing the CDEvents translated from them to a receiver
+type Handler struct {
+	verify    Verifier
+	translate Translator
+	receiver  *transport.Receiver
+}
+
+// NewHandler creates a webhook handler, verify may be nil to accept unverified requests
+func NewHandler(verify Verifier, translate Translator, receiver *transport.Receiver) *Handler {
+	return &Handler{verify: verify, translate: translate, receiver: receiver}
+}
+
+// ServeHTTP translates a webhook request. It responds 202 Accepted when all translated events are accepted,
+// 401 Unauthorized when the request can't be verified, 400 Bad Request for payloads that can't be translated
+// and rejected events, or 502 Bad Gateway when events were not delivered.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are accepted", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(req.Body, maxPayloadBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if h.verify != nil {
+		if err := h.verify(req.Header, payload); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	cdEvents, err := h.translate(req.Header, payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rejected []string
+	status := http.StatusBadRequest
+	for _, event := range cdEvents {
+		if err := h.receiver.Handle(req.Context(), event); err != nil {
+			rejected = append(rejected, fmt.Sprintf("%s: %v", event.GetType(), err))
+			if errors.Is(err, transport.ErrNotDelivered) {
+				status = http.StatusBadGateway
+			}
+		}
+	}
+	if len(rejected) > 0 {
+		http.Error(w, fmt.Sprintf("rejected %d of %d events:\n%s", len(rejected), len(cdEvents), strings.Join(rejected, "\n")), status)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "translated %d events\n", len(cdEvents))
+}
+
+// eventOptions returns the options of events sourced from source, such as a repository URL, that happened at
+// timestamp unless it is zero
+func eventOptions(source string, timestamp time.Time) []events.EventOption {
+	opts := []events.EventOption{events.WithSource(source)}
+	if !timestamp.IsZero() {
+		opts = append(opts, events.WithTimestamp(timestamp))
+	}
+	return opts
+}